@@ -5,45 +5,116 @@ package config
 
 import (
 	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rs/zerolog/log"
 	"regexp"
 	"strings"
+	"sync/atomic"
+	"text/template"
 )
 
 var (
-	Config Specification
+	current    atomic.Pointer[Specification]
+	rootRegion string
 )
 
-func ParseConfiguration(rootRegion string) {
-	err := envconfig.Process("steadybit_extension", &Config)
-	if err != nil {
-		log.Fatal().Err(err).Msgf("Failed to parse configuration from environment.")
-	}
+func init() {
+	current.Store(&Specification{})
+}
+
+// Config returns the current configuration. A reload publishes a new specification instead of modifying the current
+// one, so the result is consistent, but must not be modified. Read it once if several values need to match.
+func Config() *Specification {
+	return current.Load()
+}
 
-	Config.AssumeRoles = trimSpaces(Config.AssumeRoles)
-	Config.Regions = trimSpaces(Config.Regions)
-	Config.EnrichEc2DataForTargetTypes = trimSpaces(Config.EnrichEc2DataForTargetTypes)
+// Set publishes the specification as the current configuration.
+func Set(spec Specification) {
+	current.Store(&spec)
+}
+
+// Update publishes a copy of the current configuration modified by the given function.
+func Update(modify func(spec *Specification)) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+	spec := *current.Load()
+	modify(&spec)
+	current.Store(&spec)
+}
 
-	if len(Config.Regions) == 0 {
-		Config.Regions = []string{rootRegion}
+func ParseConfiguration(region string) {
+	rootRegion = region
+	spec, err := loadSpecification()
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Configuration issue, shutting down.")
 	}
+	Set(spec)
 
-	if Config.DisableDiscoveryExcludes {
+	if spec.ConfigFile != "" {
+		log.Info().Msgf("Using configuration file '%s'.", spec.ConfigFile)
+	}
+	if spec.DisableDiscoveryExcludes {
 		log.Info().Msg("Discovery excludes are disabled. Will also discover containers labeled with steadybit.com/discovery-disabled.")
 	}
-	if Config.AssumeRoles != nil && Config.AssumeRolesAdvanced != nil {
-		log.Fatal().Msg("You can only specify either `assumeRoles` or `assumeRolesAdvanced`.")
+	if spec.AssumeRolesAdvanced != nil {
+		log.Info().Msgf("Using assume role configuration: %v", spec.AssumeRolesAdvanced)
+	}
+	if spec.OrganizationsEnabled {
+		log.Info().Msgf("Assuming role '%s' in all accounts of the AWS organization.", spec.OrganizationsRoleNameTemplate)
 	}
-	translateToAssumeRolesAdvanced()
-	err = verifyAssumeRolesAdvanced()
+}
+
+// loadSpecification reads the environment and the optional configuration file and verifies the result. It does not
+// modify the current configuration, so that a broken configuration file can be rejected during a reload.
+func loadSpecification() (Specification, error) {
+	var spec Specification
+	err := envconfig.Process("steadybit_extension", &spec)
 	if err != nil {
-		log.Fatal().Err(err).Msgf("Configuration issue, shutting down.")
+		return spec, fmt.Errorf("failed to parse configuration from environment: %w", err)
+	}
+	if spec.ConfigFile != "" {
+		err = applyConfigFile(spec.ConfigFile, &spec)
+		if err != nil {
+			return spec, err
+		}
+	}
+
+	spec.AssumeRoles = trimSpaces(spec.AssumeRoles)
+	spec.Regions = trimSpaces(spec.Regions)
+	spec.EnrichEc2DataForTargetTypes = trimSpaces(spec.EnrichEc2DataForTargetTypes)
+	spec.OrganizationsOrganizationalUnits = trimSpaces(spec.OrganizationsOrganizationalUnits)
+	spec.OrganizationsExcludedAccounts = trimSpaces(spec.OrganizationsExcludedAccounts)
+
+	if len(spec.Regions) == 0 {
+		spec.Regions = []string{rootRegion}
 	}
 
-	if Config.AssumeRolesAdvanced != nil {
-		log.Info().Msgf("Using assume role configuration: %v", Config.AssumeRolesAdvanced)
+	if spec.AssumeRoles != nil && spec.AssumeRolesAdvanced != nil {
+		return spec, fmt.Errorf("you can only specify either `assumeRoles` or `assumeRolesAdvanced`")
+	}
+	translateToAssumeRolesAdvanced(&spec)
+	err = verifyAssumeRolesAdvanced(&spec)
+	if err != nil {
+		return spec, err
+	}
+	err = verifyOrganizations(&spec)
+	if err != nil {
+		return spec, err
 	}
+	err = verifyAllTagFilters(&spec)
+	if err != nil {
+		return spec, err
+	}
+	err = verifyAttackJournal(&spec)
+	if err != nil {
+		return spec, err
+	}
+	err = verifyProtection(&spec)
+	if err != nil {
+		return spec, err
+	}
+	return spec, nil
 }
 
 func trimSpaces(orig []string) []string {
@@ -54,14 +125,17 @@ func trimSpaces(orig []string) []string {
 	return trimmed
 }
 
-func verifyAssumeRolesAdvanced() error {
-	if Config.AssumeRolesAdvanced != nil {
+func verifyAssumeRolesAdvanced(spec *Specification) error {
+	if spec.AssumeRolesAdvanced != nil {
 		existingAccount := make(map[string]bool)
 		existingRoles := make(map[string]bool)
-		for _, role := range Config.AssumeRolesAdvanced {
+		for _, role := range spec.AssumeRolesAdvanced {
 			if role.RoleArn == "" {
 				return fmt.Errorf("roleArn must not be empty")
 			}
+			if err := verifyAssumeRoleSession(role); err != nil {
+				return err
+			}
 			account := getAccountNumberFromArn(role.RoleArn)
 			for _, region := range role.Regions {
 				_, roleAndRegionAlreadyConfigured := existingRoles[role.RoleArn+"/"+region]
@@ -85,31 +159,137 @@ func verifyAssumeRolesAdvanced() error {
 	return nil
 }
 
-func getAccountNumberFromArn(arn string) string {
-	re := regexp.MustCompile(`^arn:aws(-us-gov)?:iam::(\d{12}):role/[\w+=,.@-]+$`)
-	matches := re.FindStringSubmatch(arn)
-	if len(matches) < 3 {
+var accountIdPattern = regexp.MustCompile(`^\d{12}$`)
+
+var sessionNamePattern = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+func verifyAssumeRoleSession(role AssumeRole) error {
+	if role.SessionName != "" && !sessionNamePattern.MatchString(role.SessionName) {
+		return fmt.Errorf("sessionName '%s' of role '%s' must consist of 2 to 64 letters, digits or the characters +=,.@_-", role.SessionName, role.RoleArn)
+	}
+	if role.DurationSeconds != 0 && (role.DurationSeconds < 900 || role.DurationSeconds > 43200) {
+		return fmt.Errorf("durationSeconds of role '%s' must be between 900 and 43200", role.RoleArn)
+	}
+	if len(role.ChainedRoles) > 0 && role.DurationSeconds > 3600 {
+		return fmt.Errorf("durationSeconds of role '%s' must not exceed 3600, as AWS limits chained role sessions to one hour", role.RoleArn)
+	}
+	if len(role.SessionTags) > 50 {
+		return fmt.Errorf("role '%s' has more than 50 session tags", role.RoleArn)
+	}
+	for key := range role.SessionTags {
+		if key == "" {
+			return fmt.Errorf("session tags of role '%s' must not have an empty key", role.RoleArn)
+		}
+	}
+	for _, chained := range role.ChainedRoles {
+		if chained.RoleArn == "" {
+			return fmt.Errorf("roleArn of the chained roles of role '%s' must not be empty", role.RoleArn)
+		}
+	}
+	return nil
+}
+
+func verifyOrganizations(spec *Specification) error {
+	if !spec.OrganizationsEnabled {
+		return nil
+	}
+	if strings.TrimSpace(spec.OrganizationsRoleNameTemplate) == "" {
+		return fmt.Errorf("organizationsRoleNameTemplate must not be empty when organizations are enabled")
+	}
+	if _, err := ParseRoleNameTemplate(spec.OrganizationsRoleNameTemplate); err != nil {
+		return fmt.Errorf("invalid organizationsRoleNameTemplate: %w", err)
+	}
+	if spec.OrganizationsRefreshInterval <= 0 {
+		return fmt.Errorf("organizationsRefreshInterval must be greater than 0")
+	}
+	return nil
+}
+
+func verifyAttackJournal(spec *Specification) error {
+	switch spec.AttackJournalBackend {
+	case "", "none":
+	case "file":
+		if spec.AttackJournalFileDirectory == "" {
+			return fmt.Errorf("attackJournalFileDirectory must be set to a persistent directory when the attack journal backend is 'file'")
+		}
+	case "s3":
+		if spec.AttackJournalS3Bucket == "" {
+			return fmt.Errorf("attackJournalS3Bucket must be set when the attack journal backend is 's3'")
+		}
+	case "dynamodb":
+		if spec.AttackJournalDynamodbTable == "" {
+			return fmt.Errorf("attackJournalDynamodbTable must be set when the attack journal backend is 'dynamodb'")
+		}
+	default:
+		return fmt.Errorf("unknown attack journal backend '%s', use one of file, s3, dynamodb or none", spec.AttackJournalBackend)
+	}
+	return nil
+}
+
+func verifyProtection(spec *Specification) error {
+	for _, tag := range spec.ProtectedTags {
+		if key, _, _ := strings.Cut(tag, "="); strings.TrimSpace(key) == "" {
+			return fmt.Errorf("protected tag '%s' has no key", tag)
+		}
+	}
+	for _, pattern := range spec.ProtectedArnPatterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("protected ARN patterns must not be empty")
+		}
+	}
+	return nil
+}
+
+func verifyAllTagFilters(spec *Specification) error {
+	if err := verifyTagFilters(spec.TagFilters); err != nil {
+		return err
+	}
+	if err := verifyTagFilters(spec.OrganizationsAccountTagFilters); err != nil {
+		return err
+	}
+	for _, role := range spec.AssumeRolesAdvanced {
+		if err := verifyTagFilters(role.TagFilters); err != nil {
+			return fmt.Errorf("invalid tag filters for role '%s': %w", role.RoleArn, err)
+		}
+	}
+	return nil
+}
+
+// RoleNameTemplateData is passed to the `OrganizationsRoleNameTemplate` to render the role name for an account.
+type RoleNameTemplateData struct {
+	AccountId   string
+	AccountName string
+}
+
+func ParseRoleNameTemplate(roleNameTemplate string) (*template.Template, error) {
+	return template.New("roleName").Option("missingkey=error").Parse(roleNameTemplate)
+}
+
+// getAccountNumberFromArn returns the account of a role ARN of any partition.
+func getAccountNumberFromArn(roleArn string) string {
+	parsed, err := arn.Parse(roleArn)
+	if err != nil || parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") || !accountIdPattern.MatchString(parsed.AccountID) {
 		return "unknown"
 	}
-	return matches[2]
+	return parsed.AccountID
 }
 
-func translateToAssumeRolesAdvanced() {
+func translateToAssumeRolesAdvanced(spec *Specification) {
 	// if advanced assume roles are used, apply simple tag filters and regions to all roles that do not specify an own filter
-	if Config.AssumeRolesAdvanced != nil {
-		for i, assumeRole := range Config.AssumeRolesAdvanced {
+	if spec.AssumeRolesAdvanced != nil {
+		for i, assumeRole := range spec.AssumeRolesAdvanced {
 			if assumeRole.TagFilters == nil {
-				Config.AssumeRolesAdvanced[i].TagFilters = Config.TagFilters
+				spec.AssumeRolesAdvanced[i].TagFilters = spec.TagFilters
 			}
 			if assumeRole.Regions == nil {
-				Config.AssumeRolesAdvanced[i].Regions = Config.Regions
+				spec.AssumeRolesAdvanced[i].Regions = spec.Regions
 			}
 		}
 	}
 	// create advanced assume roles from simple assume roles
-	if Config.AssumeRoles != nil {
-		for _, assumeRole := range Config.AssumeRoles {
-			Config.AssumeRolesAdvanced = append(Config.AssumeRolesAdvanced, AssumeRole{assumeRole, Config.Regions, Config.TagFilters})
+	if spec.AssumeRoles != nil {
+		for _, assumeRole := range spec.AssumeRoles {
+			spec.AssumeRolesAdvanced = append(spec.AssumeRolesAdvanced, AssumeRole{RoleArn: assumeRole, Regions: spec.Regions, TagFilters: spec.TagFilters})
 		}
 	}
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"reflect"
 	"testing"
 )
@@ -82,51 +83,81 @@ func TestTranslateToAssumeRolesAdvanced(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set the global Config variable
-			Config = tt.initialConfig
+			spec := tt.initialConfig
 
 			// Call the function under test
-			translateToAssumeRolesAdvanced()
+			translateToAssumeRolesAdvanced(&spec)
 
 			// Verify the results
-			if !reflect.DeepEqual(Config, tt.expectedConfig) {
+			if !reflect.DeepEqual(spec, tt.expectedConfig) {
 				t.Errorf("translateToAssumeRolesAdvanced() failed for %s.\nExpected: %+v\nGot: %+v",
-					tt.name, tt.expectedConfig, Config)
+					tt.name, tt.expectedConfig, spec)
 			}
 		})
 	}
 }
 
 func TestVerifyAssumeRolesAdvanced(t *testing.T) {
+	var spec Specification
 	t.Run("valid configuration", func(t *testing.T) {
-		Config.AssumeRolesAdvanced = []AssumeRole{
+		spec.AssumeRolesAdvanced = []AssumeRole{
 			{RoleArn: "arn:aws:iam::123456789012:role/TestRole1", Regions: []string{"us-east-1"}, TagFilters: TagFilters{{Key: "team", Values: []string{"foo"}}}},
 			{RoleArn: "arn:aws:iam::123456789012:role/TestRole2", Regions: []string{"us-east-1"}, TagFilters: TagFilters{{Key: "team", Values: []string{"bar"}}}},
 			{RoleArn: "arn:aws:iam::234567890123:role/TestRole2", Regions: []string{"us-west-2"}},
 		}
-		err := verifyAssumeRolesAdvanced()
+		err := verifyAssumeRolesAdvanced(&spec)
 		assert.Nil(t, err)
 	})
 
 	t.Run("duplicate account without tag filters (should fail)", func(t *testing.T) {
-		Config.AssumeRolesAdvanced = []AssumeRole{
+		spec.AssumeRolesAdvanced = []AssumeRole{
 			{RoleArn: "arn:aws:iam::123456789012:role/TestRole1", Regions: []string{"us-east-1"}},
 			{RoleArn: "arn:aws:iam::123456789012:role/TestRole1", Regions: []string{"eu-central-1"}},
 			{RoleArn: "arn:aws:iam::123456789012:role/TestRole2", Regions: []string{"us-east-1"}},
 		}
-		err := verifyAssumeRolesAdvanced()
+		err := verifyAssumeRolesAdvanced(&spec)
 		assert.EqualError(t, err, "you have configured multiple role-arn for the same account '123456789012'. you need to set up tag filters to separate the discovered targets by each role")
 	})
 
 	t.Run("duplicate role in same region (should fail)", func(t *testing.T) {
-		Config.AssumeRolesAdvanced = []AssumeRole{
+		spec.AssumeRolesAdvanced = []AssumeRole{
 			{RoleArn: "arn:aws:iam::123456789012:role/TestRole1", Regions: []string{"us-east-1"}},
 			{RoleArn: "arn:aws:iam::123456789999:role/TestRole1", Regions: []string{"us-east-1"}},
 			{RoleArn: "arn:aws:iam::123456789012:role/TestRole1", Regions: []string{"us-east-1"}},
 		}
-		err := verifyAssumeRolesAdvanced()
+		err := verifyAssumeRolesAdvanced(&spec)
 		assert.EqualError(t, err, "you have configured the same role-arn for the same region twice. (arn: 'arn:aws:iam::123456789012:role/TestRole1', region: 'us-east-1')")
 	})
+
+	t.Run("session options", func(t *testing.T) {
+		role := AssumeRole{
+			RoleArn:         "arn:aws:iam::123456789012:role/TestRole1",
+			Regions:         []string{"us-east-1"},
+			ExternalId:      "4711",
+			SessionName:     "steadybit@prod",
+			DurationSeconds: 3600,
+			SessionTags:     map[string]string{"team": "chaos"},
+			ChainedRoles:    []ChainedRole{{RoleArn: "arn:aws:iam::111111111111:role/Hub", ExternalId: "0815"}},
+		}
+		spec.AssumeRolesAdvanced = []AssumeRole{role}
+		assert.NoError(t, verifyAssumeRolesAdvanced(&spec))
+
+		invalid := role
+		invalid.SessionName = "steadybit extension"
+		spec.AssumeRolesAdvanced = []AssumeRole{invalid}
+		assert.ErrorContains(t, verifyAssumeRolesAdvanced(&spec), "sessionName 'steadybit extension'")
+
+		invalid = role
+		invalid.DurationSeconds = 7200
+		spec.AssumeRolesAdvanced = []AssumeRole{invalid}
+		assert.ErrorContains(t, verifyAssumeRolesAdvanced(&spec), "chained role sessions to one hour")
+
+		invalid = role
+		invalid.DurationSeconds = 60
+		invalid.ChainedRoles = nil
+		spec.AssumeRolesAdvanced = []AssumeRole{invalid}
+		assert.ErrorContains(t, verifyAssumeRolesAdvanced(&spec), "between 900 and 43200")
+	})
 }
 
 func Test_getAccountNumberFromArn(t *testing.T) {
@@ -148,6 +179,21 @@ func Test_getAccountNumberFromArn(t *testing.T) {
 			args: args{arn: "arn:aws-us-gov:iam::123456789012:role/TestRole"},
 			want: "123456789012",
 		},
+		{
+			name: "China ARN",
+			args: args{arn: "arn:aws-cn:iam::123456789012:role/TestRole"},
+			want: "123456789012",
+		},
+		{
+			name: "ISO ARN with path",
+			args: args{arn: "arn:aws-iso-b:iam::123456789012:role/path/TestRole"},
+			want: "123456789012",
+		},
+		{
+			name: "User ARN",
+			args: args{arn: "arn:aws:iam::123456789012:user/TestUser"},
+			want: "unknown",
+		},
 		{
 			name: "Invalid ARN",
 			args: args{arn: "arn:this-is-not-a-valid-arn"},
@@ -160,3 +206,48 @@ func Test_getAccountNumberFromArn(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyTagFilters(t *testing.T) {
+	var filters TagFilters
+	err := filters.UnmarshalText([]byte(`[{"key":"env","values":["prod"]},{"anyOf":[{"key":"team","operator":"glob","values":["shop-*"]},{"key":"owner","operator":"absent"}]}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, TagFilters{
+		{Key: "env", Values: []string{"prod"}},
+		{AnyOf: []TagFilter{
+			{Key: "team", Operator: TagFilterOperatorGlob, Values: []string{"shop-*"}},
+			{Key: "owner", Operator: TagFilterOperatorAbsent},
+		}},
+	}, filters)
+	assert.NoError(t, verifyTagFilters(filters))
+
+	assert.EqualError(t, verifyTagFilters([]TagFilter{{Key: "env", Operator: "like", Values: []string{"prod"}}}), "unknown tag filter operator 'like' for key 'env'")
+	assert.ErrorContains(t, verifyTagFilters([]TagFilter{{Key: "env", Operator: TagFilterOperatorRegex, Values: []string{"("}}}), "invalid regular expression in tag filter for key 'env'")
+	assert.EqualError(t, verifyTagFilters([]TagFilter{{Operator: TagFilterOperatorExists}}), "tag filter key must not be empty")
+	assert.EqualError(t, verifyTagFilters([]TagFilter{{Key: "env", AnyOf: []TagFilter{{Key: "team", Operator: TagFilterOperatorExists}}}}), "tag filter groups (anyOf/allOf) must not specify key, operator or values")
+	assert.EqualError(t, verifyTagFilters([]TagFilter{{AllOf: []TagFilter{{Key: "team", Operator: "contains"}}}}), "unknown tag filter operator 'contains' for key 'team'")
+}
+
+func TestVerifyAttackJournal(t *testing.T) {
+	assert.NoError(t, verifyAttackJournal(&Specification{AttackJournalBackend: "none"}))
+	assert.NoError(t, verifyAttackJournal(&Specification{AttackJournalBackend: "file", AttackJournalFileDirectory: "/data/journal"}))
+	assert.EqualError(t, verifyAttackJournal(&Specification{AttackJournalBackend: "file"}), "attackJournalFileDirectory must be set to a persistent directory when the attack journal backend is 'file'")
+	assert.EqualError(t, verifyAttackJournal(&Specification{AttackJournalBackend: "s3"}), "attackJournalS3Bucket must be set when the attack journal backend is 's3'")
+	assert.EqualError(t, verifyAttackJournal(&Specification{AttackJournalBackend: "sqlite"}), "unknown attack journal backend 'sqlite', use one of file, s3, dynamodb or none")
+}
+
+func TestParseEndpointOverridesFromEnvironment(t *testing.T) {
+	rootRegion = "eu-central-1"
+	t.Setenv("STEADYBIT_EXTENSION_AWS_ENDPOINT_OVERRIDES", "EC2:http://ec2-mock:8080, SSM=http://ssm-mock:8080,Elastic Load Balancing v2:https://elb-mock")
+
+	spec, err := loadSpecification()
+	require.NoError(t, err)
+	assert.Equal(t, Endpoints{
+		"EC2":                       "http://ec2-mock:8080",
+		"SSM":                       "http://ssm-mock:8080",
+		"Elastic Load Balancing v2": "https://elb-mock",
+	}, spec.AwsEndpointOverrides)
+
+	t.Setenv("STEADYBIT_EXTENSION_AWS_ENDPOINT_OVERRIDES", "EC2")
+	_, err = loadSpecification()
+	assert.ErrorContains(t, err, "invalid endpoint override 'EC2'")
+}
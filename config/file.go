@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"sigs.k8s.io/yaml"
+)
+
+// ReloadListener is invoked after the configuration has been reloaded successfully.
+type ReloadListener func(previous Specification, current Specification)
+
+var (
+	reloadMutex     sync.Mutex
+	reloadListeners []ReloadListener
+)
+
+// applyConfigFile overrides the given specification with the values of a YAML or JSON file. The keys are the json names
+// of the Specification fields, keys that are not present in the file keep their value from the environment.
+func applyConfigFile(path string, spec *Specification) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file '%s': %w", path, err)
+	}
+	err = yaml.UnmarshalStrict(content, spec)
+	if err != nil {
+		return fmt.Errorf("failed to parse configuration file '%s': %w", path, err)
+	}
+	return nil
+}
+
+func AddReloadListener(listener ReloadListener) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+	reloadListeners = append(reloadListeners, listener)
+}
+
+// StartConfigurationReload reloads the configuration on SIGHUP and whenever the content of the configuration file
+// changes. Nothing happens if no configuration file is used.
+func StartConfigurationReload(ctx context.Context) {
+	configFile := Config().ConfigFile
+	if configFile == "" {
+		return
+	}
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	ticker := time.NewTicker(time.Duration(max(Config().ConfigFileWatchInterval, 1)) * time.Second)
+	lastChecksum := fileChecksum(configFile)
+	go func() {
+		defer signal.Stop(hangup)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-hangup:
+				log.Info().Msg("Received SIGHUP, reloading configuration.")
+				lastChecksum = fileChecksum(configFile)
+				ReloadConfiguration()
+			case <-ticker.C:
+				checksum := fileChecksum(configFile)
+				if checksum != lastChecksum {
+					lastChecksum = checksum
+					log.Info().Msgf("Configuration file '%s' changed, reloading configuration.", configFile)
+					ReloadConfiguration()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// ReloadConfiguration re-reads the environment and the configuration file. If the new configuration is invalid, the
+// current configuration is kept.
+func ReloadConfiguration() bool {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+	spec, err := loadSpecification()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to reload configuration. Keeping the current configuration.")
+		return false
+	}
+	previous := *current.Load()
+	Set(spec)
+	log.Info().Msg("Configuration reloaded.")
+	for _, listener := range reloadListeners {
+		listener(previous, spec)
+	}
+	return true
+}
+
+func fileChecksum(path string) [sha256.Size]byte {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}
+	}
+	return sha256.Sum256(content)
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadSpecificationFromYamlFile(t *testing.T) {
+	rootRegion = "eu-central-1"
+	t.Setenv("STEADYBIT_EXTENSION_REGIONS", "us-east-1")
+	t.Setenv("STEADYBIT_EXTENSION_DISCOVERY_INTERVAL_SQS", "45")
+	t.Setenv("STEADYBIT_EXTENSION_CONFIG_FILE", writeConfigFile(t, "config.yaml", `
+regions:
+  - eu-west-1
+  - eu-west-2
+assumeRolesAdvanced:
+  - roleArn: arn:aws:iam::123456789012:role/steadybit
+    tagFilters:
+      - key: team
+        values: [shop]
+discoveryIntervalEc2: 90
+discoveryAttributesExcludesEc2:
+  - aws-ec2.label.*
+awsEndpointOverrides:
+  EC2: http://ec2-mock:8080
+`))
+
+	spec, err := loadSpecification()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"eu-west-1", "eu-west-2"}, spec.Regions, "file takes precedence over the environment")
+	assert.Equal(t, 90, spec.DiscoveryIntervalEc2)
+	assert.Equal(t, 45, spec.DiscoveryIntervalSqs, "values not present in the file are taken from the environment")
+	assert.Equal(t, 30, spec.DiscoveryIntervalRds, "defaults are still applied")
+	assert.Equal(t, []string{"aws-ec2.label.*"}, spec.DiscoveryAttributesExcludesEc2)
+	assert.Equal(t, Endpoints{"EC2": "http://ec2-mock:8080"}, spec.AwsEndpointOverrides)
+	assert.Equal(t, AssumeRoles{{
+		RoleArn:    "arn:aws:iam::123456789012:role/steadybit",
+		Regions:    []string{"eu-west-1", "eu-west-2"},
+		TagFilters: []TagFilter{{Key: "team", Values: []string{"shop"}}},
+	}}, spec.AssumeRolesAdvanced)
+}
+
+func TestLoadSpecificationFromJsonFile(t *testing.T) {
+	rootRegion = "eu-central-1"
+	t.Setenv("STEADYBIT_EXTENSION_CONFIG_FILE", writeConfigFile(t, "config.json", `{"tagFilters":[{"key":"application","values":["demo"]}],"workerThreads":4}`))
+
+	spec, err := loadSpecification()
+	require.NoError(t, err)
+
+	assert.Equal(t, TagFilters{{Key: "application", Values: []string{"demo"}}}, spec.TagFilters)
+	assert.Equal(t, 4, spec.WorkerThreads)
+	assert.Equal(t, []string{"eu-central-1"}, spec.Regions)
+}
+
+func TestLoadSpecificationRejectsInvalidFiles(t *testing.T) {
+	rootRegion = "eu-central-1"
+
+	t.Setenv("STEADYBIT_EXTENSION_CONFIG_FILE", writeConfigFile(t, "unknown.yaml", "regionz: [eu-west-1]"))
+	_, err := loadSpecification()
+	assert.ErrorContains(t, err, "regionz")
+
+	t.Setenv("STEADYBIT_EXTENSION_CONFIG_FILE", writeConfigFile(t, "duplicate.yaml", `
+assumeRolesAdvanced:
+  - roleArn: arn:aws:iam::123456789012:role/one
+  - roleArn: arn:aws:iam::123456789012:role/two
+`))
+	_, err = loadSpecification()
+	assert.ErrorContains(t, err, "you have configured multiple role-arn for the same account '123456789012'")
+
+	t.Setenv("STEADYBIT_EXTENSION_CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	_, err = loadSpecification()
+	assert.ErrorContains(t, err, "failed to read configuration file")
+}
+
+func TestReloadConfiguration(t *testing.T) {
+	rootRegion = "eu-central-1"
+	path := writeConfigFile(t, "config.yaml", "regions: [eu-west-1]")
+	t.Setenv("STEADYBIT_EXTENSION_CONFIG_FILE", path)
+	spec, err := loadSpecification()
+	require.NoError(t, err)
+	Set(spec)
+
+	var notified []Specification
+	originalListeners := reloadListeners
+	defer func() { reloadListeners = originalListeners }()
+	AddReloadListener(func(previous Specification, current Specification) {
+		notified = append(notified, previous, current)
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte("regions: [us-east-1, us-west-2]"), 0600))
+	assert.True(t, ReloadConfiguration())
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, Config().Regions)
+	require.Len(t, notified, 2)
+	assert.Equal(t, []string{"eu-west-1"}, notified[0].Regions)
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, notified[1].Regions)
+
+	require.NoError(t, os.WriteFile(path, []byte("regions: eu-west-1"), 0600))
+	assert.False(t, ReloadConfiguration())
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, Config().Regions, "invalid configuration is not applied")
+	assert.Len(t, notified, 2)
+}
@@ -5,6 +5,9 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 )
 
 type Specification struct {
@@ -14,6 +17,46 @@ type Specification struct {
 	AssumeRolesAdvanced                          AssumeRoles `json:"assumeRolesAdvanced" split_words:"true" required:"false"` // If you need a more fine-grained approach and want to specify Regions/TagFilters per role.
 	WorkerThreads                                int         `json:"workerThreads" split_words:"true" required:"false" default:"1"`
 	AwsEndpointOverride                          string      `json:"awsEndpointOverride" split_words:"true" required:"false"`
+	AwsEndpointOverrides                         Endpoints   `json:"awsEndpointOverrides" split_words:"true" required:"false"` // Endpoint URL per service id, e.g. EC2 or SSM. Services without an entry use `AwsEndpointOverride`.
+	AssumeRoleRetryInitialInterval               int         `json:"assumeRoleRetryInitialInterval" split_words:"true" required:"false" default:"10"`
+	AssumeRoleRetryMaxInterval                   int         `json:"assumeRoleRetryMaxInterval" split_words:"true" required:"false" default:"300"`
+	AwsApiRateLimit                              float64     `json:"awsApiRateLimit" split_words:"true" required:"false" default:"0"` // Calls per second per account, region and service. 0 disables the rate limiting.
+	AwsApiRateLimitBurst                         int         `json:"awsApiRateLimitBurst" split_words:"true" required:"false" default:"40"`
+	AwsApiRateLimitMin                           float64     `json:"awsApiRateLimitMin" split_words:"true" required:"false" default:"1"` // Lower bound when backing off after throttling errors.
+	TracingEnabled                               bool        `json:"tracingEnabled" split_words:"true" required:"false" default:"false"`
+	TracingEndpoint                              string      `json:"tracingEndpoint" split_words:"true" required:"false"` // OTLP/HTTP endpoint URL. If empty, the OTEL_EXPORTER_OTLP_* environment variables are used.
+	TracingSampleRatio                           float64     `json:"tracingSampleRatio" split_words:"true" required:"false" default:"1"`
+	AttackJournalBackend                         string      `json:"attackJournalBackend" split_words:"true" required:"false" default:"none"`  // One of file, s3, dynamodb or none.
+	AttackJournalScope                           string      `json:"attackJournalScope" split_words:"true" required:"false" default:"default"` // Separates the entries of extension installations sharing an S3 bucket or DynamoDB table.
+	AttackJournalFileDirectory                   string      `json:"attackJournalFileDirectory" split_words:"true" required:"false"`
+	AttackJournalS3Bucket                        string      `json:"attackJournalS3Bucket" split_words:"true" required:"false"`
+	AttackJournalS3Prefix                        string      `json:"attackJournalS3Prefix" split_words:"true" required:"false" default:"steadybit-extension-aws/journal/"`
+	AttackJournalDynamodbTable                   string      `json:"attackJournalDynamodbTable" split_words:"true" required:"false"`
+	AttackJournalMaxRollbackAttempts             int         `json:"attackJournalMaxRollbackAttempts" split_words:"true" required:"false" default:"10"`
+	ProtectedTags                                []string    `json:"protectedTags" split_words:"true" required:"false" default:"steadybit.com/protected=true"` // Entries of the form key=value or key. Targets carrying a matching tag are rejected by every action.
+	ProtectedArnPatterns                         []string    `json:"protectedArnPatterns" split_words:"true" required:"false"`                                 // Glob patterns supporting * and ?. Targets with a matching ARN are rejected by every action.
+	DryRun                                       bool        `json:"dryRun" split_words:"true" required:"false" default:"false"`                               // If enabled, attacks only check their permissions. Can also be enabled per execution.
+	PermissionSelfCheckOnStartup                 bool        `json:"permissionSelfCheckOnStartup" split_words:"true" required:"false" default:"true"`          // Logs the IAM permissions missing for the enabled discoveries and actions on startup.
+	ReadinessRequiresReachableAccount            bool        `json:"readinessRequiresReachableAccount" split_words:"true" required:"false" default:"false"`    // If enabled, the readiness probe fails while no account is reachable.
+	AccountReachableTimeout                      int         `json:"accountReachableTimeout" split_words:"true" required:"false" default:"600"`                // Seconds after the last successful discovery during which an account counts as reachable.
+	AttackLeaseWaitTimeout                       int         `json:"attackLeaseWaitTimeout" split_words:"true" required:"false" default:"0"`                   // Seconds an attack waits for a concurrent attack on the same resource to end. 0 rejects it immediately.
+	AttackLeaseMaxAge                            int         `json:"attackLeaseMaxAge" split_words:"true" required:"false" default:"86400"`                    // Seconds after which the lease of an execution that has not been stopped expires. 0 disables the expiry.
+	DiscoveryAccountTimeout                      int         `json:"discoveryAccountTimeout" split_words:"true" required:"false" default:"120"`                // Seconds after which the discovery of a single account is abandoned. 0 disables the timeout.
+	DiscoveryStaleTargetsMaxAge                  int         `json:"discoveryStaleTargetsMaxAge" split_words:"true" required:"false" default:"3600"`           // Seconds the last good targets of a failing account are served. 0 disables serving stale targets.
+	TagCacheTtl                                  int         `json:"tagCacheTtl" split_words:"true" required:"false" default:"60"`                             // Seconds the tags fetched by the Resource Groups Tagging API are shared between discoveries. 0 disables the cache.
+	BlackholeProtectedSubnetIds                  []string    `json:"blackholeProtectedSubnetIds" split_words:"true" required:"false"`                          // Subnets never blackholed in the account of the extension or the agent, e.g. the subnets of an agent not running next to the extension.
+	BlackholeReconcileInterval                   int         `json:"blackholeReconcileInterval" split_words:"true" required:"false" default:"0"`               // Seconds between the scans for orphaned blackhole network ACLs. 0 disables the blackhole reconciler.
+	BlackholeMaxTtl                              int         `json:"blackholeMaxTtl" split_words:"true" required:"false" default:"86400"`                      // Seconds after which the blackhole reconciler rolls back a blackhole, even if it has not been stopped yet.
+	BlackholeOwnerId                             string      `json:"blackholeOwnerId" split_words:"true" required:"false"`                                     // Tagged on the blackhole network ACLs, the blackhole reconciler only rolls back network ACLs of its own owner id. Defaults to the hostname.
+	BlackholeNetworkAclRulesQuota                int         `json:"blackholeNetworkAclRulesQuota" split_words:"true" required:"false" default:"20"`           // Rules per direction of a network ACL, raise it if the quota of the accounts has been increased. 0 disables the check.
+	OrganizationsEnabled                         bool        `json:"organizationsEnabled" split_words:"true" required:"false" default:"false"`                 // If enabled, a role is assumed in every account returned by AWS Organizations.
+	OrganizationsRoleNameTemplate                string      `json:"organizationsRoleNameTemplate" split_words:"true" required:"false" default:"steadybit-extension-aws"`
+	OrganizationsOrganizationalUnits             []string    `json:"organizationsOrganizationalUnits" split_words:"true" required:"false"`
+	OrganizationsAccountTagFilters               TagFilters  `json:"organizationsAccountTagFilters" split_words:"true" required:"false"`
+	OrganizationsExcludedAccounts                []string    `json:"organizationsExcludedAccounts" split_words:"true" required:"false"`
+	OrganizationsRefreshInterval                 int         `json:"organizationsRefreshInterval" split_words:"true" required:"false" default:"600"`
+	ConfigFile                                   string      `json:"-" split_words:"true" required:"false"` // Optional YAML or JSON file, its values take precedence over the environment.
+	ConfigFileWatchInterval                      int         `json:"configFileWatchInterval" split_words:"true" required:"false" default:"10"`
 	DiscoveryDisabledApigateway                  bool        `json:"discoveryDisabledApigateway" split_words:"true" required:"false" default:"false"`
 	DiscoveryDisabledAsg                         bool        `json:"discoveryDisabledAsg" split_words:"true" required:"false" default:"false"`
 	DiscoveryDisabledEc2                         bool        `json:"discoveryDisabledEc2" split_words:"true" required:"false" default:"false"`
@@ -76,14 +119,53 @@ type Specification struct {
 	DiscoveryAttributesExcludesRds               []string    `json:"discoveryAttributesExcludesRds" split_words:"true" required:"false"`
 	DiscoveryAttributesExcludesSubnet            []string    `json:"discoveryAttributesExcludesSubnet" split_words:"true" required:"false"`
 	DiscoveryAttributesExcludesZone              []string    `json:"discoveryAttributesExcludesZone" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesApigateway        []string    `json:"discoveryAttributesIncludesApigateway" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesAsg               []string    `json:"discoveryAttributesIncludesAsg" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesElb               []string    `json:"discoveryAttributesIncludesElb" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesEc2               []string    `json:"discoveryAttributesIncludesEc2" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesDynamodb          []string    `json:"discoveryAttributesIncludesDynamodb" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesEcs               []string    `json:"discoveryAttributesIncludesEcs" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesEks               []string    `json:"discoveryAttributesIncludesEks" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesElasticache       []string    `json:"discoveryAttributesIncludesElasticache" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesFis               []string    `json:"discoveryAttributesIncludesFis" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesEbs               []string    `json:"discoveryAttributesIncludesEbs" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesEventbridge       []string    `json:"discoveryAttributesIncludesEventbridge" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesMq                []string    `json:"discoveryAttributesIncludesMq" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesNatGateway        []string    `json:"discoveryAttributesIncludesNatGateway" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesSqs               []string    `json:"discoveryAttributesIncludesSqs" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesMsk               []string    `json:"discoveryAttributesIncludesMsk" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesLambda            []string    `json:"discoveryAttributesIncludesLambda" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesRds               []string    `json:"discoveryAttributesIncludesRds" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesSubnet            []string    `json:"discoveryAttributesIncludesSubnet" split_words:"true" required:"false"`
+	DiscoveryAttributesIncludesZone              []string    `json:"discoveryAttributesIncludesZone" split_words:"true" required:"false"`
+	DiscoveryAttributesMaxLabels                 int         `json:"discoveryAttributesMaxLabels" split_words:"true" required:"false" default:"0"` // Maximum number of label attributes per target. 0 keeps all labels.
 	DisableDiscoveryExcludes                     bool        `required:"false" split_words:"true" default:"false"`
 }
 
+// DefaultProtectedTag is the default of `ProtectedTags`.
+const DefaultProtectedTag = "steadybit.com/protected=true"
+
+// HasExplicitProtectedTags reports whether the user configured protected tags other than the default.
+func (s *Specification) HasExplicitProtectedTags() bool {
+	return len(s.ProtectedTags) > 0 && !slices.Equal(s.ProtectedTags, []string{DefaultProtectedTag})
+}
+
 type AssumeRoles []AssumeRole
 type AssumeRole struct {
-	RoleArn    string      `json:"roleArn"`
-	Regions    []string    `json:"regions"`
-	TagFilters []TagFilter `json:"tagFilters"`
+	RoleArn         string            `json:"roleArn"`
+	Regions         []string          `json:"regions"`
+	TagFilters      []TagFilter       `json:"tagFilters"`
+	ExternalId      string            `json:"externalId,omitempty"`
+	SessionName     string            `json:"sessionName,omitempty"`     // Defaults to steadybit-extension-aws.
+	DurationSeconds int32             `json:"durationSeconds,omitempty"` // Defaults to the STS default of one hour.
+	SessionTags     map[string]string `json:"sessionTags,omitempty"`     // Passed when assuming RoleArn, not when assuming the chained roles.
+	ChainedRoles    []ChainedRole     `json:"chainedRoles,omitempty"`    // Assumed in order before RoleArn, starting with the credentials of the extension.
+}
+
+// ChainedRole is an intermediate role assumed on the way to the role of an AssumeRole entry.
+type ChainedRole struct {
+	RoleArn    string `json:"roleArn"`
+	ExternalId string `json:"externalId,omitempty"`
 }
 
 func (j *AssumeRoles) UnmarshalText(text []byte) error {
@@ -94,10 +176,18 @@ func (j *AssumeRoles) UnmarshalText(text []byte) error {
 	return json.Unmarshal(text, (*[]AssumeRole)(j))
 }
 
+// UnmarshalJSON is needed for configuration files, as encoding/json does not use UnmarshalText for arrays.
+func (j *AssumeRoles) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*[]AssumeRole)(j))
+}
+
 type TagFilters []TagFilter
 type TagFilter struct {
-	Key    string   `json:"key"`
-	Values []string `json:"values"`
+	Key      string            `json:"key"`
+	Values   []string          `json:"values"`
+	Operator TagFilterOperator `json:"operator,omitempty"` // Defaults to TagFilterOperatorEquals
+	AnyOf    []TagFilter       `json:"anyOf,omitempty"`    // Matches if at least one of the nested filters matches
+	AllOf    []TagFilter       `json:"allOf,omitempty"`    // Matches if all nested filters match
 }
 
 func (j *TagFilters) UnmarshalText(text []byte) error {
@@ -107,3 +197,34 @@ func (j *TagFilters) UnmarshalText(text []byte) error {
 	}
 	return json.Unmarshal(text, (*[]TagFilter)(j))
 }
+
+func (j *TagFilters) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*[]TagFilter)(j))
+}
+
+// Endpoints maps service ids to endpoint URLs. In the environment, entries are written as `EC2:http://localhost:4566,SSM:http://localhost:4567`.
+type Endpoints map[string]string
+
+// Decode parses the environment format. envconfig would split the entries on every colon and break the URLs, so each
+// entry is split on its first `:` or `=` only.
+func (e *Endpoints) Decode(value string) error {
+	endpoints := Endpoints{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		separator := strings.IndexAny(entry, ":=")
+		if separator < 0 {
+			return fmt.Errorf("invalid endpoint override '%s', expected <service>:<url>", entry)
+		}
+		serviceId := strings.TrimSpace(entry[:separator])
+		endpoint := strings.TrimSpace(entry[separator+1:])
+		if serviceId == "" || endpoint == "" {
+			return fmt.Errorf("invalid endpoint override '%s', expected <service>:<url>", entry)
+		}
+		endpoints[serviceId] = endpoint
+	}
+	*e = endpoints
+	return nil
+}
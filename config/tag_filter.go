@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type TagFilterOperator string
+
+const (
+	TagFilterOperatorEquals    TagFilterOperator = "equals"    // tag is present and its value is one of Values
+	TagFilterOperatorNotEquals TagFilterOperator = "notEquals" // tag is absent or its value is none of Values
+	TagFilterOperatorExists    TagFilterOperator = "exists"    // tag is present, Values are ignored
+	TagFilterOperatorAbsent    TagFilterOperator = "absent"    // tag is not present, Values are ignored
+	TagFilterOperatorGlob      TagFilterOperator = "glob"      // tag is present and its value matches one of the glob patterns in Values
+	TagFilterOperatorRegex     TagFilterOperator = "regex"     // tag is present and its value fully matches one of the regular expressions in Values
+)
+
+// IsGroup returns true if the filter combines nested filters instead of matching a tag itself.
+func (f TagFilter) IsGroup() bool {
+	return len(f.AnyOf) > 0 || len(f.AllOf) > 0
+}
+
+// IsExactMatch returns true for the classic filters (key plus any of the values), which can also be passed to AWS APIs.
+func (f TagFilter) IsExactMatch() bool {
+	return !f.IsGroup() && (f.Operator == "" || f.Operator == TagFilterOperatorEquals)
+}
+
+func verifyTagFilters(filters []TagFilter) error {
+	for _, filter := range filters {
+		if filter.IsGroup() {
+			if filter.Key != "" || filter.Operator != "" || len(filter.Values) > 0 {
+				return fmt.Errorf("tag filter groups (anyOf/allOf) must not specify key, operator or values")
+			}
+			if err := verifyTagFilters(filter.AnyOf); err != nil {
+				return err
+			}
+			if err := verifyTagFilters(filter.AllOf); err != nil {
+				return err
+			}
+			continue
+		}
+		if filter.Key == "" {
+			return fmt.Errorf("tag filter key must not be empty")
+		}
+		switch filter.Operator {
+		case "", TagFilterOperatorEquals, TagFilterOperatorNotEquals, TagFilterOperatorGlob, TagFilterOperatorExists, TagFilterOperatorAbsent:
+		case TagFilterOperatorRegex:
+			for _, value := range filter.Values {
+				if _, err := regexp.Compile(value); err != nil {
+					return fmt.Errorf("invalid regular expression in tag filter for key '%s': %w", filter.Key, err)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown tag filter operator '%s' for key '%s'", filter.Operator, filter.Key)
+		}
+	}
+	return nil
+}
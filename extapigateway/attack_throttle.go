@@ -104,6 +104,15 @@ func (a *apigatewayThrottleAttack) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (a *apigatewayThrottleAttack) RequiredPermissions() []string {
+	return []string{"apigateway:GET", "apigateway:PATCH"}
+}
+
+func (a *apigatewayThrottleAttack) ResourceArns(_ context.Context, target *action_kit_api.Target) ([]string, error) {
+	return []string{utils.BuildArn("apigateway", extutil.MustHaveValue(target.Attributes, "aws.region")[0], "",
+		fmt.Sprintf("/apis/%s/stages/%s", extutil.MustHaveValue(target.Attributes, "aws.apigateway.api.id")[0], extutil.MustHaveValue(target.Attributes, "aws.apigateway.name")[0]))}, nil
+}
+
 func (a *apigatewayThrottleAttack) Prepare(ctx context.Context, state *ApiGatewayThrottleAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
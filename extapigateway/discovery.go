@@ -7,10 +7,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"slices"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -20,7 +18,6 @@ import (
 	apigwv2types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
@@ -40,10 +37,13 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*apigatewayDiscovery)(nil)
 )
 
+// ApigatewayDiscoveryPermissions lists the IAM actions called by the discovery.
+var ApigatewayDiscoveryPermissions = []string{"apigateway:GET"}
+
 func NewApigatewayDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(&apigatewayDiscovery{},
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalApigateway)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalApigateway }),
 	)
 }
 
@@ -51,7 +51,7 @@ func (d *apigatewayDiscovery) Describe() discovery_kit_api.DiscoveryDescription
 	return discovery_kit_api.DiscoveryDescription{
 		Id: apigatewayTargetType,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalApigateway)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalApigateway)),
 		},
 	}
 }
@@ -133,7 +133,7 @@ func getAllStages(ctx context.Context, rest RestApiGatewayApi, httpApi HttpApiGa
 		result = append(result, httpTargets...)
 	}
 
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesApigateway), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesApigateway, config.Config().DiscoveryAttributesIncludesApigateway), nil
 }
 
 func getRestStages(ctx context.Context, client RestApiGatewayApi, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
@@ -148,7 +148,7 @@ func getRestStages(ctx context.Context, client RestApiGatewayApi, account *utils
 			if api.Id == nil {
 				continue
 			}
-			if !matchesApigwTagFilter(api.Tags, account.TagFilters) {
+			if !utils.MatchesTags(api.Tags, account.TagFilters) {
 				continue
 			}
 			stagesOut, err := client.GetStages(ctx, &apigateway.GetStagesInput{RestApiId: api.Id})
@@ -176,7 +176,7 @@ func getHttpStages(ctx context.Context, client HttpApiGatewayApi, account *utils
 			if api.ApiId == nil {
 				continue
 			}
-			if !matchesApigwTagFilter(api.Tags, account.TagFilters) {
+			if !utils.MatchesTags(api.Tags, account.TagFilters) {
 				continue
 			}
 			stagesOut, err := client.GetStages(ctx, &apigatewayv2.GetStagesInput{ApiId: api.ApiId})
@@ -196,24 +196,6 @@ func getHttpStages(ctx context.Context, client HttpApiGatewayApi, account *utils
 	return result, nil
 }
 
-func matchesApigwTagFilter(tags map[string]string, filters []config.TagFilter) bool {
-	if len(filters) == 0 {
-		return true
-	}
-	for _, filter := range filters {
-		matched := false
-		if v, ok := tags[filter.Key]; ok {
-			if slices.Contains(filter.Values, v) {
-				matched = true
-			}
-		}
-		if !matched {
-			return false
-		}
-	}
-	return true
-}
-
 func toRestStageTarget(api apigwtypes.RestApi, stage apigwtypes.Stage, account string, region string, role *string) discovery_kit_api.Target {
 	apiId := aws.ToString(api.Id)
 	apiName := aws.ToString(api.Name)
@@ -93,6 +93,10 @@ func (a *asgSuspendProcessesAttack) Describe() action_kit_api.ActionDescription
 	}
 }
 
+func (a *asgSuspendProcessesAttack) RequiredPermissions() []string {
+	return []string{"autoscaling:SuspendProcesses", "autoscaling:ResumeProcesses"}
+}
+
 func (a *asgSuspendProcessesAttack) Prepare(_ context.Context, state *AsgAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
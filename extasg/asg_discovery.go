@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -31,11 +30,14 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*asgDiscovery)(nil)
 )
 
+// AsgDiscoveryPermissions lists the IAM actions called by the discovery.
+var AsgDiscoveryPermissions = []string{"autoscaling:DescribeAutoScalingGroups"}
+
 func NewAsgDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &asgDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalAsg)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalAsg }),
 	)
 }
 
@@ -43,7 +45,7 @@ func (d *asgDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: asgTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalAsg)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalAsg)),
 		},
 	}
 }
@@ -165,33 +167,20 @@ func getAllAsgs(ctx context.Context, client autoscaling.DescribeAutoScalingGroup
 			}
 		}
 	}
-	return result, nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesAsg, config.Config().DiscoveryAttributesIncludesAsg), nil
 }
 
 func matchesTagFilter(tags []types.TagDescription, filters []config.TagFilter) bool {
 	if len(filters) == 0 {
 		return true
 	}
-	for _, filter := range filters {
-		matched := false
-		for _, tag := range tags {
-			if tag.Key != nil && *tag.Key == filter.Key {
-				for _, filterValue := range filter.Values {
-					if tag.Value != nil && *tag.Value == filterValue {
-						matched = true
-						break
-					}
-				}
-			}
-			if matched {
-				break
-			}
-		}
-		if !matched {
-			return false
+	tagMap := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if tag.Key != nil {
+			tagMap[*tag.Key] = aws.ToString(tag.Value)
 		}
 	}
-	return true
+	return utils.MatchesTags(tagMap, filters)
 }
 
 func toAsgTarget(asg types.AutoScalingGroup, awsAccountNumber string, awsRegion string, role *string) discovery_kit_api.Target {
@@ -103,6 +103,10 @@ func (a *tableThrottleAttack) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (a *tableThrottleAttack) RequiredPermissions() []string {
+	return []string{"dynamodb:DescribeTable", "dynamodb:UpdateTable"}
+}
+
 func (a *tableThrottleAttack) Prepare(ctx context.Context, state *TableThrottleAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
@@ -10,7 +10,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -18,9 +17,9 @@ import (
 	aastypes "github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
@@ -34,10 +33,20 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*tableDiscovery)(nil)
 )
 
+// TableDiscoveryPermissions lists the IAM actions called by the discovery.
+var TableDiscoveryPermissions = []string{
+	"dynamodb:ListTables",
+	"dynamodb:DescribeTable",
+	"dynamodb:DescribeContinuousBackups",
+	"dynamodb:DescribeTimeToLive",
+	"application-autoscaling:DescribeScalableTargets",
+	"tag:GetResources",
+}
+
 func NewTableDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(&tableDiscovery{},
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalDynamodb)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalDynamodb }),
 	)
 }
 
@@ -45,7 +54,7 @@ func (d *tableDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: tableTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalDynamodb)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalDynamodb)),
 		},
 	}
 }
@@ -102,7 +111,8 @@ func (d *tableDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_a
 func getTableTargets(account *utils.AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error) {
 	ddb := dynamodb.NewFromConfig(account.AwsConfig)
 	aas := applicationautoscaling.NewFromConfig(account.AwsConfig)
-	result, err := getAllTables(ctx, ddb, aas, account)
+	tagsClient := resourcegroupstaggingapi.NewFromConfig(account.AwsConfig)
+	result, err := getAllTables(ctx, ddb, aas, tagsClient, account)
 	if err != nil {
 		var re *awshttp.ResponseError
 		if errors.As(err, &re) && re.HTTPStatusCode() == 403 {
@@ -120,7 +130,11 @@ type scalableTargetKey struct {
 	dimension  aastypes.ScalableDimension
 }
 
-func getAllTables(ctx context.Context, ddb DynamodbApi, aas AppAutoScalingApi, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
+func getAllTables(ctx context.Context, ddb DynamodbApi, aas AppAutoScalingApi, tagsClient resourcegroupstaggingapi.GetResourcesAPIClient, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
+	resourceTags, err := utils.GetResourceTags(ctx, tagsClient, account, "dynamodb:table")
+	if err != nil {
+		return nil, err
+	}
 	scalableTargets, err := fetchDynamodbScalableTargets(ctx, aas)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to fetch DynamoDB application-autoscaling targets. Autoscaling attributes will be missing.")
@@ -135,20 +149,22 @@ func getAllTables(ctx context.Context, ddb DynamodbApi, aas AppAutoScalingApi, a
 			return result, err
 		}
 		for _, name := range output.TableNames {
-			described, err := ddb.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
+			tableArn := utils.BuildArn("dynamodb", account.Region, account.AccountNumber, "table/"+name)
+			tags, err := resourceTags.Get(tableArn, func() (map[string]string, error) {
+				return fetchTableTags(ctx, ddb, tableArn)
+			})
 			if err != nil {
-				log.Warn().Err(err).Msgf("Failed to describe DynamoDB table %s", name)
-				continue
+				return result, err
 			}
-			if described.Table == nil {
+			if !utils.MatchesTags(tags, account.TagFilters) {
 				continue
 			}
-
-			tags, err := fetchTableTags(ctx, ddb, described.Table.TableArn)
+			described, err := ddb.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
 			if err != nil {
-				log.Warn().Err(err).Msgf("Failed to fetch tags for DynamoDB table %s", name)
+				log.Warn().Err(err).Msgf("Failed to describe DynamoDB table %s", name)
+				continue
 			}
-			if !matchesTableTagFilter(tags, account.TagFilters) {
+			if described.Table == nil {
 				continue
 			}
 
@@ -158,7 +174,7 @@ func getAllTables(ctx context.Context, ddb DynamodbApi, aas AppAutoScalingApi, a
 			result = append(result, toTableTarget(described.Table, tags, pitr, ttl, scalableTargets, account.AccountNumber, account.Region, account.AssumeRole))
 		}
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesDynamodb), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesDynamodb, config.Config().DiscoveryAttributesIncludesDynamodb), nil
 }
 
 func fetchDynamodbScalableTargets(ctx context.Context, aas AppAutoScalingApi) (map[scalableTargetKey]aastypes.ScalableTarget, error) {
@@ -181,18 +197,20 @@ func fetchDynamodbScalableTargets(ctx context.Context, aas AppAutoScalingApi) (m
 	return out, nil
 }
 
-func fetchTableTags(ctx context.Context, ddb DynamodbApi, arn *string) ([]types.Tag, error) {
-	if arn == nil {
-		return nil, nil
-	}
-	tags := make([]types.Tag, 0)
+// fetchTableTags reads the tags of the table if they could not be fetched with the tagging API.
+func fetchTableTags(ctx context.Context, ddb DynamodbApi, tableArn string) (map[string]string, error) {
+	tags := make(map[string]string)
 	var nextToken *string
 	for {
-		out, err := ddb.ListTagsOfResource(ctx, &dynamodb.ListTagsOfResourceInput{ResourceArn: arn, NextToken: nextToken})
+		out, err := ddb.ListTagsOfResource(ctx, &dynamodb.ListTagsOfResourceInput{ResourceArn: aws.String(tableArn), NextToken: nextToken})
 		if err != nil {
 			return nil, err
 		}
-		tags = append(tags, out.Tags...)
+		for _, tag := range out.Tags {
+			if tag.Key != nil {
+				tags[*tag.Key] = aws.ToString(tag.Value)
+			}
+		}
 		if out.NextToken == nil {
 			break
 		}
@@ -227,33 +245,7 @@ func fetchTTL(ctx context.Context, ddb DynamodbApi, tableName string) *bool {
 	return &enabled
 }
 
-func matchesTableTagFilter(tags []types.Tag, filters []config.TagFilter) bool {
-	if len(filters) == 0 {
-		return true
-	}
-	for _, filter := range filters {
-		matched := false
-		for _, tag := range tags {
-			if tag.Key != nil && *tag.Key == filter.Key {
-				for _, v := range filter.Values {
-					if tag.Value != nil && *tag.Value == v {
-						matched = true
-						break
-					}
-				}
-			}
-			if matched {
-				break
-			}
-		}
-		if !matched {
-			return false
-		}
-	}
-	return true
-}
-
-func toTableTarget(t *types.TableDescription, tags []types.Tag, pitr *bool, ttl *bool, scalable map[scalableTargetKey]aastypes.ScalableTarget, account string, region string, role *string) discovery_kit_api.Target {
+func toTableTarget(t *types.TableDescription, tags map[string]string, pitr *bool, ttl *bool, scalable map[scalableTargetKey]aastypes.ScalableTarget, account string, region string, role *string) discovery_kit_api.Target {
 	arn := aws.ToString(t.TableArn)
 	name := aws.ToString(t.TableName)
 
@@ -348,11 +340,8 @@ func toTableTarget(t *types.TableDescription, tags []types.Tag, pitr *bool, ttl
 	tableResourceId := fmt.Sprintf("table/%s", name)
 	addAutoscalingAttributes(attributes, "aws.dynamodb.autoscaling", tableResourceId, scalable, aastypes.ScalableDimensionDynamoDBTableReadCapacityUnits, aastypes.ScalableDimensionDynamoDBTableWriteCapacityUnits)
 
-	for _, tag := range tags {
-		if tag.Key == nil {
-			continue
-		}
-		attributes[fmt.Sprintf("aws.dynamodb.label.%s", strings.ToLower(*tag.Key))] = []string{aws.ToString(tag.Value)}
+	for k, v := range tags {
+		attributes[fmt.Sprintf("aws.dynamodb.label.%s", strings.ToLower(k))] = []string{v}
 	}
 
 	if role != nil {
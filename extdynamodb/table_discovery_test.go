@@ -13,6 +13,8 @@ import (
 	aastypes "github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	tagtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
 	extConfig "github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
 	"github.com/stretchr/testify/assert"
@@ -71,6 +73,26 @@ func (m *ddbApiMock) UpdateTable(ctx context.Context, params *dynamodb.UpdateTab
 	return args.Get(0).(*dynamodb.UpdateTableOutput), args.Error(1)
 }
 
+type tagClientMock struct {
+	mock.Mock
+}
+
+func (m *tagClientMock) GetResources(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*resourcegroupstaggingapi.GetResourcesOutput), args.Error(1)
+}
+
+func newTagClientMock(mappings ...tagtypes.ResourceTagMapping) *tagClientMock {
+	tagClient := new(tagClientMock)
+	tagClient.On("GetResources", mock.Anything, mock.MatchedBy(func(p *resourcegroupstaggingapi.GetResourcesInput) bool {
+		return p.ResourceTypeFilters[0] == "dynamodb:table"
+	})).Return(&resourcegroupstaggingapi.GetResourcesOutput{ResourceTagMappingList: mappings}, nil)
+	return tagClient
+}
+
 type aasApiMock struct {
 	mock.Mock
 }
@@ -138,9 +160,10 @@ func TestGetAllTables(t *testing.T) {
 	ddb.On("DescribeTimeToLive", mock.Anything, mock.Anything).Return(&dynamodb.DescribeTimeToLiveOutput{
 		TimeToLiveDescription: &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled},
 	}, nil)
-	ddb.On("ListTagsOfResource", mock.Anything, mock.Anything).Return(&dynamodb.ListTagsOfResourceOutput{
-		Tags: []types.Tag{{Key: aws.String("application"), Value: aws.String("Demo")}},
-	}, nil)
+	tagClient := newTagClientMock(tagtypes.ResourceTagMapping{
+		ResourceARN: aws.String("arn:aws:dynamodb:us-east-1:42:table/orders"),
+		Tags:        []tagtypes.Tag{{Key: aws.String("application"), Value: aws.String("Demo")}},
+	})
 
 	aas.On("DescribeScalableTargets", mock.Anything, mock.Anything).Return(&applicationautoscaling.DescribeScalableTargetsOutput{
 		ScalableTargets: []aastypes.ScalableTarget{
@@ -160,7 +183,7 @@ func TestGetAllTables(t *testing.T) {
 		},
 	}, nil)
 
-	targets, err := getAllTables(context.Background(), ddb, aas, &utils.AwsAccess{
+	targets, err := getAllTables(context.Background(), ddb, aas, tagClient, &utils.AwsAccess{
 		AccountNumber: "42",
 		Region:        "us-east-1",
 		AssumeRole:    aws.String("arn:role"),
@@ -219,10 +242,9 @@ func TestGetAllTablesPayPerRequestDefaultsSse(t *testing.T) {
 	}, nil)
 	ddb.On("DescribeContinuousBackups", mock.Anything, mock.Anything).Return(&dynamodb.DescribeContinuousBackupsOutput{}, nil)
 	ddb.On("DescribeTimeToLive", mock.Anything, mock.Anything).Return(&dynamodb.DescribeTimeToLiveOutput{}, nil)
-	ddb.On("ListTagsOfResource", mock.Anything, mock.Anything).Return(&dynamodb.ListTagsOfResourceOutput{}, nil)
 	aas.On("DescribeScalableTargets", mock.Anything, mock.Anything).Return(&applicationautoscaling.DescribeScalableTargetsOutput{}, nil)
 
-	targets, err := getAllTables(context.Background(), ddb, aas, &utils.AwsAccess{AccountNumber: "42", Region: "us-east-1"})
+	targets, err := getAllTables(context.Background(), ddb, aas, newTagClientMock(), &utils.AwsAccess{AccountNumber: "42", Region: "us-east-1"})
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"PAY_PER_REQUEST"}, targets[0].Attributes["aws.dynamodb.billing-mode"])
 	assert.Equal(t, []string{"AWS_OWNED"}, targets[0].Attributes["aws.dynamodb.sse.type"], "should default to AWS_OWNED when SSE absent")
@@ -239,10 +261,9 @@ func TestGetAllTablesContinuesWhenAutoscalingFails(t *testing.T) {
 	}, nil)
 	ddb.On("DescribeContinuousBackups", mock.Anything, mock.Anything).Return(&dynamodb.DescribeContinuousBackupsOutput{}, nil)
 	ddb.On("DescribeTimeToLive", mock.Anything, mock.Anything).Return(&dynamodb.DescribeTimeToLiveOutput{}, nil)
-	ddb.On("ListTagsOfResource", mock.Anything, mock.Anything).Return(&dynamodb.ListTagsOfResourceOutput{}, nil)
 	aas.On("DescribeScalableTargets", mock.Anything, mock.Anything).Return(nil, errors.New("permission denied"))
 
-	targets, err := getAllTables(context.Background(), ddb, aas, &utils.AwsAccess{AccountNumber: "42", Region: "us-east-1"})
+	targets, err := getAllTables(context.Background(), ddb, aas, newTagClientMock(), &utils.AwsAccess{AccountNumber: "42", Region: "us-east-1"})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(targets))
 	assert.Equal(t, []string{"false"}, targets[0].Attributes["aws.dynamodb.autoscaling.read.enabled"])
@@ -254,6 +275,32 @@ func TestGetAllTablesError(t *testing.T) {
 	aas := new(aasApiMock)
 	aas.On("DescribeScalableTargets", mock.Anything, mock.Anything).Return(&applicationautoscaling.DescribeScalableTargetsOutput{}, nil)
 	ddb.On("ListTables", mock.Anything, mock.Anything).Return(nil, errors.New("expected"))
-	_, err := getAllTables(context.Background(), ddb, aas, &utils.AwsAccess{AccountNumber: "42", Region: "us-east-1"})
+	_, err := getAllTables(context.Background(), ddb, aas, newTagClientMock(), &utils.AwsAccess{AccountNumber: "42", Region: "us-east-1"})
 	assert.EqualError(t, err, "expected")
 }
+
+func TestGetAllTablesFiltersByTagsBeforeDescribing(t *testing.T) {
+	ddb := new(ddbApiMock)
+	aas := new(aasApiMock)
+	aas.On("DescribeScalableTargets", mock.Anything, mock.Anything).Return(&applicationautoscaling.DescribeScalableTargetsOutput{}, nil)
+	ddb.On("ListTables", mock.Anything, mock.Anything).Return(&dynamodb.ListTablesOutput{TableNames: []string{"orders", "untagged"}}, nil)
+	ddb.On("DescribeTable", mock.Anything, mock.Anything).Return(&dynamodb.DescribeTableOutput{
+		Table: &types.TableDescription{TableName: aws.String("orders"), TableArn: aws.String("arn:aws:dynamodb:us-east-1:42:table/orders")},
+	}, nil)
+	ddb.On("DescribeContinuousBackups", mock.Anything, mock.Anything).Return(&dynamodb.DescribeContinuousBackupsOutput{}, nil)
+	ddb.On("DescribeTimeToLive", mock.Anything, mock.Anything).Return(&dynamodb.DescribeTimeToLiveOutput{}, nil)
+	tagClient := newTagClientMock(tagtypes.ResourceTagMapping{
+		ResourceARN: aws.String("arn:aws:dynamodb:us-east-1:42:table/orders"),
+		Tags:        []tagtypes.Tag{{Key: aws.String("application"), Value: aws.String("Demo")}},
+	})
+
+	targets, err := getAllTables(context.Background(), ddb, aas, tagClient, &utils.AwsAccess{
+		AccountNumber: "42",
+		Region:        "us-east-1",
+		TagFilters:    []extConfig.TagFilter{{Key: "application", Values: []string{"Demo"}}},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, targets, 1)
+	ddb.AssertNumberOfCalls(t, "DescribeTable", 1)
+}
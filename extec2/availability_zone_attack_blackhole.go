@@ -17,6 +17,7 @@ import (
 	extension_kit "github.com/steadybit/extension-kit"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
+	"slices"
 )
 
 type azBlackholeAction struct {
@@ -79,6 +80,29 @@ func (e *azBlackholeAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+// ResourceArns returns the ARNs of the subnets in the zone. Their network ACL associations are replaced like by a subnet
+// blackhole, so an attack on the zone conflicts with attacks on each of its subnets.
+func (e *azBlackholeAction) ResourceArns(ctx context.Context, target *action_kit_api.Target) ([]string, error) {
+	targetAccount := extutil.MustHaveValue(target.Attributes, "aws.account")[0]
+	targetRegion := extutil.MustHaveValue(target.Attributes, "aws.region")[0]
+	clientEc2, _, err := e.clientProvider(targetAccount, targetRegion, utils.GetOptionalTargetAttribute(target.Attributes, "extension-aws.discovered-by-role"))
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AWS clients for AWS targetAccount %s", targetAccount), err)
+	}
+	targetSubnets, err := getTargetSubnetsForBlackholeZone(clientEc2, ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	var resourceArns []string
+	for _, subnetIds := range targetSubnets {
+		for _, subnetId := range subnetIds {
+			resourceArns = append(resourceArns, subnetArn(targetRegion, targetAccount, subnetId))
+		}
+	}
+	slices.Sort(resourceArns)
+	return resourceArns, nil
+}
+
 func (e *azBlackholeAction) Prepare(ctx context.Context, state *BlackholeState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	return prepareBlackhole(ctx, state, request, e.extensionRootAccountNumber, e.clientProvider, getTargetSubnetsForBlackholeZone)
 }
@@ -122,6 +146,14 @@ func (e *azBlackholeAction) Stop(ctx context.Context, state *BlackholeState) (*a
 	return stopBlackhole(ctx, state, e.clientProvider)
 }
 
+func (e *azBlackholeAction) DryRun(ctx context.Context, state *BlackholeState) ([]action_kit_api.Message, error) {
+	return dryRunBlackhole(ctx, state, e.clientProvider)
+}
+
+func (e *azBlackholeAction) RequiredPermissions() []string {
+	return blackholePermissions
+}
+
 func defaultClientProviderAzBlackhole(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error) {
 	awsAccess, err := utils.GetAwsAccess(account, region, role)
 	if err != nil {
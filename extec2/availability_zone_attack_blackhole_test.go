@@ -6,17 +6,20 @@ package extec2
 
 import (
 	"context"
+	"errors"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/google/uuid"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-kit/extutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 type clientEC2ApiMock struct {
@@ -83,6 +86,14 @@ func (m *clientImdsApiMock) GetInstanceIdentityDocument(ctx context.Context, par
 	return args.Get(0).(*imds.GetInstanceIdentityDocumentOutput), args.Error(1)
 }
 
+func (m *clientImdsApiMock) GetMetadata(ctx context.Context, params *imds.GetMetadataInput, optFns ...func(*imds.Options)) (*imds.GetMetadataOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*imds.GetMetadataOutput), args.Error(1)
+}
+
 func TestPrepareBlackhole(t *testing.T) {
 	clientEc2 := new(clientEC2ApiMock)
 	clientEc2.On("DescribeSubnets", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeSubnetsInput) bool {
@@ -145,6 +156,39 @@ func TestPrepareBlackhole(t *testing.T) {
 	clientImds.AssertExpectations(t)
 }
 
+func TestAzBlackholeLeasesSubnetsOfZone(t *testing.T) {
+	// Given
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeSubnets", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeSubnetsInput) bool {
+		return params.Filters[0].Values[0] == "eu-west-1a"
+	})).Return(new(ec2.DescribeSubnetsOutput{
+		Subnets: []types.Subnet{
+			{SubnetId: new("subnet-2"), VpcId: new("vpcId-1")},
+			{SubnetId: new("subnet-1"), VpcId: new("vpcId-2")},
+		},
+	}), nil)
+	action := azBlackholeAction{
+		clientProvider: func(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error) {
+			return clientEc2, nil, nil
+		}}
+	target := &action_kit_api.Target{Attributes: map[string][]string{
+		"aws.zone":          {"eu-west-1a"},
+		"aws.region":        {"eu-west-1"},
+		"aws.account":       {"42"},
+		"aws.ec2.subnet.id": {"subnet-1"},
+		"aws.vpc.id":        {"vpcId-2"},
+	}}
+
+	// When
+	resourceArns, err := action.ResourceArns(context.Background(), target)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arn:aws:ec2:eu-west-1:42:subnet/subnet-1", "arn:aws:ec2:eu-west-1:42:subnet/subnet-2"}, resourceArns)
+	subnetArns, _ := (&subnetBlackholeAction{}).ResourceArns(context.Background(), target)
+	assert.Subset(t, resourceArns, subnetArns, "the zone and the subnet blackhole lease the same subnet")
+}
+
 func TestShouldNotAttackWhenExtensionIsInTargetAccountId(t *testing.T) {
 	// Given
 	clientImds := new(clientImdsApiMock)
@@ -153,6 +197,7 @@ func TestShouldNotAttackWhenExtensionIsInTargetAccountId(t *testing.T) {
 			AccountID: "42",
 		},
 	}), nil)
+	clientImds.On("GetMetadata", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("not available"))
 
 	ctx := context.Background()
 	action := azBlackholeAction{
@@ -181,7 +226,7 @@ func TestShouldNotAttackWhenExtensionIsInTargetAccountId(t *testing.T) {
 	// When
 	_, err := action.Prepare(ctx, &state, requestBody)
 	// Then
-	assert.ErrorContains(t, err, "The extension is running in a protected AWS account ([42]). Attack is disabled to prevent an extension lockout.")
+	assert.ErrorContains(t, err, "The extension is running in a protected AWS account ([42]), but its subnets could not be determined via the EC2 or ECS task metadata. Attack is disabled to prevent an extension lockout.")
 	clientImds.AssertExpectations(t)
 }
 
@@ -189,6 +234,7 @@ func TestShouldNotAttackWhenExtensionIsInTargetAccountIdViaStsClient(t *testing.
 	// Given
 	clientImds := new(clientImdsApiMock)
 	clientImds.On("GetInstanceIdentityDocument", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	clientImds.On("GetMetadata", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("not available"))
 
 	ctx := context.Background()
 	action := azBlackholeAction{
@@ -217,7 +263,7 @@ func TestShouldNotAttackWhenExtensionIsInTargetAccountIdViaStsClient(t *testing.
 	// When
 	_, err := action.Prepare(ctx, &state, requestBody)
 	// Then
-	assert.ErrorContains(t, err, "The extension is running in a protected AWS account ([42]). Attack is disabled to prevent an extension lockout.")
+	assert.ErrorContains(t, err, "The extension is running in a protected AWS account ([42]), but its subnets could not be determined via the EC2 or ECS task metadata. Attack is disabled to prevent an extension lockout.")
 	clientImds.AssertExpectations(t)
 }
 
@@ -301,6 +347,7 @@ func TestShouldNotAttackWhenAgentIsInTargetAccountId(t *testing.T) {
 			AccountID: "41",
 		},
 	}), nil)
+	clientImds.On("GetMetadata", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("not available"))
 
 	ctx := context.Background()
 	action := azBlackholeAction{
@@ -329,13 +376,55 @@ func TestShouldNotAttackWhenAgentIsInTargetAccountId(t *testing.T) {
 	// When
 	_, err := action.Prepare(ctx, &state, requestBody)
 	// Then
-	assert.ErrorContains(t, err, "The agent is running in the same AWS account (42) as the target. Attack is disabled to prevent an agent lockout.")
+	assert.ErrorContains(t, err, "The agent is running in the same AWS account (42) as the target, but its subnets could not be determined. Attack is disabled to prevent an agent lockout.")
 
 	clientImds.AssertExpectations(t)
 }
 
+func TestShouldNotAttackWhenAgentIsInTargetAccountIdAndExtensionIsInAnotherAccount(t *testing.T) {
+	// Given
+	clientImds := new(clientImdsApiMock)
+	clientImds.On("GetInstanceIdentityDocument", mock.Anything, mock.Anything, mock.Anything).Return(new(imds.GetInstanceIdentityDocumentOutput{
+		InstanceIdentityDocument: imds.InstanceIdentityDocument{
+			AccountID: "41",
+		},
+	}), nil)
+	macsInput, macsOutput := imdsMetadata("network/interfaces/macs/", "0e:00:00:00:00:01/\n")
+	clientImds.On("GetMetadata", mock.Anything, macsInput, mock.Anything).Return(macsOutput, nil)
+	subnetInput, subnetOutput := imdsMetadata("network/interfaces/macs/0e:00:00:00:00:01/subnet-id", "subnet-1")
+	clientImds.On("GetMetadata", mock.Anything, subnetInput, mock.Anything).Return(subnetOutput, nil)
+
+	ctx := context.Background()
+	action := azBlackholeAction{
+		extensionRootAccountNumber: "",
+		clientProvider: func(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error) {
+			return nil, clientImds, nil
+		}}
+	state := action.NewEmptyState()
+
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Target: new(action_kit_api.Target{
+			Attributes: map[string][]string{
+				"aws.zone":    {"eu-west-1a"},
+				"aws.region":  {"eu-west-1"},
+				"aws.account": {"42"},
+			},
+		}),
+		ExecutionContext: new(action_kit_api.ExecutionContext{
+			AgentAwsAccountId: aws.String("42"),
+		}),
+	})
+
+	// When
+	_, err := action.Prepare(ctx, &state, requestBody)
+	// Then
+	assert.ErrorContains(t, err, "The agent is running in the same AWS account (42) as the target, but its subnets could not be determined. Attack is disabled to prevent an agent lockout.")
+}
+
 func TestStartBlackhole(t *testing.T) {
 	// Given
+	extConfig.Update(func(spec *extConfig.Specification) { spec.BlackholeOwnerId = "extension-aws-1" })
+	defer extConfig.Update(func(spec *extConfig.Specification) { spec.BlackholeOwnerId = "" })
 	executionId := uuid.New()
 	clientEc2 := new(clientEC2ApiMock)
 	clientEc2.On("DescribeNetworkAcls", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeNetworkAclsInput) bool {
@@ -374,6 +463,12 @@ func TestStartBlackhole(t *testing.T) {
 		require.Equal(t, new("nacl-1"), params.TagSpecifications[0].Tags[2].Value)
 		require.Equal(t, new("steadybit-replaced subnet-2"), params.TagSpecifications[0].Tags[3].Key)
 		require.Equal(t, new("nacl-2"), params.TagSpecifications[0].Tags[3].Value)
+		require.Equal(t, new("steadybit-owner-id"), params.TagSpecifications[0].Tags[4].Key)
+		require.Equal(t, new("extension-aws-1"), params.TagSpecifications[0].Tags[4].Value)
+		require.Equal(t, new("steadybit-created-at"), params.TagSpecifications[0].Tags[5].Key)
+		createdAt, err := time.Parse(time.RFC3339, *params.TagSpecifications[0].Tags[5].Value)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), createdAt, time.Minute)
 		return true
 	}), mock.Anything).Return(new(ec2.CreateNetworkAclOutput{
 		NetworkAcl: &types.NetworkAcl{
@@ -9,12 +9,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	types2 "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
 	"github.com/steadybit/extension-kit/extbuild"
-	"time"
 )
 
 type azDiscovery struct {
@@ -24,11 +22,14 @@ var (
 	_ discovery_kit_sdk.TargetDescriber = (*azDiscovery)(nil)
 )
 
+// AzDiscoveryPermissions lists the IAM actions called by the discovery.
+var AzDiscoveryPermissions = []string{"ec2:DescribeAvailabilityZones"}
+
 func NewAzDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &azDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalZone)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalZone }),
 	)
 }
 
@@ -36,7 +37,7 @@ func (a *azDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: azTargetType,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalZone)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalZone)),
 		},
 	}
 }
@@ -82,7 +83,7 @@ func getAllAvailabilityZonesFromCache(getZonesUtil GetZonesUtil, account *utils.
 	for _, availabilityZone := range getZonesUtil.GetZones(account) {
 		result = append(result, toAvailabilityZoneTarget(availabilityZone, account.AccountNumber, account.AssumeRole))
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesZone)
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesZone, config.Config().DiscoveryAttributesIncludesZone)
 }
 
 func toAvailabilityZoneTarget(availabilityZone types2.AvailabilityZone, awsAccountNumber string, role *string) discovery_kit_api.Target {
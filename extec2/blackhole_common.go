@@ -16,6 +16,7 @@ import (
 	"golang.org/x/exp/slices"
 	"sort"
 	"strings"
+	"time"
 )
 
 type BlackholeState struct {
@@ -27,6 +28,17 @@ type BlackholeState struct {
 	OldNetworkAclIds    map[string]string   // map[NewAssociationId] = oldNetworkAclId
 	TargetSubnets       map[string][]string // map[vpcId] = [subnetIds]
 	AttackExecutionId   uuid.UUID
+	Filter              *BlackholeFilter // nil blocks all traffic
+}
+
+// BlackholeFilter scopes a blackhole to some traffic. The entries of the replaced network ACLs are copied and deny
+// rules for the filter are inserted in front of them.
+type BlackholeFilter struct {
+	Protocols  []string // protocol numbers, -1 for all
+	PortRanges []types.PortRange
+	Cidrs      []string
+	Ingress    bool
+	Egress     bool
 }
 
 type blackholeEC2Api interface {
@@ -40,6 +52,7 @@ type blackholeEC2Api interface {
 
 type blackholeImdsApi interface {
 	GetInstanceIdentityDocument(ctx context.Context, params *imds.GetInstanceIdentityDocumentInput, optFns ...func(*imds.Options)) (*imds.GetInstanceIdentityDocumentOutput, error)
+	GetMetadata(ctx context.Context, params *imds.GetMetadataInput, optFns ...func(*imds.Options)) (*imds.GetMetadataOutput, error)
 }
 
 func prepareBlackhole(ctx context.Context, state *BlackholeState, request action_kit_api.PrepareActionRequestBody, extensionRootAccountNumber string, clientProvider func(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error), subnetProvider func(clientEc2 blackholeEC2Api, ctx context.Context, target *action_kit_api.Target) (map[string][]string, error)) (*action_kit_api.PrepareResult, error) {
@@ -57,9 +70,6 @@ func prepareBlackhole(ctx context.Context, state *BlackholeState, request action
 	if len(protectedAccounts) == 0 {
 		return nil, extension_kit.ToError("Could not get AWS Account of the extension. Attack is disabled to prevent an extension lockout.", nil)
 	}
-	if slices.Contains(protectedAccounts, targetAccount) {
-		return nil, extension_kit.ToError(fmt.Sprintf("The extension is running in a protected AWS account (%s). Attack is disabled to prevent an extension lockout.", protectedAccounts), nil)
-	}
 
 	agentAwsAccountId := ""
 	if request.ExecutionContext != nil && request.ExecutionContext.AgentAwsAccountId != nil {
@@ -70,8 +80,17 @@ func prepareBlackhole(ctx context.Context, state *BlackholeState, request action
 		return nil, extension_kit.ToError("Could not get AWS Account of the agent. Attack is disabled to prevent an agent lockout. Please check https://github.com/steadybit/extension-aws#agent-lockout---requirements", nil)
 	}
 
-	if targetAccount == agentAwsAccountId {
-		return nil, extension_kit.ToError(fmt.Sprintf("The agent is running in the same AWS account (%s) as the target. Attack is disabled to prevent an agent lockout.", agentAwsAccountId), nil)
+	// Instead of refusing attacks in the account of the extension or the agent, the subnets they run in are excluded
+	var location *blackholeLocation
+	extensionInTargetAccount := slices.Contains(protectedAccounts, targetAccount)
+	if extensionInTargetAccount || targetAccount == agentAwsAccountId {
+		location = getBlackholeLocation(ctx, clientImds, request)
+		if extensionInTargetAccount && !location.extensionDetected {
+			return nil, extension_kit.ToError(fmt.Sprintf("The extension is running in a protected AWS account (%s), but its subnets could not be determined via the EC2 or ECS task metadata. Attack is disabled to prevent an extension lockout.", protectedAccounts), nil)
+		}
+		if targetAccount == agentAwsAccountId && !location.agentDetected {
+			return nil, extension_kit.ToError(fmt.Sprintf("The agent is running in the same AWS account (%s) as the target, but its subnets could not be determined. Attack is disabled to prevent an agent lockout. Please add the subnets of the agent to STEADYBIT_EXTENSION_BLACKHOLE_PROTECTED_SUBNET_IDS, see https://github.com/steadybit/extension-aws#agent-lockout---requirements", agentAwsAccountId), nil)
+		}
 	}
 
 	// Get Target Subnets
@@ -80,13 +99,25 @@ func prepareBlackhole(ctx context.Context, state *BlackholeState, request action
 		return nil, err
 	}
 
+	var result *action_kit_api.PrepareResult
+	if location != nil {
+		var messages *action_kit_api.Messages
+		targetSubnets, messages, err = excludeLockoutSubnets(ctx, clientEc2, targetSubnets, location)
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to exclude the subnets of the extension and the agent. Attack is disabled to prevent a lockout.", err)
+		}
+		if messages != nil {
+			result = &action_kit_api.PrepareResult{Messages: messages}
+		}
+	}
+
 	state.AgentAWSAccount = agentAwsAccountId
 	state.ExtensionAwsAccount = targetAccount
 	state.TargetRegion = targetRegion
 	state.TargetSubnets = targetSubnets
 	state.AttackExecutionId = request.ExecutionId
 	state.DiscoveredByRole = discoveredByRole
-	return nil, nil
+	return result, nil
 }
 
 func startBlackhole(ctx context.Context, state *BlackholeState, clientProvider func(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error)) (*action_kit_api.StartResult, error) {
@@ -98,6 +129,7 @@ func startBlackhole(ctx context.Context, state *BlackholeState, clientProvider f
 	log.Debug().Msgf("Attack state: %+v", state)
 
 	state.OldNetworkAclIds = make(map[string]string)
+	trackBlackhole(state.AttackExecutionId)
 
 	vpcIds := make([]string, 0, len(state.TargetSubnets))
 	for vpcId := range state.TargetSubnets {
@@ -106,37 +138,62 @@ func startBlackhole(ctx context.Context, state *BlackholeState, clientProvider f
 	sort.Strings(vpcIds)
 
 	for _, vpcId := range vpcIds {
-		subnetIds := state.TargetSubnets[vpcId]
-		log.Info().Msgf("Creating temporary ACL to block traffic in VPC %s", vpcId)
-		//Find existing to be modified network acl associations matching the subnetIds in the given VPC
-		desiredAclAssociations, getNetworkAclAssociationsErr := getNetworkAclAssociations(ctx, clientEc2, vpcId, subnetIds)
-		if getNetworkAclAssociationsErr != nil {
-			log.Error().Err(getNetworkAclAssociationsErr).Msgf("Failed to get network ACL associations for VPC %s", vpcId)
-			err = extension_kit.ToError(fmt.Sprintf("Failed to get network ACL associations for VPC %s", vpcId), getNetworkAclAssociationsErr)
+		if err = blackholeVpc(ctx, state, clientEc2, vpcId); err != nil {
 			break
 		}
-		log.Info().Msgf("Found %d network ACL associations to modify", len(desiredAclAssociations))
+	}
 
-		networkAclId, createNetworkAclErr := createNetworkAcl(ctx, state, clientEc2, vpcId, desiredAclAssociations)
-		if createNetworkAclErr != nil {
-			log.Error().Err(createNetworkAclErr).Msgf("Failed to create network ACL for VPC %s", vpcId)
-			err = extension_kit.ToError(fmt.Sprintf("Failed to create network ACL for VPC %s", vpcId), createNetworkAclErr)
-			break
+	if err != nil {
+		_ = rollbackBlackholeViaTags(ctx, state, clientEc2)
+		untrackBlackhole(state.AttackExecutionId)
+	}
+	return nil, err
+}
+
+func blackholeVpc(ctx context.Context, state *BlackholeState, clientEc2 blackholeEC2Api, vpcId string) error {
+	subnetIds := state.TargetSubnets[vpcId]
+	log.Info().Msgf("Creating temporary ACL to block traffic in VPC %s", vpcId)
+	//Find existing to be modified network acl associations matching the subnetIds in the given VPC
+	desiredAclAssociations, err := getNetworkAclAssociations(ctx, clientEc2, vpcId, subnetIds)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to get network ACL associations for VPC %s", vpcId)
+		return extension_kit.ToError(fmt.Sprintf("Failed to get network ACL associations for VPC %s", vpcId), err)
+	}
+	log.Info().Msgf("Found %d network ACL associations to modify", len(desiredAclAssociations))
+
+	for _, aclAssociations := range groupNetworkAclAssociations(state, desiredAclAssociations) {
+		networkAclId, err := createNetworkAcl(ctx, state, clientEc2, vpcId, aclAssociations)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to create network ACL for VPC %s", vpcId)
+			return extension_kit.ToError(fmt.Sprintf("Failed to create network ACL for VPC %s", vpcId), err)
 		}
 
-		//Replace the association IDs for the above subnets with the new network acl which will deny all traffic for those subnets in that AZ
-		replaceNetworkAclAssociationsErr := replaceNetworkAclAssociations(ctx, state, clientEc2, desiredAclAssociations, networkAclId)
-		if replaceNetworkAclAssociationsErr != nil {
-			log.Error().Err(replaceNetworkAclAssociationsErr).Msgf("Failed to replace network ACL associations for VPC %s", vpcId)
-			err = extension_kit.ToError(fmt.Sprintf("Failed to replace network ACL associations for VPC %s", vpcId), replaceNetworkAclAssociationsErr)
-			break
+		//Replace the association IDs for the above subnets with the new network acl which will deny the traffic for those subnets
+		if err := replaceNetworkAclAssociations(ctx, state, clientEc2, aclAssociations, networkAclId); err != nil {
+			log.Error().Err(err).Msgf("Failed to replace network ACL associations for VPC %s", vpcId)
+			return extension_kit.ToError(fmt.Sprintf("Failed to replace network ACL associations for VPC %s", vpcId), err)
 		}
 	}
+	return nil
+}
 
-	if err != nil {
-		_ = rollbackBlackholeViaTags(ctx, state, clientEc2)
+// groupNetworkAclAssociations groups the associations by the network ACL to copy for a scoped blackhole. Without a
+// filter, all associations get the same deny-all ACL.
+func groupNetworkAclAssociations(state *BlackholeState, associations []types.NetworkAclAssociation) [][]types.NetworkAclAssociation {
+	if state.Filter == nil {
+		return [][]types.NetworkAclAssociation{associations}
 	}
-	return nil, err
+	groups := make([][]types.NetworkAclAssociation, 0)
+	indexByAclId := make(map[string]int)
+	for _, association := range associations {
+		if i, ok := indexByAclId[*association.NetworkAclId]; ok {
+			groups[i] = append(groups[i], association)
+		} else {
+			indexByAclId[*association.NetworkAclId] = len(groups)
+			groups = append(groups, []types.NetworkAclAssociation{association})
+		}
+	}
+	return groups
 }
 
 func stopBlackhole(ctx context.Context, state *BlackholeState, clientProvider func(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error)) (*action_kit_api.StopResult, error) {
@@ -145,9 +202,54 @@ func stopBlackhole(ctx context.Context, state *BlackholeState, clientProvider fu
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize EC2 client for AWS account %s and region %s", state.ExtensionAwsAccount, state.TargetRegion), err)
 	}
 
+	// a failed rollback is retried by the blackhole reconciler, as the execution is unknown from now on
+	untrackBlackhole(state.AttackExecutionId)
 	return nil, rollbackBlackholeViaTags(ctx, state, clientEc2)
 }
 
+var blackholePermissions = []string{
+	"ec2:DescribeSubnets",
+	"ec2:DescribeNetworkAcls",
+	"ec2:CreateNetworkAcl",
+	"ec2:CreateNetworkAclEntry",
+	"ec2:ReplaceNetworkAclAssociation",
+	"ec2:DeleteNetworkAcl",
+	"ec2:CreateTags",
+}
+
+// subnetArn is leased by every blackhole replacing the network ACL association of the subnet.
+func subnetArn(region string, account string, subnetId string) string {
+	return utils.BuildArn("ec2", region, account, "subnet/"+subnetId)
+}
+
+// dryRunBlackhole checks the creation of the network ACL with the DryRun flag. The remaining calls need the ids of the
+// created ACL, so their permissions are simulated.
+func dryRunBlackhole(ctx context.Context, state *BlackholeState, clientProvider func(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error)) ([]action_kit_api.Message, error) {
+	clientEc2, _, err := clientProvider(state.ExtensionAwsAccount, state.TargetRegion, state.DiscoveredByRole)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]action_kit_api.Message, 0)
+	for vpcId := range state.TargetSubnets {
+		_, err := clientEc2.CreateNetworkAcl(ctx, &ec2.CreateNetworkAclInput{VpcId: aws.String(vpcId), DryRun: aws.Bool(true)})
+		message, err := utils.Ec2DryRunMessage("CreateNetworkAcl", err)
+		if err != nil {
+			return nil, err
+		}
+		message.Message = fmt.Sprintf("%s (VPC %s)", message.Message, vpcId)
+		messages = append(messages, message)
+	}
+	simulated, err := utils.SimulatePermissions(ctx, &utils.DryRunState{
+		Account: state.ExtensionAwsAccount,
+		Region:  state.TargetRegion,
+		Role:    state.DiscoveredByRole,
+	}, []string{"ec2:CreateNetworkAclEntry", "ec2:ReplaceNetworkAclAssociation", "ec2:DeleteNetworkAcl", "ec2:CreateTags"})
+	if err != nil {
+		return nil, err
+	}
+	return append(messages, simulated...), nil
+}
+
 func getProtectedAWSAccounts(ctx context.Context, clientImds blackholeImdsApi, extensionRootAccountNumber string) []string {
 	ec2MetadataAccountId := getAccountNumberByEC2Metadata(ctx, clientImds)
 	if ec2MetadataAccountId == "" && extensionRootAccountNumber != "" {
@@ -219,6 +321,13 @@ func createNetworkAcl(ctx context.Context, state *BlackholeState, clientEc2 blac
 			Value: aws.String(*desiredAclAssociation.NetworkAclId),
 		})
 	}
+	tagList = append(tagList, types.Tag{
+		Key:   aws.String(blackholeOwnerIdTag),
+		Value: aws.String(getBlackholeOwnerId()),
+	}, types.Tag{
+		Key:   aws.String(blackholeCreatedAtTag),
+		Value: aws.String(time.Now().UTC().Format(time.RFC3339)),
+	})
 
 	createNetworkAclResult, err := clientEc2.CreateNetworkAcl(ctx, &ec2.CreateNetworkAclInput{
 		VpcId: aws.String(vpcId),
@@ -237,12 +346,103 @@ func createNetworkAcl(ctx context.Context, state *BlackholeState, clientEc2 blac
 
 	state.NetworkAclIds = append(state.NetworkAclIds, *createNetworkAclResult.NetworkAcl.NetworkAclId)
 
+	if state.Filter != nil {
+		// all associations of a scoped blackhole share the replaced network ACL
+		err = createScopedNetworkAclEntries(ctx, clientEc2, state.Filter, *createNetworkAclResult.NetworkAcl.NetworkAclId, *desiredAclAssociations[0].NetworkAclId)
+		return *createNetworkAclResult.NetworkAcl.NetworkAclId, err
+	}
+
 	//Create deny all egress rule
 	createNetworkAclEntry(ctx, clientEc2, *createNetworkAclResult.NetworkAcl.NetworkAclId, 100, true)
 	createNetworkAclEntry(ctx, clientEc2, *createNetworkAclResult.NetworkAcl.NetworkAclId, 101, false)
 	return *createNetworkAclResult.NetworkAcl.NetworkAclId, nil
 }
 
+// createScopedNetworkAclEntries copies the entries of the replaced network ACL and inserts the deny rules of the filter
+// in front of them. The rules are renumbered, keeping the order of the copied entries. Unlike the deny-all entries,
+// failures are returned, as a partial copy would block more traffic than intended.
+func createScopedNetworkAclEntries(ctx context.Context, clientEc2 blackholeEC2Api, filter *BlackholeFilter, networkAclId string, replacedNetworkAclId string) error {
+	describeNetworkAclsResult, err := clientEc2.DescribeNetworkAcls(ctx, &ec2.DescribeNetworkAclsInput{NetworkAclIds: []string{replacedNetworkAclId}})
+	if err != nil {
+		return err
+	}
+	if len(describeNetworkAclsResult.NetworkAcls) == 0 {
+		return fmt.Errorf("network ACL %s not found", replacedNetworkAclId)
+	}
+	replacedEntries := describeNetworkAclsResult.NetworkAcls[0].Entries
+	sort.SliceStable(replacedEntries, func(i, j int) bool {
+		return aws.ToInt32(replacedEntries[i].RuleNumber) < aws.ToInt32(replacedEntries[j].RuleNumber)
+	})
+
+	for _, egress := range []bool{true, false} {
+		entries := filter.scopedEntries(replacedEntries, egress)
+		for i, entry := range entries {
+			entry.NetworkAclId = aws.String(networkAclId)
+			entry.Egress = aws.Bool(egress)
+			entry.RuleNumber = aws.Int32(int32(i + 1))
+			if _, err := clientEc2.CreateNetworkAclEntry(ctx, &entry); err != nil {
+				log.Error().Err(err).Msgf("Failed to create network ACL entry for network ACL %s (rule %d, egress: %t)", networkAclId, i+1, egress)
+				return err
+			}
+		}
+		log.Debug().Msgf("Created %d network ACL entries for network ACL %s (egress: %t)", len(entries), networkAclId, egress)
+	}
+	return nil
+}
+
+const defaultNetworkAclRuleNumber = 32767
+
+// scopedEntries returns the deny rules of the filter followed by the copied entries of the replaced network ACL for one
+// direction, in the order of their rule numbers.
+func (f *BlackholeFilter) scopedEntries(replacedEntries []types.NetworkAclEntry, egress bool) []ec2.CreateNetworkAclEntryInput {
+	entries := make([]ec2.CreateNetworkAclEntryInput, 0)
+	if (egress && f.Egress) || (!egress && f.Ingress) {
+		entries = append(entries, f.denyEntries()...)
+	}
+	for _, entry := range replacedEntries {
+		// the default rule (*) is part of every network ACL
+		if aws.ToBool(entry.Egress) != egress || aws.ToInt32(entry.RuleNumber) >= defaultNetworkAclRuleNumber {
+			continue
+		}
+		entries = append(entries, ec2.CreateNetworkAclEntryInput{
+			CidrBlock:     entry.CidrBlock,
+			Ipv6CidrBlock: entry.Ipv6CidrBlock,
+			IcmpTypeCode:  entry.IcmpTypeCode,
+			PortRange:     entry.PortRange,
+			Protocol:      entry.Protocol,
+			RuleAction:    entry.RuleAction,
+		})
+	}
+	return entries
+}
+
+func (f *BlackholeFilter) denyEntries() []ec2.CreateNetworkAclEntryInput {
+	entries := make([]ec2.CreateNetworkAclEntryInput, 0)
+	for _, cidr := range f.Cidrs {
+		for _, protocol := range f.Protocols {
+			entry := ec2.CreateNetworkAclEntryInput{
+				Protocol:   aws.String(protocol),
+				RuleAction: types.RuleActionDeny,
+			}
+			if strings.Contains(cidr, ":") {
+				entry.Ipv6CidrBlock = aws.String(cidr)
+			} else {
+				entry.CidrBlock = aws.String(cidr)
+			}
+			// port ranges only apply to TCP and UDP
+			if protocol == "-1" || len(f.PortRanges) == 0 {
+				entries = append(entries, entry)
+				continue
+			}
+			for _, portRange := range f.PortRanges {
+				entry.PortRange = &types.PortRange{From: portRange.From, To: portRange.To}
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
 func createNetworkAclEntry(ctx context.Context, clientEc2 blackholeEC2Api, networkAclId string, ruleNumber int, egress bool) {
 	createdNetworkAclEntry, err := clientEc2.CreateNetworkAclEntry(ctx, &ec2.CreateNetworkAclEntryInput{
 		NetworkAclId: aws.String(networkAclId),
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extec2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-aws/v2/utils"
+)
+
+// Blackholes in the AWS account of the extension or the agent exclude the subnets they run in, instead of being
+// refused. The subnets of the extension are detected via the EC2 instance metadata, which also covers EKS nodes, and
+// the ECS task metadata. The location of the agent is not known to the extension, it is only taken from the configured
+// protected subnets and the restricted endpoints of the agent. Without them, blackholes in the account of the agent are
+// refused, as the agent might run in any subnet, even if it runs next to the extension.
+
+// blackholeLocation holds the subnets and addresses to exclude, each with the reason reported in the prepare messages.
+type blackholeLocation struct {
+	subnetIds         map[string]string
+	prefixes          []locatedPrefix
+	extensionDetected bool
+	agentDetected     bool
+}
+
+type locatedPrefix struct {
+	prefix netip.Prefix
+	reason string
+}
+
+var ecsTaskMetadataClient = &http.Client{Timeout: 2 * time.Second}
+
+func getBlackholeLocation(ctx context.Context, clientImds blackholeImdsApi, request action_kit_api.PrepareActionRequestBody) *blackholeLocation {
+	location := &blackholeLocation{subnetIds: make(map[string]string)}
+
+	for _, subnetId := range getSubnetIdsByEC2Metadata(ctx, clientImds) {
+		location.subnetIds[subnetId] = "the extension runs in it (EC2 instance metadata)"
+		location.extensionDetected = true
+	}
+	for _, address := range getAddressesByEcsTaskMetadata(ctx) {
+		location.prefixes = append(location.prefixes, locatedPrefix{prefix: netip.PrefixFrom(address, address.BitLen()), reason: fmt.Sprintf("the extension runs in it with address %s (ECS task metadata)", address)})
+		location.extensionDetected = true
+	}
+	for _, subnetId := range extConfig.Config().BlackholeProtectedSubnetIds {
+		location.subnetIds[subnetId] = "it is configured as protected subnet"
+		location.agentDetected = true
+	}
+	if request.ExecutionContext != nil && request.ExecutionContext.RestrictedEndpoints != nil {
+		for _, endpoint := range *request.ExecutionContext.RestrictedEndpoints {
+			prefix, err := netip.ParsePrefix(endpoint.Cidr)
+			if err != nil {
+				log.Debug().Err(err).Msgf("Ignoring restricted endpoint %s with invalid CIDR %s", endpoint.Name, endpoint.Cidr)
+				continue
+			}
+			location.prefixes = append(location.prefixes, locatedPrefix{prefix: prefix, reason: fmt.Sprintf("the agent uses the endpoint %s (%s) in it", endpoint.Name, endpoint.Cidr)})
+			location.agentDetected = true
+		}
+	}
+	return location
+}
+
+// getSubnetIdsByEC2Metadata returns the subnets of all network interfaces of the instance the extension runs on.
+func getSubnetIdsByEC2Metadata(ctx context.Context, clientImds blackholeImdsApi) []string {
+	macs, err := getEC2Metadata(ctx, clientImds, "network/interfaces/macs/")
+	if err != nil {
+		log.Debug().Err(err).Msg("Unable to get network interfaces by EC2-Metadata-Service.")
+		return nil
+	}
+	subnetIds := make([]string, 0)
+	for _, mac := range strings.Fields(macs) {
+		subnetId, err := getEC2Metadata(ctx, clientImds, "network/interfaces/macs/"+strings.TrimSuffix(mac, "/")+"/subnet-id")
+		if err != nil {
+			log.Debug().Err(err).Msgf("Unable to get subnet of network interface %s by EC2-Metadata-Service.", mac)
+			continue
+		}
+		subnetIds = append(subnetIds, strings.TrimSpace(subnetId))
+	}
+	return subnetIds
+}
+
+func getEC2Metadata(ctx context.Context, clientImds blackholeImdsApi, path string) (string, error) {
+	output, err := clientImds.GetMetadata(ctx, &imds.GetMetadataInput{Path: path})
+	if err != nil {
+		return "", err
+	}
+	if output == nil || output.Content == nil {
+		return "", fmt.Errorf("no content for %s", path)
+	}
+	defer func() { _ = output.Content.Close() }()
+	content, err := io.ReadAll(output.Content)
+	return string(content), err
+}
+
+type ecsTaskMetadata struct {
+	Containers []struct {
+		Networks []struct {
+			IPv4Addresses []string
+			IPv6Addresses []string
+		}
+	}
+}
+
+// getAddressesByEcsTaskMetadata returns the addresses of the ECS task the extension runs in, see
+// https://docs.aws.amazon.com/AmazonECS/latest/developerguide/task-metadata-endpoint-v4.html
+func getAddressesByEcsTaskMetadata(ctx context.Context) []netip.Addr {
+	uri := os.Getenv("ECS_CONTAINER_METADATA_URI_V4")
+	if uri == "" {
+		return nil
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri+"/task", nil)
+	if err != nil {
+		return nil
+	}
+	response, err := ecsTaskMetadataClient.Do(request)
+	if err != nil {
+		log.Debug().Err(err).Msg("Unable to get ECS task metadata.")
+		return nil
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	var metadata ecsTaskMetadata
+	if err := json.NewDecoder(response.Body).Decode(&metadata); err != nil {
+		log.Debug().Err(err).Msg("Unable to parse ECS task metadata.")
+		return nil
+	}
+	addresses := make([]netip.Addr, 0)
+	for _, container := range metadata.Containers {
+		for _, network := range container.Networks {
+			for _, value := range append(network.IPv4Addresses, network.IPv6Addresses...) {
+				if address, err := netip.ParseAddr(value); err == nil {
+					addresses = append(addresses, address)
+				}
+			}
+		}
+	}
+	return addresses
+}
+
+// excludeLockoutSubnets removes the subnets of the location from the target subnets and reports each excluded subnet.
+func excludeLockoutSubnets(ctx context.Context, clientEc2 blackholeEC2Api, targetSubnets map[string][]string, location *blackholeLocation) (map[string][]string, *action_kit_api.Messages, error) {
+	subnetIds := make([]string, 0)
+	for _, ids := range targetSubnets {
+		subnetIds = append(subnetIds, ids...)
+	}
+	sort.Strings(subnetIds)
+	if len(subnetIds) == 0 {
+		return targetSubnets, nil, nil
+	}
+
+	reasons := make(map[string]string)
+	paginator := ec2.NewDescribeSubnetsPaginator(clientEc2, &ec2.DescribeSubnetsInput{SubnetIds: subnetIds})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, subnet := range output.Subnets {
+			if reason := location.reasonToExclude(subnet.SubnetId, subnetCidrBlocks(subnet.CidrBlock, subnet.Ipv6CidrBlockAssociationSet)); reason != "" {
+				reasons[aws.ToString(subnet.SubnetId)] = reason
+			}
+		}
+	}
+
+	var messages *action_kit_api.Messages
+	result := make(map[string][]string)
+	for _, subnetId := range subnetIds {
+		if reason, ok := reasons[subnetId]; ok {
+			messages = utils.AppendInfof(messages, "Subnet %s is excluded from the blackhole, as %s.", subnetId, reason)
+		}
+	}
+	for vpcId, ids := range targetSubnets {
+		for _, subnetId := range ids {
+			if _, ok := reasons[subnetId]; !ok {
+				result[vpcId] = append(result[vpcId], subnetId)
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil, nil, fmt.Errorf("all subnets of the target are used by the extension or the agent: %s", strings.Join(subnetIds, ", "))
+	}
+	return result, messages, nil
+}
+
+func (l *blackholeLocation) reasonToExclude(subnetId *string, cidrBlocks []netip.Prefix) string {
+	if reason, ok := l.subnetIds[aws.ToString(subnetId)]; ok {
+		return reason
+	}
+	for _, cidrBlock := range cidrBlocks {
+		for _, located := range l.prefixes {
+			if cidrBlock.Overlaps(located.prefix) {
+				return located.reason
+			}
+		}
+	}
+	return ""
+}
+
+func subnetCidrBlocks(cidrBlock *string, ipv6Associations []types.SubnetIpv6CidrBlockAssociation) []netip.Prefix {
+	values := []string{aws.ToString(cidrBlock)}
+	for _, association := range ipv6Associations {
+		values = append(values, aws.ToString(association.Ipv6CidrBlock))
+	}
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extec2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func imdsMetadata(path string, content string) (any, any) {
+	return mock.MatchedBy(func(params *imds.GetMetadataInput) bool {
+		return params.Path == path
+	}), &imds.GetMetadataOutput{Content: io.NopCloser(strings.NewReader(content))}
+}
+
+func TestPrepareBlackholeExcludesSubnetsOfExtension(t *testing.T) {
+	// Given
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeSubnets", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeSubnetsInput) bool {
+		return len(params.Filters) > 0
+	})).Return(new(ec2.DescribeSubnetsOutput{
+		Subnets: []types.Subnet{
+			{SubnetId: new("subnet-1"), VpcId: new("vpcId-1")},
+			{SubnetId: new("subnet-2"), VpcId: new("vpcId-1")},
+			{SubnetId: new("subnet-3"), VpcId: new("vpcId-1")},
+		},
+	}), nil)
+	clientEc2.On("DescribeSubnets", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeSubnetsInput) bool {
+		return len(params.SubnetIds) == 3
+	})).Return(new(ec2.DescribeSubnetsOutput{
+		Subnets: []types.Subnet{
+			{SubnetId: new("subnet-1"), CidrBlock: new("10.0.1.0/24")},
+			{SubnetId: new("subnet-2"), CidrBlock: new("10.0.2.0/24")},
+			{SubnetId: new("subnet-3"), CidrBlock: new("10.0.3.0/24")},
+		},
+	}), nil)
+	clientImds := new(clientImdsApiMock)
+	clientImds.On("GetInstanceIdentityDocument", mock.Anything, mock.Anything, mock.Anything).Return(new(imds.GetInstanceIdentityDocumentOutput{
+		InstanceIdentityDocument: imds.InstanceIdentityDocument{AccountID: "42"},
+	}), nil)
+	macsInput, macsOutput := imdsMetadata("network/interfaces/macs/", "0e:00:00:00:00:01/\n")
+	clientImds.On("GetMetadata", mock.Anything, macsInput, mock.Anything).Return(macsOutput, nil)
+	subnetInput, subnetOutput := imdsMetadata("network/interfaces/macs/0e:00:00:00:00:01/subnet-id", "subnet-1")
+	clientImds.On("GetMetadata", mock.Anything, subnetInput, mock.Anything).Return(subnetOutput, nil)
+
+	action := azBlackholeAction{
+		clientProvider: func(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error) {
+			return clientEc2, clientImds, nil
+		}}
+	state := action.NewEmptyState()
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Target: new(action_kit_api.Target{
+			Attributes: map[string][]string{
+				"aws.zone":    {"eu-west-1a"},
+				"aws.region":  {"eu-west-1"},
+				"aws.account": {"42"},
+			},
+		}),
+		ExecutionContext: new(action_kit_api.ExecutionContext{
+			AgentAwsAccountId:   aws.String("42"),
+			RestrictedEndpoints: new([]action_kit_api.RestrictedEndpoint{{Name: "extension-aws", Cidr: "10.0.3.17/32"}}),
+		}),
+	})
+
+	// When
+	result, err := action.Prepare(context.Background(), &state, requestBody)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"vpcId-1": {"subnet-2"}}, state.TargetSubnets)
+	require.Len(t, *result.Messages, 2)
+	assert.Equal(t, "Subnet subnet-1 is excluded from the blackhole, as the extension runs in it (EC2 instance metadata).", (*result.Messages)[0].Message)
+	assert.Equal(t, "Subnet subnet-3 is excluded from the blackhole, as the agent uses the endpoint extension-aws (10.0.3.17/32) in it.", (*result.Messages)[1].Message)
+}
+
+func TestPrepareBlackholeExcludesProtectedSubnetsOfAgent(t *testing.T) {
+	// Given
+	extConfig.Update(func(spec *extConfig.Specification) { spec.BlackholeProtectedSubnetIds = []string{"subnet-2"} })
+	defer extConfig.Update(func(spec *extConfig.Specification) { spec.BlackholeProtectedSubnetIds = nil })
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeSubnets", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeSubnetsInput) bool {
+		return len(params.Filters) > 0
+	})).Return(new(ec2.DescribeSubnetsOutput{
+		Subnets: []types.Subnet{
+			{SubnetId: new("subnet-1"), VpcId: new("vpcId-1")},
+			{SubnetId: new("subnet-2"), VpcId: new("vpcId-1")},
+		},
+	}), nil)
+	clientEc2.On("DescribeSubnets", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeSubnetsInput) bool {
+		return len(params.SubnetIds) == 2
+	})).Return(new(ec2.DescribeSubnetsOutput{
+		Subnets: []types.Subnet{
+			{SubnetId: new("subnet-1"), CidrBlock: new("10.0.1.0/24")},
+			{SubnetId: new("subnet-2"), CidrBlock: new("10.0.2.0/24")},
+		},
+	}), nil)
+	clientImds := new(clientImdsApiMock)
+	clientImds.On("GetInstanceIdentityDocument", mock.Anything, mock.Anything, mock.Anything).Return(new(imds.GetInstanceIdentityDocumentOutput{
+		InstanceIdentityDocument: imds.InstanceIdentityDocument{AccountID: "41"},
+	}), nil)
+	clientImds.On("GetMetadata", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("not available"))
+
+	action := azBlackholeAction{
+		clientProvider: func(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error) {
+			return clientEc2, clientImds, nil
+		}}
+	state := action.NewEmptyState()
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Target: new(action_kit_api.Target{
+			Attributes: map[string][]string{
+				"aws.zone":    {"eu-west-1a"},
+				"aws.region":  {"eu-west-1"},
+				"aws.account": {"42"},
+			},
+		}),
+		ExecutionContext: new(action_kit_api.ExecutionContext{
+			AgentAwsAccountId: aws.String("42"),
+		}),
+	})
+
+	// When
+	result, err := action.Prepare(context.Background(), &state, requestBody)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"vpcId-1": {"subnet-1"}}, state.TargetSubnets)
+	require.Len(t, *result.Messages, 1)
+	assert.Equal(t, "Subnet subnet-2 is excluded from the blackhole, as it is configured as protected subnet.", (*result.Messages)[0].Message)
+}
+
+func TestExcludeLockoutSubnetsFailsIfAllSubnetsAreExcluded(t *testing.T) {
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeSubnets", mock.Anything, mock.Anything).Return(new(ec2.DescribeSubnetsOutput{
+		Subnets: []types.Subnet{{SubnetId: new("subnet-1"), CidrBlock: new("10.0.1.0/24")}},
+	}), nil)
+	location := &blackholeLocation{
+		subnetIds: map[string]string{},
+		prefixes:  []locatedPrefix{{prefix: netip.MustParsePrefix("10.0.1.5/32"), reason: "test"}},
+	}
+
+	_, _, err := excludeLockoutSubnets(context.Background(), clientEc2, map[string][]string{"vpcId-1": {"subnet-1"}}, location)
+
+	assert.ErrorContains(t, err, "all subnets of the target are used by the extension or the agent: subnet-1")
+}
+
+func TestGetAddressesByEcsTaskMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v4/task", r.URL.Path)
+		_, _ = w.Write([]byte(`{"Containers": [{"Networks": [{"NetworkMode": "awsvpc", "IPv4Addresses": ["10.0.2.106"]}]}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("ECS_CONTAINER_METADATA_URI_V4", server.URL+"/v4")
+
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("10.0.2.106")}, getAddressesByEcsTaskMetadata(context.Background()))
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extec2
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-aws/v2/utils"
+)
+
+// Blackholes are rolled back by stop. If stop never reaches the extension, the network ACLs created by the blackhole
+// stay associated and the subnets stay isolated. The blackhole reconciler periodically scans every configured account
+// and region for the network ACLs tagged with its owner id and rolls back executions which are unknown to this
+// extension, e.g. because the extension was restarted, or which run longer than BlackholeMaxTtl according to the
+// creation timestamp tagged on the network ACL. Network ACLs of other owners, e.g. other replicas or installations,
+// are never touched.
+
+const (
+	blackholeCleanupUnknownExecution = "unknown-execution"
+	blackholeCleanupExpired          = "expired"
+
+	blackholeOwnerIdTag   = "steadybit-owner-id"
+	blackholeCreatedAtTag = "steadybit-created-at"
+)
+
+var (
+	activeBlackholes      = make(map[uuid.UUID]bool)
+	activeBlackholesMutex sync.Mutex
+
+	reconcilerClientProvider = func(access *utils.AwsAccess) blackholeEC2Api {
+		return ec2.NewFromConfig(access.AwsConfig)
+	}
+)
+
+func trackBlackhole(executionId uuid.UUID) {
+	activeBlackholesMutex.Lock()
+	defer activeBlackholesMutex.Unlock()
+	activeBlackholes[executionId] = true
+}
+
+func untrackBlackhole(executionId uuid.UUID) {
+	activeBlackholesMutex.Lock()
+	defer activeBlackholesMutex.Unlock()
+	delete(activeBlackholes, executionId)
+}
+
+func isBlackholeActive(executionId uuid.UUID) bool {
+	activeBlackholesMutex.Lock()
+	defer activeBlackholesMutex.Unlock()
+	return activeBlackholes[executionId]
+}
+
+// getBlackholeOwnerId returns the owner id tagged on the network ACLs created by this extension.
+func getBlackholeOwnerId() string {
+	if ownerId := extConfig.Config().BlackholeOwnerId; ownerId != "" {
+		return ownerId
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		log.Warn().Err(err).Msg("Failed to get the hostname, using 'extension-aws' as blackhole owner id.")
+		return "extension-aws"
+	}
+	return hostname
+}
+
+// StartBlackholeReconciler scans for orphaned blackhole network ACLs every BlackholeReconcileInterval seconds.
+func StartBlackholeReconciler(ctx context.Context) {
+	if extConfig.Config().BlackholeReconcileInterval <= 0 {
+		return
+	}
+	interval := time.Duration(extConfig.Config().BlackholeReconcileInterval) * time.Second
+	log.Info().Msgf("Scanning for orphaned blackhole network ACLs of owner %s every %s.", getBlackholeOwnerId(), interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+			reconcileBlackholes(ctx)
+		}
+	}()
+}
+
+func reconcileBlackholes(ctx context.Context) {
+	// accesses with different roles for the same account and region see the same network ACLs
+	scanned := make(map[string]bool)
+	for _, access := range utils.GetConfiguredAwsAccesses() {
+		key := access.AccountNumber + "/" + access.Region
+		if scanned[key] {
+			continue
+		}
+		scanned[key] = true
+		reconcileBlackholesOfAccount(ctx, reconcilerClientProvider(&access), access.AccountNumber, access.Region)
+	}
+}
+
+// reconcileBlackholesOfAccount returns the number of executions which have been rolled back.
+func reconcileBlackholesOfAccount(ctx context.Context, clientEc2 blackholeEC2Api, account string, region string) int {
+	executions, err := getBlackholeExecutions(ctx, clientEc2, getBlackholeOwnerId())
+	if err != nil {
+		log.Warn().Err(err).Str("account", account).Str("region", region).Msg("Failed to scan for orphaned blackhole network ACLs.")
+		return 0
+	}
+
+	rolledBack := 0
+	maxTtl := time.Duration(extConfig.Config().BlackholeMaxTtl) * time.Second
+	for _, execution := range executions {
+		executionId := execution.executionId
+		reason := ""
+		if !isBlackholeActive(executionId) {
+			reason = blackholeCleanupUnknownExecution
+		} else if maxTtl > 0 && !execution.createdAt.IsZero() && time.Since(execution.createdAt) > maxTtl {
+			reason = blackholeCleanupExpired
+		} else {
+			continue
+		}
+
+		log.Warn().Str("account", account).Str("region", region).Msgf("Rolling back blackhole of execution %s (%s).", executionId, reason)
+		state := &BlackholeState{ExtensionAwsAccount: account, TargetRegion: region, AttackExecutionId: executionId}
+		err := rollbackBlackholeViaTags(ctx, state, clientEc2)
+		utils.RecordBlackholeCleanup(account, region, reason, err != nil)
+		if err != nil {
+			log.Error().Err(err).Str("account", account).Str("region", region).Msgf("Failed to roll back blackhole of execution %s. Retrying with the next scan.", executionId)
+			continue
+		}
+		untrackBlackhole(executionId)
+		rolledBack++
+		log.Info().Str("account", account).Str("region", region).Msgf("Rolled back blackhole of execution %s.", executionId)
+	}
+	return rolledBack
+}
+
+type blackholeExecution struct {
+	executionId uuid.UUID
+	createdAt   time.Time // zero if no network ACL of the execution has a valid creation timestamp
+}
+
+// getBlackholeExecutions returns the executions of all network ACLs created by the given owner, ordered by execution
+// id. The creation timestamp of an execution is the one of its oldest network ACL.
+func getBlackholeExecutions(ctx context.Context, clientEc2 blackholeEC2Api, ownerId string) ([]blackholeExecution, error) {
+	executions := make(map[uuid.UUID]*blackholeExecution)
+	paginator := ec2.NewDescribeNetworkAclsPaginator(clientEc2, &ec2.DescribeNetworkAclsInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("tag:Name"),
+				Values: []string{"created by steadybit"},
+			}, {
+				Name:   aws.String("tag-key"),
+				Values: []string{"steadybit-attack-execution-id"},
+			}, {
+				Name:   aws.String("tag:" + blackholeOwnerIdTag),
+				Values: []string{ownerId},
+			},
+		},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, networkAcl := range output.NetworkAcls {
+			var executionId *uuid.UUID
+			var createdAt time.Time
+			for _, tag := range networkAcl.Tags {
+				switch aws.ToString(tag.Key) {
+				case "steadybit-attack-execution-id":
+					parsed, err := uuid.Parse(aws.ToString(tag.Value))
+					if err != nil {
+						log.Warn().Msgf("Ignoring network ACL %s with invalid execution id %s.", aws.ToString(networkAcl.NetworkAclId), aws.ToString(tag.Value))
+						continue
+					}
+					executionId = &parsed
+				case blackholeCreatedAtTag:
+					parsed, err := time.Parse(time.RFC3339, aws.ToString(tag.Value))
+					if err != nil {
+						log.Warn().Msgf("Ignoring invalid creation timestamp %s of network ACL %s.", aws.ToString(tag.Value), aws.ToString(networkAcl.NetworkAclId))
+						continue
+					}
+					createdAt = parsed
+				}
+			}
+			if executionId == nil {
+				continue
+			}
+			execution, ok := executions[*executionId]
+			if !ok {
+				execution = &blackholeExecution{executionId: *executionId}
+				executions[*executionId] = execution
+			}
+			if !createdAt.IsZero() && (execution.createdAt.IsZero() || createdAt.Before(execution.createdAt)) {
+				execution.createdAt = createdAt
+			}
+		}
+	}
+
+	result := make([]blackholeExecution, 0, len(executions))
+	for _, execution := range executions {
+		result = append(result, *execution)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].executionId.String() < result[j].executionId.String()
+	})
+	return result, nil
+}
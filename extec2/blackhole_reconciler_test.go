@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extec2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/google/uuid"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func blackholeNetworkAcl(networkAclId string, executionId uuid.UUID, subnetId string, createdAt time.Time) types.NetworkAcl {
+	return types.NetworkAcl{
+		NetworkAclId: new(networkAclId),
+		Tags: []types.Tag{
+			{Key: new("Name"), Value: new("created by steadybit")},
+			{Key: new("steadybit-attack-execution-id"), Value: new(executionId.String())},
+			{Key: new("steadybit-replaced " + subnetId), Value: new("original-" + networkAclId)},
+			{Key: new("steadybit-owner-id"), Value: new("extension-aws-1")},
+			{Key: new("steadybit-created-at"), Value: new(createdAt.UTC().Format(time.RFC3339))},
+		},
+		Associations: []types.NetworkAclAssociation{
+			{NetworkAclAssociationId: new("association-" + networkAclId), NetworkAclId: new(networkAclId), SubnetId: new(subnetId)},
+		},
+	}
+}
+
+func TestReconcileBlackholesRollsBackUnknownAndExpiredExecutions(t *testing.T) {
+	// Given
+	extConfig.Update(func(spec *extConfig.Specification) {
+		spec.BlackholeMaxTtl = 3600
+		spec.BlackholeOwnerId = "extension-aws-1"
+	})
+	defer extConfig.Update(func(spec *extConfig.Specification) {
+		spec.BlackholeMaxTtl = 0
+		spec.BlackholeOwnerId = ""
+	})
+
+	unknownExecutionId := uuid.New()
+	activeExecutionId := uuid.New()
+	expiredExecutionId := uuid.New()
+	trackBlackhole(activeExecutionId)
+	trackBlackhole(expiredExecutionId)
+	defer untrackBlackhole(activeExecutionId)
+
+	networkAcls := map[string]types.NetworkAcl{
+		unknownExecutionId.String(): blackholeNetworkAcl("nacl-1", unknownExecutionId, "subnet-1", time.Now()),
+		activeExecutionId.String():  blackholeNetworkAcl("nacl-2", activeExecutionId, "subnet-2", time.Now().Add(-30*time.Minute)),
+		expiredExecutionId.String(): blackholeNetworkAcl("nacl-3", expiredExecutionId, "subnet-3", time.Now().Add(-2*time.Hour)),
+	}
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeNetworkAcls", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeNetworkAclsInput) bool {
+		return *params.Filters[1].Name == "tag-key" && *params.Filters[2].Name == "tag:steadybit-owner-id" && params.Filters[2].Values[0] == "extension-aws-1"
+	}), mock.Anything).Return(new(ec2.DescribeNetworkAclsOutput{
+		NetworkAcls: []types.NetworkAcl{networkAcls[unknownExecutionId.String()], networkAcls[activeExecutionId.String()], networkAcls[expiredExecutionId.String()]},
+	}), nil)
+	for executionId, networkAcl := range networkAcls {
+		clientEc2.On("DescribeNetworkAcls", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeNetworkAclsInput) bool {
+			return *params.Filters[1].Name == "tag:steadybit-attack-execution-id" && params.Filters[1].Values[0] == executionId
+		}), mock.Anything).Return(new(ec2.DescribeNetworkAclsOutput{NetworkAcls: []types.NetworkAcl{networkAcl}}), nil)
+	}
+	clientEc2.On("ReplaceNetworkAclAssociation", mock.Anything, mock.Anything, mock.Anything).Return(new(ec2.ReplaceNetworkAclAssociationOutput{
+		NewAssociationId: new("association-new"),
+	}), nil)
+	clientEc2.On("DeleteNetworkAcl", mock.Anything, mock.Anything, mock.Anything).Return(new(ec2.DeleteNetworkAclOutput{}), nil)
+
+	// When
+	rolledBack := reconcileBlackholesOfAccount(context.Background(), clientEc2, "42", "eu-west-1")
+
+	// Then
+	assert.Equal(t, 2, rolledBack)
+	clientEc2.AssertCalled(t, "ReplaceNetworkAclAssociation", mock.Anything, &ec2.ReplaceNetworkAclAssociationInput{AssociationId: new("association-nacl-1"), NetworkAclId: new("original-nacl-1")}, mock.Anything)
+	clientEc2.AssertCalled(t, "ReplaceNetworkAclAssociation", mock.Anything, &ec2.ReplaceNetworkAclAssociationInput{AssociationId: new("association-nacl-3"), NetworkAclId: new("original-nacl-3")}, mock.Anything)
+	clientEc2.AssertCalled(t, "DeleteNetworkAcl", mock.Anything, &ec2.DeleteNetworkAclInput{NetworkAclId: new("nacl-1")}, mock.Anything)
+	clientEc2.AssertCalled(t, "DeleteNetworkAcl", mock.Anything, &ec2.DeleteNetworkAclInput{NetworkAclId: new("nacl-3")}, mock.Anything)
+	clientEc2.AssertNumberOfCalls(t, "DeleteNetworkAcl", 2)
+
+	assert.False(t, isBlackholeActive(expiredExecutionId))
+	assert.True(t, isBlackholeActive(activeExecutionId))
+}
+
+func TestGetBlackholeExecutionsUsesOldestCreationTimestamp(t *testing.T) {
+	// Given
+	executionId := uuid.New()
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeNetworkAcls", mock.Anything, mock.Anything, mock.Anything).Return(new(ec2.DescribeNetworkAclsOutput{
+		NetworkAcls: []types.NetworkAcl{
+			blackholeNetworkAcl("nacl-1", executionId, "subnet-1", createdAt.Add(time.Minute)),
+			blackholeNetworkAcl("nacl-2", executionId, "subnet-2", createdAt),
+		},
+	}), nil)
+
+	// When
+	executions, err := getBlackholeExecutions(context.Background(), clientEc2, "extension-aws-1")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []blackholeExecution{{executionId: executionId, createdAt: createdAt}}, executions)
+}
@@ -18,3 +18,5 @@ const (
 	subnetTargetType         = "com.steadybit.extension_aws.ec2-subnet"
 	subnetIcon               = "data:image/svg+xml,%3Csvg%20width%3D%2222%22%20height%3D%2222%22%20viewBox%3D%220%200%2022%2022%22%20fill%3D%22none%22%20xmlns%3D%22http%3A%2F%2Fwww.w3.org%2F2000%2Fsvg%22%3E%0A%3Cpath%20fill-rule%3D%22evenodd%22%20clip-rule%3D%22evenodd%22%20d%3D%22M9.1768%202.76796C8.99372%202.76796%208.8453%202.91637%208.8453%203.09945V6.74586C8.8453%206.92893%208.99372%207.07735%209.1768%207.07735L11%207.07735L12.8232%207.07735C13.0063%207.07735%2013.1547%206.92893%2013.1547%206.74586V3.09945C13.1547%202.91637%2013.0063%202.76796%2012.8232%202.76796H9.1768ZM11.884%208.8453H12.8232C13.9827%208.8453%2014.9227%207.90535%2014.9227%206.74586V3.09945C14.9227%201.93995%2013.9827%201%2012.8232%201H9.1768C8.0173%201%207.07735%201.93995%207.07735%203.09945V6.74586C7.07735%207.90535%208.0173%208.8453%209.1768%208.8453H10.116V10.7238H6.13812C5.58131%2010.7238%205.04731%2010.9449%204.65359%2011.3387C4.25986%2011.7324%204.03867%2012.2664%204.03867%2012.8232V13.1547H3.09945C1.93996%2013.1547%201%2014.0947%201%2015.2541V18.9006C1%2020.06%201.93995%2021%203.09945%2021H6.74586C7.90535%2021%208.8453%2020.06%208.8453%2018.9006V15.2541C8.8453%2014.0947%207.90535%2013.1547%206.74586%2013.1547H5.80663V12.8232C5.80663%2012.7353%205.84156%2012.651%205.90372%2012.5888C5.96589%2012.5266%206.0502%2012.4917%206.13812%2012.4917H11H15.8619C15.9498%2012.4917%2016.0341%2012.5266%2016.0963%2012.5888C16.1584%2012.651%2016.1934%2012.7353%2016.1934%2012.8232V13.1547H15.2541C14.0947%2013.1547%2013.1547%2014.0947%2013.1547%2015.2541V18.9006C13.1547%2020.06%2014.0947%2021%2015.2541%2021H18.9006C20.06%2021%2021%2020.06%2021%2018.9006V15.2541C21%2014.0947%2020.06%2013.1547%2018.9006%2013.1547H17.9613V12.8232C17.9613%2012.2664%2017.7401%2011.7324%2017.3464%2011.3387C16.9527%2010.9449%2016.4187%2010.7238%2015.8619%2010.7238H11.884V8.8453ZM3.09945%2014.9227C2.91637%2014.9227%202.76796%2015.0711%202.76796%2015.2541V18.9006C2.76796%2019.0836%202.91637%2019.232%203.09945%2019.232H6.74586C6.92893%2019.232%207.07735%2019.0836%207.07735%2018.9006V15.2541C7.07735%2015.0711%206.92893%2014.9227%206.74586%2014.9227L4.92265%2014.9227L3.09945%2014.9227ZM15.2541%2014.9227L17.0773%2014.9227L18.9006%2014.9227C19.0836%2014.9227%2019.232%2015.0711%2019.232%2015.2541V18.9006C19.232%2019.0836%2019.0836%2019.232%2018.9006%2019.232H15.2541C15.0711%2019.232%2014.9227%2019.0836%2014.9227%2018.9006V15.2541C14.9227%2015.0711%2015.0711%2014.9227%2015.2541%2014.9227Z%22%20fill%3D%22%231D2632%22%2F%3E%0A%3C%2Fsvg%3E"
 )
+
+const subnetScopedBlackholeActionId = "com.steadybit.extension_aws.ec2-subnet.blackhole_ports"
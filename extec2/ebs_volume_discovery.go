@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -17,7 +16,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
@@ -31,10 +29,13 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*ebsVolumeDiscovery)(nil)
 )
 
+// EbsVolumeDiscoveryPermissions lists the IAM actions called by the discovery.
+var EbsVolumeDiscoveryPermissions = []string{"ec2:DescribeVolumes", "ec2:DescribeSnapshots"}
+
 func NewEbsVolumeDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(&ebsVolumeDiscovery{},
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalEbs)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalEbs }),
 	)
 }
 
@@ -42,7 +43,7 @@ func (d *ebsVolumeDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: ebsTargetType,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalEbs)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalEbs)),
 		},
 	}
 }
@@ -126,7 +127,7 @@ func getAllEbsVolumes(ctx context.Context, client ebsApi, account *utils.AwsAcce
 		}
 		result = append(result, toEbsVolumeTarget(v, latestSnapshot[aws.ToString(v.VolumeId)], account.AccountNumber, account.Region, account.AssumeRole))
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesEbs), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesEbs, config.Config().DiscoveryAttributesIncludesEbs), nil
 }
 
 func listAllVolumes(ctx context.Context, client ebsApi) ([]types.Volume, error) {
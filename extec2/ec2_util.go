@@ -23,6 +23,9 @@ type util struct {
 	vpcs  sync.Map
 }
 
+// Ec2UtilPermissions lists the IAM actions called to cache the zones and VPCs of every account.
+var Ec2UtilPermissions = []string{"ec2:DescribeAvailabilityZones", "ec2:DescribeVpcs"}
+
 func InitializeEc2Util() {
 	Util = &util{
 		zones: sync.Map{},
@@ -60,7 +63,7 @@ func initZonesCache(client AZDescribeAvailabilityZonesApi, awsAccountNumber stri
 }
 
 func initVpcCache(client ec2.DescribeVpcsAPIClient, awsAccountNumber string, region string, ctx context.Context) {
-	if !config.Config.DiscoveryDisabledVpc {
+	if !config.Config().DiscoveryDisabledVpc {
 		output, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{})
 		if err != nil {
 			var re *awshttp.ResponseError
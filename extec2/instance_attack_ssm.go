@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extec2
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-aws/v2/extssm"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// The SSM attacks on EC2 instances run the same AWSFIS documents as the attacks on ECS tasks. The network attacks use
+// the documents for instances, which roll back after `DurationSeconds` by themselves and need no heartbeat.
+
+var ec2InstanceSsmTarget = extssm.Target{
+	Label: "EC2 Instance",
+	ReadTarget: func(state *extssm.ActionState, target *action_kit_api.Target) {
+		state.InstanceId = extutil.MustHaveValue(target.Attributes, "aws-ec2.instance.id")[0]
+	},
+	TargetId: func(state *extssm.ActionState) string {
+		return state.InstanceId
+	},
+	FindManagedInstance: func(ctx context.Context, client extssm.Api, state *extssm.ActionState) (string, error) {
+		output, err := client.DescribeInstanceInformation(ctx, &ssm.DescribeInstanceInformationInput{
+			Filters: []types.InstanceInformationStringFilter{
+				{Key: new("InstanceIds"), Values: []string{state.InstanceId}},
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+		// Instances stay registered when the SSM Agent stops, but are no longer online.
+		for _, information := range output.InstanceInformationList {
+			if aws.ToString(information.InstanceId) == state.InstanceId && information.PingStatus == types.PingStatusOnline {
+				return state.InstanceId, nil
+			}
+		}
+		return "", extssm.ErrManagedInstanceNotFound
+	},
+	NotFoundDetail: func(_ *action_kit_api.Target) string {
+		return "Please make sure that the SSM Agent is running on the instance and that its instance profile allows it to register with Systems Manager, e.g. by attaching the AmazonSSMManagedInstanceCore policy."
+	},
+}
+
+func NewEc2InstanceStressCpuAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEc2InstanceSsmAction(extssm.StressCpuDescription(ec2TargetType), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-CPU-Stress",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "ExecuteStressNg",
+		GetParameters:    extssm.StressCpuParameters,
+	})
+}
+
+func NewEc2InstanceStressMemoryAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEc2InstanceSsmAction(extssm.StressMemoryDescription(ec2TargetType), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-Memory-Stress",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "ExecuteStressNg",
+		GetParameters:    extssm.StressMemoryParameters,
+	})
+}
+
+func NewEc2InstanceStressIoAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEc2InstanceSsmAction(extssm.StressIoDescription(ec2TargetType), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-IO-Stress",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "ExecuteStressNg",
+		GetParameters:    extssm.StressIoParameters,
+	})
+}
+
+func NewEc2InstanceFillDiskAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEc2InstanceSsmAction(extssm.FillDiskDescription(ec2TargetType), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-Disk-Fill",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "ExecuteDiskFill",
+		GetParameters:    extssm.FillDiskParameters,
+	})
+}
+
+func NewEc2InstanceStopProcessAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEc2InstanceSsmAction(extssm.StopProcessDescription(ec2TargetType), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-Kill-Process",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "KillProcess",
+		GetParameters:    extssm.StopProcessParameters,
+	})
+}
+
+func NewEc2InstanceNetworkDelayAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEc2InstanceSsmAction(extssm.NetworkDelayDescription(ec2TargetType), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-Network-Latency-Sources",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "FaultInjection",
+		GetParameters:    getEc2InstanceNetworkParameters(extssm.NetworkDelayParameters, "egress"),
+	})
+}
+
+func NewEc2InstanceNetworkLossAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEc2InstanceSsmAction(extssm.NetworkLossDescription(ec2TargetType), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-Network-Packet-Loss-Sources",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "FaultInjection",
+		GetParameters:    getEc2InstanceNetworkParameters(extssm.NetworkLossParameters, "egress"),
+	})
+}
+
+func NewEc2InstanceNetworkBlackholePortAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEc2InstanceSsmAction(extssm.NetworkBlackholePortDescription(ec2TargetType), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-Network-Blackhole-Port",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "FaultInjection",
+		GetParameters:    getEc2InstanceNetworkParameters(extssm.NetworkBlackholePortParameters, ""),
+	})
+}
+
+func newEc2InstanceSsmAction(description action_kit_api.ActionDescription, invocation extssm.CommandInvocation) action_kit_sdk.ActionWithStop[extssm.ActionState] {
+	description = getEc2InstanceDescription(description)
+	description.Icon = new(ec2Icon)
+	description.TargetSelection = &action_kit_api.TargetSelection{
+		TargetType: ec2TargetType,
+		SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+			{
+				Label:       "instance-id",
+				Description: new("Find ec2-instance by instance-id"),
+				Query:       "aws-ec2.instance.id=\"\"",
+			},
+			{
+				Label:       "instance-name",
+				Description: new("Find ec2-instance by instance-name"),
+				Query:       "aws-ec2.instance.name=\"\"",
+			},
+		}),
+	}
+	description.Category = new("EC2")
+	return extssm.NewAction(description, ec2InstanceSsmTarget, invocation)
+}
+
+// getEc2InstanceDescription adapts a description shared with the attacks on ECS tasks, e.g. the parameter label
+// `Container CPUs` becomes `CPUs`.
+func getEc2InstanceDescription(description action_kit_api.ActionDescription) action_kit_api.ActionDescription {
+	description.Hint = nil
+	for i, parameter := range description.Parameters {
+		description.Parameters[i].Label = strings.Replace(parameter.Label, "Container ", "", 1)
+	}
+	return description
+}
+
+func getEc2InstanceNetworkParameters(getParameters func(action_kit_api.PrepareActionRequestBody) (map[string][]string, error), trafficType string) func(action_kit_api.PrepareActionRequestBody) (map[string][]string, error) {
+	return func(request action_kit_api.PrepareActionRequestBody) (map[string][]string, error) {
+		parameters, err := getParameters(request)
+		if err != nil {
+			return nil, err
+		}
+		delete(parameters, extssm.FisActionStateParameterName)
+		if trafficType != "" {
+			parameters["TrafficType"] = []string{trafficType}
+		}
+		return parameters, nil
+	}
+}
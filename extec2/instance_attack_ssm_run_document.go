@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extec2
+
+import (
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-aws/v2/extssm"
+)
+
+func NewEc2InstanceRunSsmDocumentAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEc2InstanceSsmAction(extssm.RunSsmDocumentDescription(ec2TargetType), extssm.CommandInvocation{
+		GetParameters:  extssm.RunSsmDocumentParameters,
+		GetDocumentRun: extssm.RunSsmDocumentRun,
+	})
+}
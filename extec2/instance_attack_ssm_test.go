@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extec2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-aws/v2/extssm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ec2InstanceSsmTarget_FindManagedInstance(t *testing.T) {
+	tests := []struct {
+		name       string
+		pingStatus types.PingStatus
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{name: "should use online instance", pingStatus: types.PingStatusOnline, wantErr: assert.NoError},
+		{name: "should error on offline instance", pingStatus: types.PingStatusConnectionLost, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := new(ssmApiMock)
+			api.On("DescribeInstanceInformation", mock.Anything, mock.MatchedBy(func(params *ssm.DescribeInstanceInformationInput) bool {
+				return *params.Filters[0].Key == "InstanceIds" && params.Filters[0].Values[0] == "i-0"
+			})).Return(&ssm.DescribeInstanceInformationOutput{
+				InstanceInformationList: []types.InstanceInformation{{InstanceId: new("i-0"), PingStatus: tt.pingStatus}},
+			}, nil)
+
+			state := extssm.ActionState{}
+			ec2InstanceSsmTarget.ReadTarget(&state, &action_kit_api.Target{
+				Attributes: map[string][]string{"aws-ec2.instance.id": {"i-0"}},
+			})
+			managedInstanceId, err := ec2InstanceSsmTarget.FindManagedInstance(context.Background(), api, &state)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			if err == nil {
+				assert.Equal(t, "i-0", state.InstanceId)
+				assert.Equal(t, "i-0", managedInstanceId)
+			} else {
+				assert.ErrorIs(t, err, extssm.ErrManagedInstanceNotFound)
+			}
+		})
+	}
+}
+
+func Test_ec2InstanceSsmActions_Describe(t *testing.T) {
+	actions := map[string]action_kit_sdk.Action[extssm.ActionState]{
+		"com.steadybit.extension_aws.ec2-instance.stress_cpu":             NewEc2InstanceStressCpuAction(),
+		"com.steadybit.extension_aws.ec2-instance.stress_mem":             NewEc2InstanceStressMemoryAction(),
+		"com.steadybit.extension_aws.ec2-instance.stress_io":              NewEc2InstanceStressIoAction(),
+		"com.steadybit.extension_aws.ec2-instance.fill_disk":              NewEc2InstanceFillDiskAction(),
+		"com.steadybit.extension_aws.ec2-instance.stop-process":           NewEc2InstanceStopProcessAction(),
+		"com.steadybit.extension_aws.ec2-instance.network_delay":          NewEc2InstanceNetworkDelayAction(),
+		"com.steadybit.extension_aws.ec2-instance.network_loss":           NewEc2InstanceNetworkLossAction(),
+		"com.steadybit.extension_aws.ec2-instance.network_blackhole_port": NewEc2InstanceNetworkBlackholePortAction(),
+		"com.steadybit.extension_aws.ec2-instance.run_ssm_document":       NewEc2InstanceRunSsmDocumentAction(),
+	}
+	for id, action := range actions {
+		description := action.Describe()
+		assert.Equal(t, id, description.Id)
+		assert.Equal(t, ec2TargetType, description.TargetSelection.TargetType)
+		assert.Equal(t, "EC2", *description.Category)
+	}
+}
+
+func Test_getEc2InstanceDescription(t *testing.T) {
+	description := getEc2InstanceDescription(extssm.StressCpuDescription(ec2TargetType))
+
+	assert.Equal(t, "com.steadybit.extension_aws.ec2-instance.stress_cpu", description.Id)
+	assert.Equal(t, "Load on CPU", description.Parameters[0].Label)
+	assert.Equal(t, "CPUs", description.Parameters[1].Label)
+	assert.Nil(t, getEc2InstanceDescription(extssm.StressMemoryDescription(ec2TargetType)).Hint)
+}
+
+func Test_getEc2InstanceNetworkParameters(t *testing.T) {
+	getParameters := getEc2InstanceNetworkParameters(extssm.NetworkDelayParameters, "egress")
+
+	params, err := getParameters(action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Config: map[string]any{
+			"duration":     10000,
+			"networkDelay": 500,
+		},
+	})
+
+	require.NoError(t, err)
+	assert.NotContains(t, params, extssm.FisActionStateParameterName)
+	assert.Equal(t, []string{"egress"}, params["TrafficType"])
+	assert.Equal(t, []string{"500"}, params["DelayMilliseconds"])
+	assert.Equal(t, []string{"0.0.0.0/0"}, params["Sources"])
+}
+
+type ssmApiMock struct {
+	mock.Mock
+}
+
+func (m *ssmApiMock) SendCommand(ctx context.Context, params *ssm.SendCommandInput, optFns ...func(*ssm.Options)) (*ssm.SendCommandOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssm.SendCommandOutput), args.Error(1)
+}
+
+func (m *ssmApiMock) CancelCommand(ctx context.Context, params *ssm.CancelCommandInput, optFns ...func(*ssm.Options)) (*ssm.CancelCommandOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssm.CancelCommandOutput), args.Error(1)
+}
+
+func (m *ssmApiMock) DescribeInstanceInformation(ctx context.Context, params *ssm.DescribeInstanceInformationInput, optFns ...func(*ssm.Options)) (*ssm.DescribeInstanceInformationOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssm.DescribeInstanceInformationOutput), args.Error(1)
+}
+
+func (m *ssmApiMock) GetCommandInvocation(ctx context.Context, params *ssm.GetCommandInvocationInput, optFns ...func(*ssm.Options)) (*ssm.GetCommandInvocationOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssm.GetCommandInvocationOutput), args.Error(1)
+}
@@ -125,42 +125,76 @@ func (e *ec2InstanceStateAction) Start(ctx context.Context, state *InstanceState
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize EC2 client for AWS account %s", state.Account), err)
 	}
 
+	err = changeInstanceState(ctx, client, state, false)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to execute state change attack '%s' on instance '%s'", state.Action, state.InstanceId), err)
+	}
+
+	return nil, nil
+}
+
+func (e *ec2InstanceStateAction) DryRun(ctx context.Context, state *InstanceStateChangeState) ([]action_kit_api.Message, error) {
+	client, err := e.clientProvider(state.Account, state.Region, state.DiscoveredByRole)
+	if err != nil {
+		return nil, err
+	}
+	message, err := utils.Ec2DryRunMessage(instanceStateOperations[state.Action], changeInstanceState(ctx, client, state, true))
+	if err != nil {
+		return nil, err
+	}
+	return []action_kit_api.Message{message}, nil
+}
+
+func (e *ec2InstanceStateAction) RequiredPermissions() []string {
+	return []string{"ec2:RebootInstances", "ec2:StopInstances", "ec2:TerminateInstances", "ec2:StartInstances"}
+}
+
+var instanceStateOperations = map[string]string{
+	"reboot":    "RebootInstances",
+	"stop":      "StopInstances",
+	"hibernate": "StopInstances",
+	"terminate": "TerminateInstances",
+	"start":     "StartInstances",
+}
+
+func changeInstanceState(ctx context.Context, client ec2InstanceStateChangeApi, state *InstanceStateChangeState, dryRun bool) error {
 	instanceIds := []string{state.InstanceId}
 
+	var err error
 	if state.Action == "reboot" {
 		in := ec2.RebootInstancesInput{
 			InstanceIds: instanceIds,
+			DryRun:      new(dryRun),
 		}
 		_, err = client.RebootInstances(ctx, &in)
 	} else if state.Action == "stop" {
 		in := ec2.StopInstancesInput{
 			InstanceIds: instanceIds,
 			Hibernate:   new(false),
+			DryRun:      new(dryRun),
 		}
 		_, err = client.StopInstances(ctx, &in)
 	} else if state.Action == "hibernate" {
 		in := ec2.StopInstancesInput{
 			InstanceIds: instanceIds,
 			Hibernate:   new(true),
+			DryRun:      new(dryRun),
 		}
 		_, err = client.StopInstances(ctx, &in)
 	} else if state.Action == "terminate" {
 		in := ec2.TerminateInstancesInput{
 			InstanceIds: instanceIds,
+			DryRun:      new(dryRun),
 		}
 		_, err = client.TerminateInstances(ctx, &in)
 	} else if state.Action == "start" {
 		in := ec2.StartInstancesInput{
 			InstanceIds: instanceIds,
+			DryRun:      new(dryRun),
 		}
 		_, err = client.StartInstances(ctx, &in)
 	}
-
-	if err != nil {
-		return nil, extension_kit.ToError(fmt.Sprintf("Failed to execute state change attack '%s' on instance '%s'", state.Action, state.InstanceId), err)
-	}
-
-	return nil, nil
+	return err
 }
 
 func defaultClientProviderInstanceState(account string, region string, role *string) (ec2InstanceStateChangeApi, error) {
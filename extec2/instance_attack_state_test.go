@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	extension_kit "github.com/steadybit/extension-kit"
 	"github.com/steadybit/extension-kit/extutil"
@@ -268,3 +269,32 @@ func TestStartInstanceStateChangeForwardsError(t *testing.T) {
 
 	api.AssertExpectations(t)
 }
+
+func TestDryRunInstanceStateChange(t *testing.T) {
+	// Given
+	api := new(ec2ClientApiMock)
+	api.On("TerminateInstances", mock.Anything, mock.MatchedBy(func(params *ec2.TerminateInstancesInput) bool {
+		require.Equal(t, "dev-worker-1", params.InstanceIds[0])
+		require.True(t, *params.DryRun)
+		return true
+	})).Return(nil, &smithy.GenericAPIError{Code: "UnauthorizedOperation"})
+	action := ec2InstanceStateAction{clientProvider: func(account string, region string, role *string) (ec2InstanceStateChangeApi, error) {
+		return api, nil
+	}}
+
+	// When
+	messages, err := action.DryRun(context.Background(), &InstanceStateChangeState{
+		Account:    "42",
+		Region:     "us-west-1",
+		InstanceId: "dev-worker-1",
+		Action:     "terminate",
+	})
+
+	// Then
+	assert.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, action_kit_api.Warn, *messages[0].Level)
+	assert.Contains(t, messages[0].Message, "ec2:TerminateInstances is denied")
+
+	api.AssertExpectations(t)
+}
@@ -13,14 +13,12 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
 	"github.com/steadybit/extension-kit/extbuild"
 	"slices"
 	"strings"
-	"time"
 )
 
 type ec2Discovery struct{}
@@ -31,11 +29,14 @@ var (
 	_ discovery_kit_sdk.EnrichmentRulesDescriber = (*ec2Discovery)(nil)
 )
 
+// Ec2InstanceDiscoveryPermissions lists the IAM actions called by the discovery.
+var Ec2InstanceDiscoveryPermissions = []string{"ec2:DescribeInstances"}
+
 func NewEc2InstanceDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &ec2Discovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalEc2)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalEc2 }),
 	)
 }
 
@@ -43,7 +44,7 @@ func (e *ec2Discovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: ec2TargetType,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalEc2)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalEc2)),
 		},
 	}
 }
@@ -82,7 +83,7 @@ func (e *ec2Discovery) DescribeEnrichmentRules() []discovery_kit_api.TargetEnric
 		rules = append(rules, getEc2InstanceToHostEnrichmentRule(t))
 	}
 	rules = append(rules, getEc2InstanceToWindowsHostEnrichmentRule())
-	for _, targetType := range config.Config.EnrichEc2DataForTargetTypes {
+	for _, targetType := range config.Config().EnrichEc2DataForTargetTypes {
 		if slices.Contains([]string{"com.steadybit.extension_host.host", "com.steadybit.extension_kubernetes.kubernetes-node", "com.steadybit.extension_host_windows.host"}, targetType) {
 			log.Warn().Msgf("Target type %s is already covered by default rules. Omitting.", targetType)
 		} else {
@@ -100,13 +101,13 @@ func getEc2InstanceToHostEnrichmentRule(target string) discovery_kit_api.TargetE
 		Src: discovery_kit_api.SourceOrDestination{
 			Type: ec2TargetType,
 			Selector: map[string]string{
-				"aws-ec2.hostname.internal": fmt.Sprintf("${dest.%s}", config.Config.EnrichEc2DataMatcherAttribute),
+				"aws-ec2.hostname.internal": fmt.Sprintf("${dest.%s}", config.Config().EnrichEc2DataMatcherAttribute),
 			},
 		},
 		Dest: discovery_kit_api.SourceOrDestination{
 			Type: target,
 			Selector: map[string]string{
-				config.Config.EnrichEc2DataMatcherAttribute: "${src.aws-ec2.hostname.internal}",
+				config.Config().EnrichEc2DataMatcherAttribute: "${src.aws-ec2.hostname.internal}",
 			},
 		},
 		Attributes: []discovery_kit_api.Attribute{
@@ -251,13 +252,13 @@ func getEc2InstanceToXEnrichmentRule(destTargetType string) discovery_kit_api.Ta
 		Src: discovery_kit_api.SourceOrDestination{
 			Type: ec2TargetType,
 			Selector: map[string]string{
-				"aws-ec2.hostname.internal": fmt.Sprintf("${dest.%s}", config.Config.EnrichEc2DataMatcherAttribute),
+				"aws-ec2.hostname.internal": fmt.Sprintf("${dest.%s}", config.Config().EnrichEc2DataMatcherAttribute),
 			},
 		},
 		Dest: discovery_kit_api.SourceOrDestination{
 			Type: destTargetType,
 			Selector: map[string]string{
-				config.Config.EnrichEc2DataMatcherAttribute: "${src.aws-ec2.hostname.internal}",
+				config.Config().EnrichEc2DataMatcherAttribute: "${src.aws-ec2.hostname.internal}",
 			},
 		},
 		Attributes: []discovery_kit_api.Attribute{
@@ -354,6 +355,10 @@ func GetAllEc2Instances(ctx context.Context, ec2Api ec2.DescribeInstancesAPIClie
 	if len(account.TagFilters) > 0 {
 		input.Filters = make([]types.Filter, 0, len(account.TagFilters))
 		for _, tagFilter := range account.TagFilters {
+			// only plain filters can be passed to the API, all others are applied after the describe call
+			if !tagFilter.IsExactMatch() {
+				continue
+			}
 			input.Filters = append(input.Filters, types.Filter{
 				Name:   new("tag:" + tagFilter.Key),
 				Values: tagFilter.Values,
@@ -361,6 +366,7 @@ func GetAllEc2Instances(ctx context.Context, ec2Api ec2.DescribeInstancesAPIClie
 		}
 	}
 
+	filterAfterDescribe := slices.ContainsFunc(account.TagFilters, func(filter config.TagFilter) bool { return !filter.IsExactMatch() })
 	paginator := ec2.NewDescribeInstancesPaginator(ec2Api, &input)
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
@@ -369,12 +375,15 @@ func GetAllEc2Instances(ctx context.Context, ec2Api ec2.DescribeInstancesAPIClie
 		}
 		for _, reservation := range output.Reservations {
 			for _, ec2Instance := range reservation.Instances {
+				if filterAfterDescribe && !matchesEc2TagFilter(ec2Instance.Tags, account.TagFilters) {
+					continue
+				}
 				result = append(result, toEc2InstanceTarget(ec2Instance, ec2Util, account.AccountNumber, account.Region, account.AssumeRole))
 			}
 		}
 	}
 
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesEc2), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesEc2, config.Config().DiscoveryAttributesIncludesEc2), nil
 }
 
 func toEc2InstanceTarget(ec2Instance types.Instance, ec2Util instanceDiscoveryEc2Util, awsAccountNumber string, awsRegion string, role *string) discovery_kit_api.Target {
@@ -385,7 +394,7 @@ func toEc2InstanceTarget(ec2Instance types.Instance, ec2Util instanceDiscoveryEc
 		}
 	}
 
-	arn := fmt.Sprintf("arn:aws:ec2:%s:%s:instance/%s", awsRegion, awsAccountNumber, *ec2Instance.InstanceId)
+	arn := utils.BuildArn("ec2", awsRegion, awsAccountNumber, "instance/"+*ec2Instance.InstanceId)
 	label := *ec2Instance.InstanceId
 	if name != nil {
 		label = label + " / " + *name
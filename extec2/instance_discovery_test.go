@@ -105,7 +105,9 @@ func TestGetAllEc2Instances(t *testing.T) {
 func TestGetAllEc2InstancesWithFilteredAttributes(t *testing.T) {
 	// Given
 	// set env var to filter out all attributes starting with "aws-ec2"
-	config.Config.DiscoveryAttributesExcludesEc2 = []string{"aws-ec2.label.*", "aws-ec2.image"}
+	config.Update(func(spec *config.Specification) {
+		spec.DiscoveryAttributesExcludesEc2 = []string{"aws-ec2.label.*", "aws-ec2.image"}
+	})
 	mockedApi := new(instanceDiscoveryApiMock)
 	mockedReturnValue := ec2.DescribeInstancesOutput{
 		Reservations: []types.Reservation{
@@ -204,6 +206,51 @@ func TestGetAllEc2InstancesShouldApplyTagFilters(t *testing.T) {
 	mockedApi.AssertExpectations(t)
 }
 
+func TestGetAllEc2InstancesShouldApplyAdvancedTagFiltersAfterDescribe(t *testing.T) {
+	// Given
+	mockedApi := new(instanceDiscoveryApiMock)
+	otherInstance := instance
+	otherInstance.InstanceId = new("i-other")
+	otherInstance.Tags = []types.Tag{{Key: new("SpecialTag"), Value: new("Boring Thing")}}
+	mockedReturnValue := ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: []types.Instance{
+					instance,
+					otherInstance,
+				},
+			},
+		},
+	}
+	mockedApi.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return len(input.Filters) == 1 && aws.ToString(input.Filters[0].Name) == "tag:Name"
+	})).Return(&mockedReturnValue, nil)
+
+	mockedZoneUtil := new(ec2UtilMock)
+	mockedZoneUtil.On("GetZone", mock.Anything, mock.Anything, mock.Anything).Return(&types.AvailabilityZone{
+		ZoneName:   new("us-east-1b"),
+		RegionName: new("us-east-1"),
+		ZoneId:     new("us-east-1b-id"),
+	})
+	mockedZoneUtil.On("GetVpcName", mock.Anything, mock.Anything, mock.Anything).Return("vpc-123-name")
+
+	// When
+	targets, err := GetAllEc2Instances(context.Background(), mockedApi, mockedZoneUtil, &utils.AwsAccess{
+		AccountNumber: "42",
+		Region:        "us-east-1",
+		TagFilters: []config.TagFilter{
+			{Key: "Name", Values: []string{"dev-demo-ngroup2"}},
+			{Key: "SpecialTag", Operator: config.TagFilterOperatorGlob, Values: []string{"Great*"}},
+		},
+	})
+
+	// Then
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(targets))
+	assert.Equal(t, aws.ToString(instance.InstanceId), targets[0].Attributes["aws-ec2.instance.id"][0])
+	mockedApi.AssertExpectations(t)
+}
+
 func TestNameNotSet(t *testing.T) {
 	// Given
 	mockedApi := new(instanceDiscoveryApiMock)
@@ -10,7 +10,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -18,7 +17,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
@@ -32,10 +30,13 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*natGatewayDiscovery)(nil)
 )
 
+// NatGatewayDiscoveryPermissions lists the IAM actions called by the discovery.
+var NatGatewayDiscoveryPermissions = []string{"ec2:DescribeNatGateways", "ec2:DescribeSubnets"}
+
 func NewNatGatewayDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(&natGatewayDiscovery{},
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalNatGateway)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalNatGateway }),
 	)
 }
 
@@ -43,7 +44,7 @@ func (d *natGatewayDiscovery) Describe() discovery_kit_api.DiscoveryDescription
 	return discovery_kit_api.DiscoveryDescription{
 		Id: natGatewayTargetType,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalNatGateway)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalNatGateway)),
 		},
 	}
 }
@@ -134,7 +135,7 @@ func getAllNatGateways(ctx context.Context, client natGatewayApi, account *utils
 		}
 		result = append(result, toNatGatewayTarget(gw, subnetToAz, gwsPerVpc, account.AccountNumber, account.Region, account.AssumeRole))
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesNatGateway), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesNatGateway, config.Config().DiscoveryAttributesIncludesNatGateway), nil
 }
 
 func listAllNatGateways(ctx context.Context, client natGatewayApi) ([]types.NatGateway, error) {
@@ -185,26 +186,13 @@ func matchesEc2TagFilter(tags []types.Tag, filters []config.TagFilter) bool {
 	if len(filters) == 0 {
 		return true
 	}
-	for _, filter := range filters {
-		matched := false
-		for _, tag := range tags {
-			if tag.Key != nil && *tag.Key == filter.Key {
-				for _, v := range filter.Values {
-					if tag.Value != nil && *tag.Value == v {
-						matched = true
-						break
-					}
-				}
-			}
-			if matched {
-				break
-			}
-		}
-		if !matched {
-			return false
+	tagMap := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if tag.Key != nil {
+			tagMap[*tag.Key] = aws.ToString(tag.Value)
 		}
 	}
-	return true
+	return utils.MatchesTags(tagMap, filters)
 }
 
 func toNatGatewayTarget(gw types.NatGateway, subnetToAz map[string]string, gwsPerVpc map[string]int, account string, region string, role *string) discovery_kit_api.Target {
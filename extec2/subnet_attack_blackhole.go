@@ -79,6 +79,11 @@ func (e *subnetBlackholeAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (e *subnetBlackholeAction) ResourceArns(_ context.Context, target *action_kit_api.Target) ([]string, error) {
+	return []string{subnetArn(extutil.MustHaveValue(target.Attributes, "aws.region")[0], extutil.MustHaveValue(target.Attributes, "aws.account")[0],
+		extutil.MustHaveValue(target.Attributes, "aws.ec2.subnet.id")[0])}, nil
+}
+
 func (e *subnetBlackholeAction) Prepare(ctx context.Context, state *BlackholeState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	return prepareBlackhole(ctx, state, request, e.extensionRootAccountNumber, e.clientProvider, getTargetSubnetsForBlackholeSubnet)
 }
@@ -97,6 +102,14 @@ func (e *subnetBlackholeAction) Stop(ctx context.Context, state *BlackholeState)
 	return stopBlackhole(ctx, state, e.clientProvider)
 }
 
+func (e *subnetBlackholeAction) DryRun(ctx context.Context, state *BlackholeState) ([]action_kit_api.Message, error) {
+	return dryRunBlackhole(ctx, state, e.clientProvider)
+}
+
+func (e *subnetBlackholeAction) RequiredPermissions() []string {
+	return blackholePermissions
+}
+
 func defaultClientProviderSubnetBlackhole(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error) {
 	awsAccess, err := utils.GetAwsAccess(account, region, role)
 	if err != nil {
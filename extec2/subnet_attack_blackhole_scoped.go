@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extec2
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-aws/v2/utils"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// subnetScopedBlackholeAction blocks only the traffic matching the protocols, ports and CIDRs. It shares the start,
+// stop and rollback of the subnet blackhole.
+type subnetScopedBlackholeAction struct {
+	subnetBlackholeAction
+}
+
+// Make sure subnetScopedBlackholeAction implements all required interfaces
+var _ action_kit_sdk.Action[BlackholeState] = (*subnetScopedBlackholeAction)(nil)
+var _ action_kit_sdk.ActionWithStop[BlackholeState] = (*subnetScopedBlackholeAction)(nil)
+
+var blackholeProtocols = map[string][]string{
+	"tcp":     {"6"},
+	"udp":     {"17"},
+	"tcp-udp": {"6", "17"},
+	"all":     {"-1"},
+}
+
+func NewSubnetScopedBlackholeAction() action_kit_sdk.Action[BlackholeState] {
+	return &subnetScopedBlackholeAction{
+		subnetBlackholeAction: subnetBlackholeAction{
+			clientProvider:             defaultClientProviderSubnetBlackhole,
+			extensionRootAccountNumber: utils.GetRootAccountNumber(),
+		},
+	}
+}
+
+func (e *subnetScopedBlackholeAction) Describe() action_kit_api.ActionDescription {
+	description := e.subnetBlackholeAction.Describe()
+	description.Id = subnetScopedBlackholeActionId
+	description.Label = "Blackhole Subnet Ports"
+	description.Description = "Block traffic of a given subnet for some protocols, ports and remote CIDRs. All other traffic is handled by the existing network ACL rules."
+	description.Parameters = []action_kit_api.ActionParameter{
+		{
+			Name:         "duration",
+			Label:        "Duration",
+			Description:  new(""),
+			Type:         action_kit_api.ActionParameterTypeDuration,
+			DefaultValue: new("60s"),
+			Order:        new(1),
+			Required:     new(true),
+		},
+		{
+			Name:        "protocol",
+			Label:       "Protocol",
+			Description: new("The protocol of the traffic to block."),
+			Type:        action_kit_api.ActionParameterTypeString,
+			Options: new([]action_kit_api.ParameterOption{
+				action_kit_api.ExplicitParameterOption{Label: "TCP", Value: "tcp"},
+				action_kit_api.ExplicitParameterOption{Label: "UDP", Value: "udp"},
+				action_kit_api.ExplicitParameterOption{Label: "TCP and UDP", Value: "tcp-udp"},
+				action_kit_api.ExplicitParameterOption{Label: "All", Value: "all"},
+			}),
+			DefaultValue: new("tcp"),
+			Order:        new(2),
+			Required:     new(true),
+		},
+		{
+			Name:        "ports",
+			Label:       "Ports",
+			Description: new("Ports or port ranges to block, e.g. 5432 or 8000-8100. Ingress rules match the ports in the subnet, egress rules the remote ports. Leave empty to block all ports."),
+			Type:        action_kit_api.ActionParameterTypeStringArray,
+			Order:       new(3),
+			Required:    new(false),
+		},
+		{
+			Name:        "cidrs",
+			Label:       "Remote CIDRs",
+			Description: new("IPv4 or IPv6 CIDRs of the remote side, e.g. the CIDR of another subnet. Leave empty to block traffic from and to 0.0.0.0/0, and ::/0 for subnets with an IPv6 CIDR."),
+			Type:        action_kit_api.ActionParameterTypeStringArray,
+			Order:       new(4),
+			Required:    new(false),
+		},
+		{
+			Name:        "direction",
+			Label:       "Direction",
+			Description: new("The direction of the traffic to block."),
+			Type:        action_kit_api.ActionParameterTypeString,
+			Options: new([]action_kit_api.ParameterOption{
+				action_kit_api.ExplicitParameterOption{Label: "Ingress and Egress", Value: "both"},
+				action_kit_api.ExplicitParameterOption{Label: "Ingress", Value: "ingress"},
+				action_kit_api.ExplicitParameterOption{Label: "Egress", Value: "egress"},
+			}),
+			DefaultValue: new("both"),
+			Order:        new(5),
+			Required:     new(true),
+		},
+	}
+	return description
+}
+
+func (e *subnetScopedBlackholeAction) Prepare(ctx context.Context, state *BlackholeState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	filter, err := getBlackholeFilter(request.Config)
+	if err != nil {
+		return nil, extension_kit.ToError("Invalid configuration of the blackhole", err)
+	}
+	result, err := e.subnetBlackholeAction.Prepare(ctx, state, request)
+	if err != nil {
+		return result, err
+	}
+	clientEc2, _, err := e.clientProvider(state.ExtensionAwsAccount, state.TargetRegion, state.DiscoveredByRole)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize EC2 client for AWS account %s", state.ExtensionAwsAccount), err)
+	}
+
+	// Without CIDRs, the IPv6 traffic of dual-stack subnets is blocked as well.
+	if len(extutil.ToStringArray(request.Config["cidrs"])) == 0 {
+		hasIpv6, err := hasIpv6CidrBlock(ctx, clientEc2, state.TargetSubnets)
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to describe the subnets of the blackhole", err)
+		}
+		if hasIpv6 {
+			filter.Cidrs = append(filter.Cidrs, "::/0")
+		}
+	}
+	if err := checkNetworkAclRulesQuota(ctx, clientEc2, filter, state.TargetSubnets); err != nil {
+		return nil, extension_kit.ToError("Invalid configuration of the blackhole", err)
+	}
+	state.Filter = filter
+	return result, nil
+}
+
+// checkNetworkAclRulesQuota returns an error if the deny rules of the filter and the copied entries of a network ACL
+// associated with the subnets exceed the rules quota of a direction.
+func checkNetworkAclRulesQuota(ctx context.Context, clientEc2 blackholeEC2Api, filter *BlackholeFilter, targetSubnets map[string][]string) error {
+	quota := extConfig.Config().BlackholeNetworkAclRulesQuota
+	if quota <= 0 {
+		return nil
+	}
+	subnetIds := make([]string, 0)
+	for _, ids := range targetSubnets {
+		subnetIds = append(subnetIds, ids...)
+	}
+	if len(subnetIds) == 0 {
+		return nil
+	}
+
+	paginator := ec2.NewDescribeNetworkAclsPaginator(clientEc2, &ec2.DescribeNetworkAclsInput{
+		Filters: []types.Filter{{Name: aws.String("association.subnet-id"), Values: subnetIds}},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to describe the network ACLs of the subnets: %w", err)
+		}
+		for _, networkAcl := range output.NetworkAcls {
+			for _, egress := range []bool{false, true} {
+				rules := len(filter.scopedEntries(networkAcl.Entries, egress))
+				if rules <= quota {
+					continue
+				}
+				direction := "ingress"
+				if egress {
+					direction = "egress"
+				}
+				denyRules := 0
+				if (egress && filter.Egress) || (!egress && filter.Ingress) {
+					denyRules = len(filter.denyEntries())
+				}
+				return fmt.Errorf("the blackhole needs %d %s rules in network ACL %s (%d deny rules for the CIDRs, protocols and ports and %d copied entries), but a network ACL allows at most %d rules per direction. Use fewer CIDRs or ports", rules, direction, aws.ToString(networkAcl.NetworkAclId), denyRules, rules-denyRules, quota)
+			}
+		}
+	}
+	return nil
+}
+
+// hasIpv6CidrBlock reports whether any of the subnets has an associated IPv6 CIDR block.
+func hasIpv6CidrBlock(ctx context.Context, clientEc2 blackholeEC2Api, targetSubnets map[string][]string) (bool, error) {
+	subnetIds := make([]string, 0)
+	for _, ids := range targetSubnets {
+		subnetIds = append(subnetIds, ids...)
+	}
+	if len(subnetIds) == 0 {
+		return false, nil
+	}
+
+	paginator := ec2.NewDescribeSubnetsPaginator(clientEc2, &ec2.DescribeSubnetsInput{SubnetIds: subnetIds})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, subnet := range output.Subnets {
+			for _, association := range subnet.Ipv6CidrBlockAssociationSet {
+				if association.Ipv6CidrBlockState != nil && association.Ipv6CidrBlockState.State == types.SubnetCidrBlockStateCodeAssociated {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+func getBlackholeFilter(config map[string]any) (*BlackholeFilter, error) {
+	protocols, ok := blackholeProtocols[extutil.ToString(config["protocol"])]
+	if !ok {
+		return nil, fmt.Errorf("invalid protocol %q", extutil.ToString(config["protocol"]))
+	}
+
+	filter := &BlackholeFilter{Protocols: protocols, Cidrs: []string{"0.0.0.0/0"}}
+	switch direction := extutil.ToString(config["direction"]); direction {
+	case "", "both":
+		filter.Ingress, filter.Egress = true, true
+	case "ingress":
+		filter.Ingress = true
+	case "egress":
+		filter.Egress = true
+	default:
+		return nil, fmt.Errorf("invalid direction %q", direction)
+	}
+
+	for _, port := range extutil.ToStringArray(config["ports"]) {
+		portRange, err := parsePortRange(port)
+		if err != nil {
+			return nil, err
+		}
+		filter.PortRanges = append(filter.PortRanges, portRange)
+	}
+
+	if cidrs := extutil.ToStringArray(config["cidrs"]); len(cidrs) > 0 {
+		filter.Cidrs = make([]string, 0, len(cidrs))
+		for _, cidr := range cidrs {
+			_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+			}
+			filter.Cidrs = append(filter.Cidrs, ipNet.String())
+		}
+	}
+
+	// the copied entries are checked once the network ACLs are known
+	if quota := extConfig.Config().BlackholeNetworkAclRulesQuota; quota > 0 && len(filter.denyEntries()) > quota {
+		return nil, fmt.Errorf("the blackhole needs %d deny rules per direction for %d CIDRs, %d protocols and %d port ranges, but a network ACL allows at most %d rules per direction. Use fewer CIDRs or ports", len(filter.denyEntries()), len(filter.Cidrs), len(filter.Protocols), len(filter.PortRanges), quota)
+	}
+	return filter, nil
+}
+
+func parsePortRange(value string) (types.PortRange, error) {
+	fromValue, toValue, isRange := strings.Cut(strings.TrimSpace(value), "-")
+	if !isRange {
+		toValue = fromValue
+	}
+	from, fromErr := strconv.ParseInt(strings.TrimSpace(fromValue), 10, 32)
+	to, toErr := strconv.ParseInt(strings.TrimSpace(toValue), 10, 32)
+	if fromErr != nil || toErr != nil || from < 0 || to > 65535 || from > to {
+		return types.PortRange{}, fmt.Errorf("invalid port or port range %q", value)
+	}
+	return types.PortRange{From: aws.Int32(int32(from)), To: aws.Int32(int32(to))}, nil
+}
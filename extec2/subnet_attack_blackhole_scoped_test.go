@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extec2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBlackholeFilter(t *testing.T) {
+	filter, err := getBlackholeFilter(map[string]any{
+		"protocol":  "tcp-udp",
+		"ports":     []any{"5432", "8000-8100"},
+		"cidrs":     []any{"10.0.1.0/24", "2001:db8::/64"},
+		"direction": "egress",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &BlackholeFilter{
+		Protocols:  []string{"6", "17"},
+		PortRanges: []types.PortRange{{From: aws.Int32(5432), To: aws.Int32(5432)}, {From: aws.Int32(8000), To: aws.Int32(8100)}},
+		Cidrs:      []string{"10.0.1.0/24", "2001:db8::/64"},
+		Egress:     true,
+	}, filter)
+
+	filter, err = getBlackholeFilter(map[string]any{"protocol": "all", "direction": "both"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.0.0.0/0"}, filter.Cidrs)
+	assert.True(t, filter.Ingress && filter.Egress)
+
+	_, err = getBlackholeFilter(map[string]any{"protocol": "tcp", "ports": []any{"8100-8000"}})
+	assert.ErrorContains(t, err, "invalid port")
+	_, err = getBlackholeFilter(map[string]any{"protocol": "tcp", "cidrs": []any{"10.0.1.0"}})
+	assert.ErrorContains(t, err, "invalid CIDR")
+	_, err = getBlackholeFilter(map[string]any{"protocol": "icmp"})
+	assert.ErrorContains(t, err, "invalid protocol")
+}
+
+func TestGetBlackholeFilterRejectsTooManyDenyRules(t *testing.T) {
+	extConfig.Update(func(spec *extConfig.Specification) { spec.BlackholeNetworkAclRulesQuota = 20 })
+	defer extConfig.Update(func(spec *extConfig.Specification) { spec.BlackholeNetworkAclRulesQuota = 0 })
+
+	_, err := getBlackholeFilter(map[string]any{
+		"protocol": "tcp-udp",
+		"ports":    []any{"80", "443", "5432", "6379", "8080", "9000"},
+		"cidrs":    []any{"10.0.1.0/24", "10.0.2.0/24"},
+	})
+	assert.EqualError(t, err, "the blackhole needs 24 deny rules per direction for 2 CIDRs, 2 protocols and 6 port ranges, but a network ACL allows at most 20 rules per direction. Use fewer CIDRs or ports")
+
+	_, err = getBlackholeFilter(map[string]any{
+		"protocol": "tcp-udp",
+		"ports":    []any{"80", "443", "5432", "6379", "8080"},
+		"cidrs":    []any{"10.0.1.0/24", "10.0.2.0/24"},
+	})
+	assert.NoError(t, err)
+}
+
+func TestCheckNetworkAclRulesQuotaCountsCopiedEntries(t *testing.T) {
+	extConfig.Update(func(spec *extConfig.Specification) { spec.BlackholeNetworkAclRulesQuota = 20 })
+	defer extConfig.Update(func(spec *extConfig.Specification) { spec.BlackholeNetworkAclRulesQuota = 0 })
+	entries := []types.NetworkAclEntry{
+		{RuleNumber: aws.Int32(defaultNetworkAclRuleNumber), Egress: aws.Bool(false), Protocol: new("-1"), CidrBlock: new("0.0.0.0/0"), RuleAction: types.RuleActionDeny},
+	}
+	for i := int32(1); i <= 18; i++ {
+		entries = append(entries, types.NetworkAclEntry{RuleNumber: aws.Int32(i * 10), Egress: aws.Bool(false), Protocol: new("6"), CidrBlock: new("10.0.0.0/16"), RuleAction: types.RuleActionAllow})
+	}
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeNetworkAcls", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeNetworkAclsInput) bool {
+		return *params.Filters[0].Name == "association.subnet-id" && params.Filters[0].Values[0] == "subnet-1"
+	}), mock.Anything).Return(&ec2.DescribeNetworkAclsOutput{NetworkAcls: []types.NetworkAcl{{NetworkAclId: new("nacl-1"), Entries: entries}}}, nil)
+	filter := &BlackholeFilter{
+		Protocols:  []string{"6"},
+		PortRanges: []types.PortRange{{From: aws.Int32(80), To: aws.Int32(80)}, {From: aws.Int32(443), To: aws.Int32(443)}, {From: aws.Int32(5432), To: aws.Int32(5432)}},
+		Cidrs:      []string{"0.0.0.0/0"},
+		Ingress:    true,
+	}
+
+	err := checkNetworkAclRulesQuota(context.Background(), clientEc2, filter, map[string][]string{"vpc-1": {"subnet-1"}})
+	assert.EqualError(t, err, "the blackhole needs 21 ingress rules in network ACL nacl-1 (3 deny rules for the CIDRs, protocols and ports and 18 copied entries), but a network ACL allows at most 20 rules per direction. Use fewer CIDRs or ports")
+
+	filter.Ingress, filter.Egress = false, true
+	assert.NoError(t, checkNetworkAclRulesQuota(context.Background(), clientEc2, filter, map[string][]string{"vpc-1": {"subnet-1"}}))
+}
+
+func TestPrepareScopedBlackholeRejectsInvalidFilter(t *testing.T) {
+	action := subnetScopedBlackholeAction{}
+
+	_, err := action.Prepare(context.Background(), &BlackholeState{}, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"protocol": "icmp"},
+	})
+
+	var kitErr extension_kit.ExtensionError
+	require.ErrorAs(t, err, &kitErr)
+	assert.Equal(t, "Invalid configuration of the blackhole", kitErr.Title)
+}
+
+func TestHasIpv6CidrBlock(t *testing.T) {
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeSubnets", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeSubnetsInput) bool {
+		return params.SubnetIds[0] == "subnet-ipv4"
+	})).Return(&ec2.DescribeSubnetsOutput{Subnets: []types.Subnet{
+		{SubnetId: new("subnet-ipv4"), CidrBlock: new("10.0.1.0/24"), Ipv6CidrBlockAssociationSet: []types.SubnetIpv6CidrBlockAssociation{
+			{Ipv6CidrBlock: new("2001:db8::/64"), Ipv6CidrBlockState: &types.SubnetCidrBlockState{State: types.SubnetCidrBlockStateCodeDisassociated}},
+		}},
+	}}, nil)
+	clientEc2.On("DescribeSubnets", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeSubnetsInput) bool {
+		return params.SubnetIds[0] == "subnet-dual-stack"
+	})).Return(&ec2.DescribeSubnetsOutput{Subnets: []types.Subnet{
+		{SubnetId: new("subnet-dual-stack"), CidrBlock: new("10.0.2.0/24"), Ipv6CidrBlockAssociationSet: []types.SubnetIpv6CidrBlockAssociation{
+			{Ipv6CidrBlock: new("2001:db8:1::/64"), Ipv6CidrBlockState: &types.SubnetCidrBlockState{State: types.SubnetCidrBlockStateCodeAssociated}},
+		}},
+	}}, nil)
+
+	hasIpv6, err := hasIpv6CidrBlock(context.Background(), clientEc2, map[string][]string{"vpc-1": {"subnet-ipv4"}})
+	require.NoError(t, err)
+	assert.False(t, hasIpv6)
+
+	hasIpv6, err = hasIpv6CidrBlock(context.Background(), clientEc2, map[string][]string{"vpc-1": {"subnet-dual-stack"}})
+	require.NoError(t, err)
+	assert.True(t, hasIpv6)
+}
+
+func TestStartScopedBlackholeCopiesEntries(t *testing.T) {
+	// Given
+	executionId := uuid.New()
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeNetworkAcls", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeNetworkAclsInput) bool {
+		return len(params.Filters) > 0
+	}), mock.Anything).Return(new(ec2.DescribeNetworkAclsOutput{
+		NetworkAcls: []types.NetworkAcl{
+			{
+				Associations: []types.NetworkAclAssociation{
+					{NetworkAclAssociationId: new("association-1"), NetworkAclId: new("nacl-1"), SubnetId: new("subnet-1")},
+				},
+			},
+		},
+	}), nil)
+	clientEc2.On("DescribeNetworkAcls", mock.Anything, mock.MatchedBy(func(params *ec2.DescribeNetworkAclsInput) bool {
+		return len(params.NetworkAclIds) == 1 && params.NetworkAclIds[0] == "nacl-1"
+	}), mock.Anything).Return(new(ec2.DescribeNetworkAclsOutput{
+		NetworkAcls: []types.NetworkAcl{
+			{
+				NetworkAclId: new("nacl-1"),
+				Entries: []types.NetworkAclEntry{
+					{RuleNumber: aws.Int32(defaultNetworkAclRuleNumber), Egress: aws.Bool(true), Protocol: new("-1"), CidrBlock: new("0.0.0.0/0"), RuleAction: types.RuleActionDeny},
+					{RuleNumber: aws.Int32(200), Egress: aws.Bool(true), Protocol: new("-1"), CidrBlock: new("0.0.0.0/0"), RuleAction: types.RuleActionAllow},
+					{RuleNumber: aws.Int32(100), Egress: aws.Bool(true), Protocol: new("6"), CidrBlock: new("10.0.9.0/24"), RuleAction: types.RuleActionDeny},
+					{RuleNumber: aws.Int32(100), Egress: aws.Bool(false), Protocol: new("-1"), CidrBlock: new("0.0.0.0/0"), RuleAction: types.RuleActionAllow},
+				},
+			},
+		},
+	}), nil)
+	clientEc2.On("CreateNetworkAcl", mock.Anything, mock.Anything, mock.Anything).Return(new(ec2.CreateNetworkAclOutput{
+		NetworkAcl: &types.NetworkAcl{NetworkAclId: new("NEW nacl-2")},
+	}), nil)
+	clientEc2.On("CreateNetworkAclEntry", mock.Anything, mock.Anything, mock.Anything).Return(new(ec2.CreateNetworkAclEntryOutput{}), nil)
+	clientEc2.On("ReplaceNetworkAclAssociation", mock.Anything, mock.Anything, mock.Anything).Return(new(ec2.ReplaceNetworkAclAssociationOutput{
+		NewAssociationId: new("NEW association-2"),
+	}), nil)
+
+	action := subnetScopedBlackholeAction{subnetBlackholeAction{clientProvider: func(account string, region string, role *string) (blackholeEC2Api, blackholeImdsApi, error) {
+		return clientEc2, nil, nil
+	}}}
+	state := BlackholeState{
+		ExtensionAwsAccount: "43",
+		TargetRegion:        "eu-west-1",
+		TargetSubnets:       map[string][]string{"vpcId-1": {"subnet-1"}},
+		AttackExecutionId:   executionId,
+		Filter: &BlackholeFilter{
+			Protocols:  []string{"6"},
+			PortRanges: []types.PortRange{{From: aws.Int32(5432), To: aws.Int32(5432)}},
+			Cidrs:      []string{"10.0.1.0/24"},
+			Egress:     true,
+		},
+	}
+
+	// When
+	_, err := action.Start(context.Background(), &state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "nacl-1", state.OldNetworkAclIds["NEW association-2"])
+
+	var entries []*ec2.CreateNetworkAclEntryInput
+	for _, call := range clientEc2.Calls {
+		if call.Method == "CreateNetworkAclEntry" {
+			entries = append(entries, call.Arguments.Get(1).(*ec2.CreateNetworkAclEntryInput))
+		}
+	}
+	require.Len(t, entries, 4)
+	assert.Equal(t, &ec2.CreateNetworkAclEntryInput{
+		NetworkAclId: new("NEW nacl-2"),
+		RuleNumber:   aws.Int32(1),
+		Egress:       aws.Bool(true),
+		Protocol:     new("6"),
+		CidrBlock:    new("10.0.1.0/24"),
+		PortRange:    &types.PortRange{From: aws.Int32(5432), To: aws.Int32(5432)},
+		RuleAction:   types.RuleActionDeny,
+	}, entries[0])
+	assert.Equal(t, int32(2), *entries[1].RuleNumber)
+	assert.Equal(t, "10.0.9.0/24", *entries[1].CidrBlock, "copied entries keep their order")
+	assert.Equal(t, types.RuleActionAllow, entries[2].RuleAction)
+	assert.Equal(t, int32(3), *entries[2].RuleNumber)
+	assert.False(t, *entries[3].Egress)
+	assert.Equal(t, int32(1), *entries[3].RuleNumber, "no deny rules for ingress")
+}
+
+func TestCreateScopedNetworkAclEntriesCopiesIpv6AndIcmpEntries(t *testing.T) {
+	// Given
+	icmp := &types.IcmpTypeCode{Type: aws.Int32(8), Code: aws.Int32(-1)}
+	icmpv6 := &types.IcmpTypeCode{Type: aws.Int32(128), Code: aws.Int32(0)}
+	clientEc2 := new(clientEC2ApiMock)
+	clientEc2.On("DescribeNetworkAcls", mock.Anything, mock.Anything, mock.Anything).Return(&ec2.DescribeNetworkAclsOutput{NetworkAcls: []types.NetworkAcl{{
+		NetworkAclId: new("nacl-1"),
+		Entries: []types.NetworkAclEntry{
+			{RuleNumber: aws.Int32(defaultNetworkAclRuleNumber), Egress: aws.Bool(false), Protocol: new("-1"), Ipv6CidrBlock: new("::/0"), RuleAction: types.RuleActionDeny},
+			{RuleNumber: aws.Int32(300), Egress: aws.Bool(false), Protocol: new("-1"), Ipv6CidrBlock: new("::/0"), RuleAction: types.RuleActionAllow},
+			{RuleNumber: aws.Int32(200), Egress: aws.Bool(false), Protocol: new("58"), Ipv6CidrBlock: new("2001:db8::/64"), IcmpTypeCode: icmpv6, RuleAction: types.RuleActionAllow},
+			{RuleNumber: aws.Int32(100), Egress: aws.Bool(false), Protocol: new("1"), CidrBlock: new("10.0.0.0/16"), IcmpTypeCode: icmp, RuleAction: types.RuleActionAllow},
+		},
+	}}}, nil)
+	clientEc2.On("CreateNetworkAclEntry", mock.Anything, mock.Anything, mock.Anything).Return(new(ec2.CreateNetworkAclEntryOutput{}), nil)
+	filter := &BlackholeFilter{Protocols: []string{"6"}, Cidrs: []string{"0.0.0.0/0", "::/0"}, Ingress: true}
+
+	// When
+	err := createScopedNetworkAclEntries(context.Background(), clientEc2, filter, "NEW nacl-2", "nacl-1")
+
+	// Then
+	require.NoError(t, err)
+	var entries []*ec2.CreateNetworkAclEntryInput
+	for _, call := range clientEc2.Calls {
+		if call.Method == "CreateNetworkAclEntry" {
+			entries = append(entries, call.Arguments.Get(1).(*ec2.CreateNetworkAclEntryInput))
+		}
+	}
+	require.Len(t, entries, 5)
+	assert.Equal(t, new("0.0.0.0/0"), entries[0].CidrBlock)
+	assert.Equal(t, new("::/0"), entries[1].Ipv6CidrBlock)
+	assert.Equal(t, types.RuleActionDeny, entries[1].RuleAction)
+	assert.Equal(t, &ec2.CreateNetworkAclEntryInput{
+		NetworkAclId: new("NEW nacl-2"),
+		RuleNumber:   aws.Int32(3),
+		Egress:       aws.Bool(false),
+		Protocol:     new("1"),
+		CidrBlock:    new("10.0.0.0/16"),
+		IcmpTypeCode: icmp,
+		RuleAction:   types.RuleActionAllow,
+	}, entries[2])
+	assert.Equal(t, &ec2.CreateNetworkAclEntryInput{
+		NetworkAclId:  new("NEW nacl-2"),
+		RuleNumber:    aws.Int32(4),
+		Egress:        aws.Bool(false),
+		Protocol:      new("58"),
+		Ipv6CidrBlock: new("2001:db8::/64"),
+		IcmpTypeCode:  icmpv6,
+		RuleAction:    types.RuleActionAllow,
+	}, entries[3])
+	assert.Equal(t, new("::/0"), entries[4].Ipv6CidrBlock)
+	assert.Equal(t, int32(5), *entries[4].RuleNumber)
+}
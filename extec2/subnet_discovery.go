@@ -13,13 +13,12 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
 	"github.com/steadybit/extension-kit/extbuild"
+	"slices"
 	"strings"
-	"time"
 )
 
 type subnetDiscovery struct{}
@@ -29,11 +28,14 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*subnetDiscovery)(nil)
 )
 
+// SubnetDiscoveryPermissions lists the IAM actions called by the discovery.
+var SubnetDiscoveryPermissions = []string{"ec2:DescribeSubnets"}
+
 func NewSubnetDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &subnetDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalSubnet)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalSubnet }),
 	)
 }
 
@@ -41,7 +43,7 @@ func (e *subnetDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: subnetTargetType,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalSubnet)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalSubnet)),
 		},
 	}
 }
@@ -126,6 +128,10 @@ func GetAllSubnets(ctx context.Context, ec2Api ec2.DescribeSubnetsAPIClient, ec2
 	if len(account.TagFilters) > 0 {
 		input.Filters = make([]types.Filter, 0, len(account.TagFilters))
 		for _, tagFilter := range account.TagFilters {
+			// only plain filters can be passed to the API, all others are applied after the describe call
+			if !tagFilter.IsExactMatch() {
+				continue
+			}
 			input.Filters = append(input.Filters, types.Filter{
 				Name:   new("tag:" + tagFilter.Key),
 				Values: tagFilter.Values,
@@ -133,6 +139,7 @@ func GetAllSubnets(ctx context.Context, ec2Api ec2.DescribeSubnetsAPIClient, ec2
 		}
 	}
 
+	filterAfterDescribe := slices.ContainsFunc(account.TagFilters, func(filter config.TagFilter) bool { return !filter.IsExactMatch() })
 	paginator := ec2.NewDescribeSubnetsPaginator(ec2Api, &input)
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
@@ -140,11 +147,14 @@ func GetAllSubnets(ctx context.Context, ec2Api ec2.DescribeSubnetsAPIClient, ec2
 			return result, err
 		}
 		for _, subnet := range output.Subnets {
+			if filterAfterDescribe && !matchesEc2TagFilter(subnet.Tags, account.TagFilters) {
+				continue
+			}
 			result = append(result, toSubnetTarget(subnet, ec2Util, account.AccountNumber, account.Region, account.AssumeRole))
 		}
 	}
 
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesSubnet), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesSubnet, config.Config().DiscoveryAttributesIncludesSubnet), nil
 }
 
 func toSubnetTarget(subnet types.Subnet, ec2Util instanceDiscoveryEc2Util, awsAccountNumber string, awsRegion string, role *string) discovery_kit_api.Target {
@@ -4,8 +4,10 @@
 package extecs
 
 import (
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-aws/v2/utils"
 )
 
 const (
@@ -21,30 +23,11 @@ func matchesTagFilter(tags []types.Tag, filters []config.TagFilter) bool {
 	if len(filters) == 0 {
 		return true
 	}
-
-	for _, filter := range filters {
-		matched := false
-
-		for _, tag := range tags {
-			if tag.Key != nil && *tag.Key == filter.Key {
-				// Check if at least one value matches
-				for _, filterValue := range filter.Values {
-					if tag.Value != nil && *tag.Value == filterValue {
-						matched = true
-						break
-					}
-				}
-			}
-			if matched {
-				break
-			}
-		}
-
-		// If a filter didn't match any tags, return false
-		if !matched {
-			return false
+	tagMap := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if tag.Key != nil {
+			tagMap[*tag.Key] = aws.ToString(tag.Value)
 		}
 	}
-
-	return true
+	return utils.MatchesTags(tagMap, filters)
 }
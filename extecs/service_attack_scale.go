@@ -88,6 +88,15 @@ func (e *ecsServiceScaleAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (e *ecsServiceScaleAction) RequiredPermissions() []string {
+	return []string{"ecs:DescribeServices", "ecs:UpdateService"}
+}
+
+// ResourceArns leases the service, as stop restores the desired count recorded during prepare.
+func (e *ecsServiceScaleAction) ResourceArns(_ context.Context, target *action_kit_api.Target) ([]string, error) {
+	return extutil.MustHaveValue(target.Attributes, "aws-ecs.service.arn"), nil
+}
+
 func (e *ecsServiceScaleAction) Prepare(ctx context.Context, state *ServiceScaleState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
@@ -13,14 +13,12 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
 	"github.com/steadybit/extension-kit/extbuild"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // pageSize is restricted by AWS ECS API.
@@ -39,11 +37,14 @@ type ecsServiceDiscoveryApi interface {
 	ecs.DescribeServicesAPIClient
 }
 
+// EcsServiceDiscoveryPermissions lists the IAM actions called by the discovery.
+var EcsServiceDiscoveryPermissions = []string{"ecs:ListClusters", "ecs:ListServices", "ecs:DescribeServices"}
+
 func NewEcsServiceDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &ecsServiceDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalEcsService)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalEcsService }),
 	)
 }
 
@@ -51,7 +52,7 @@ func (e *ecsServiceDiscovery) Describe() discovery_kit_api.DiscoveryDescription
 	return discovery_kit_api.DiscoveryDescription{
 		Id: ecsServiceTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalEcsService)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalEcsService)),
 		},
 	}
 }
@@ -135,7 +136,7 @@ func GetAllEcsServices(account *utils.AwsAccess, ecsServiceApi ecsServiceDiscove
 		result = append(result, targets...)
 	}
 
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesEcs), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesEcs, config.Config().DiscoveryAttributesIncludesEcs), nil
 }
 
 func getAllServicesInCluster(clusterArn string, account *utils.AwsAccess, ecsServiceApi ecsServiceDiscoveryApi, ctx context.Context) ([]discovery_kit_api.Target, error) {
@@ -173,7 +174,7 @@ func getAllServicesInCluster(clusterArn string, account *utils.AwsAccess, ecsSer
 }
 
 func ignoreService(service types.Service) bool {
-	if config.Config.DisableDiscoveryExcludes {
+	if config.Config().DisableDiscoveryExcludes {
 		return false
 	}
 	for _, tag := range service.Tags {
@@ -94,6 +94,10 @@ func (f EcsServiceEventLogAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (f EcsServiceEventLogAction) RequiredPermissions() []string {
+	return []string{"ecs:DescribeServices"}
+}
+
 func (f EcsServiceEventLogAction) Prepare(_ context.Context, state *EcsServiceEventLogState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	awsAccount := extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	region := extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
@@ -136,6 +136,10 @@ func (f EcsServiceTaskCountCheckAction) Describe() action_kit_api.ActionDescript
 	}
 }
 
+func (f EcsServiceTaskCountCheckAction) RequiredPermissions() []string {
+	return []string{"ecs:DescribeServices"}
+}
+
 func (f EcsServiceTaskCountCheckAction) Prepare(_ context.Context, state *EcsServiceTaskCountCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	var config EcsServiceTaskCountCheckConfig
 	if err := extconversion.Convert(request.Config, &config); err != nil {
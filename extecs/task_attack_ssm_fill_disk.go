@@ -8,58 +8,15 @@
 package extecs
 
 import (
-	"fmt"
-	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
-	"github.com/steadybit/extension-kit/extutil"
-	"strconv"
-	"time"
+	"github.com/steadybit/extension-aws/v2/extssm"
 )
 
-func NewEcsTaskFillDiskAction() action_kit_sdk.Action[TaskSsmActionState] {
-	return newEcsTaskSsmAction(getEcsTaskFillDiskDescription, ssmCommandInvocation{
-		documentName:     "AWSFIS-Run-Disk-Fill",
-		documentVersion:  "$DEFAULT",
-		stepNameToOutput: "ExecuteDiskFill",
-		getParameters:    getEcsTaskFillDiskParameters,
+func NewEcsTaskFillDiskAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEcsTaskSsmAction(extssm.FillDiskDescription(ecsTaskTargetId), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-Disk-Fill",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "ExecuteDiskFill",
+		GetParameters:    extssm.FillDiskParameters,
 	})
 }
-
-func getEcsTaskFillDiskParameters(request action_kit_api.PrepareActionRequestBody) (map[string][]string, error) {
-	duration := time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond
-
-	return map[string][]string{
-		"DurationSeconds": {fmt.Sprintf("%d", int64(duration.Seconds()))},
-		"Percent":         {strconv.Itoa(extutil.ToInt(request.Config["percent"]))},
-	}, nil
-}
-
-func getEcsTaskFillDiskDescription() action_kit_api.ActionDescription {
-	return action_kit_api.ActionDescription{
-		Id:          fmt.Sprintf("%s.fill_disk", ecsTaskTargetId),
-		Label:       "Fill Disk",
-		Description: "Fill ephemeral storage for the given duration.",
-		Parameters: []action_kit_api.ActionParameter{
-			{
-				Name:         "percent",
-				Label:        "Fill Percentage",
-				Description:  new("How many the percent of the allocated disk space dependent on the total available size shall be filled?"),
-				Type:         action_kit_api.ActionParameterTypePercentage,
-				DefaultValue: new("100"),
-				Required:     new(true),
-				Order:        new(0),
-				MinValue:     new(1),
-				MaxValue:     new(100),
-			},
-			{
-				Name:         "duration",
-				Label:        "Duration",
-				Description:  new("How long should the disk be filled?"),
-				Type:         action_kit_api.ActionParameterTypeDuration,
-				DefaultValue: new("30s"),
-				Required:     new(true),
-				Order:        new(2),
-			},
-		},
-	}
-}
@@ -8,72 +8,15 @@
 package extecs
 
 import (
-	"fmt"
-	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
-	"github.com/steadybit/extension-kit/extutil"
-	"strconv"
-	"time"
+	"github.com/steadybit/extension-aws/v2/extssm"
 )
 
-func NewEcsTaskStressMemoryAction() action_kit_sdk.Action[TaskSsmActionState] {
-	return newEcsTaskSsmAction(getEcsTaskStressMemoryDescription, ssmCommandInvocation{
-		documentName:     "AWSFIS-Run-Memory-Stress",
-		documentVersion:  "$DEFAULT",
-		stepNameToOutput: "ExecuteStressNg",
-		getParameters:    getEcsTaskStressMemoryParameters,
+func NewEcsTaskStressMemoryAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEcsTaskSsmAction(extssm.StressMemoryDescription(ecsTaskTargetId), extssm.CommandInvocation{
+		DocumentName:     "AWSFIS-Run-Memory-Stress",
+		DocumentVersion:  "$DEFAULT",
+		StepNameToOutput: "ExecuteStressNg",
+		GetParameters:    extssm.StressMemoryParameters,
 	})
 }
-
-func getEcsTaskStressMemoryParameters(request action_kit_api.PrepareActionRequestBody) (map[string][]string, error) {
-	duration := time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond
-
-	return map[string][]string{
-		"DurationSeconds": {fmt.Sprintf("%d", int64(duration.Seconds()))},
-		"Workers":         {strconv.Itoa(extutil.ToInt(request.Config["workers"]))},
-		"Percent":         {strconv.Itoa(extutil.ToInt(request.Config["percent"]))},
-	}, nil
-}
-
-func getEcsTaskStressMemoryDescription() action_kit_api.ActionDescription {
-	return action_kit_api.ActionDescription{
-		Id:          fmt.Sprintf("%s.stress_mem", ecsTaskTargetId),
-		Label:       "Stress Memory",
-		Description: "Stresses Memory for the given duration.",
-		Hint: &action_kit_api.ActionHint{
-			Type:    action_kit_api.HintInfo,
-			Content: "This action targets the entire task. Not individual containers inside it.",
-		},
-		Parameters: []action_kit_api.ActionParameter{
-			{
-				Name:         "percent",
-				Label:        "Memory Percentage",
-				Description:  new("How many the percent of the available virtual memory shall be used?"),
-				Type:         action_kit_api.ActionParameterTypePercentage,
-				DefaultValue: new("100"),
-				Required:     new(true),
-				Order:        new(0),
-				MinValue:     new(1),
-				MaxValue:     new(100),
-			},
-			{
-				Name:         "workers",
-				Label:        "Workers",
-				Description:  new("How many workers should stress the memory?"),
-				Type:         action_kit_api.ActionParameterTypeStressngWorkers,
-				DefaultValue: new("1"),
-				Required:     new(true),
-				Order:        new(1),
-			},
-			{
-				Name:         "duration",
-				Label:        "Duration",
-				Description:  new("How long should the memory be stressed?"),
-				Type:         action_kit_api.ActionParameterTypeDuration,
-				DefaultValue: new("30s"),
-				Required:     new(true),
-				Order:        new(2),
-			},
-		},
-	}
-}
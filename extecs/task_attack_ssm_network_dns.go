@@ -7,19 +7,20 @@ import (
 	"fmt"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-aws/v2/extssm"
 	"github.com/steadybit/extension-kit/extutil"
 	"strconv"
 	"time"
 )
 
-func NewEcsTaskNetworkDnsAction() action_kit_sdk.Action[TaskSsmActionState] {
-	var heartbeatParameters = updateFisActionStateParameter
-	return newEcsTaskSsmAction(getEcsTaskNetworkDnsDescription, ssmCommandInvocation{
-		documentName:              "AWSFIS-Run-Network-Blackhole-Port-ECS",
-		documentVersion:           "$DEFAULT",
-		stepNameToOutput:          "FaultInjection",
-		getParameters:             getEcsTaskNetworkDnsParameters,
-		updateHeartbeatParameters: &heartbeatParameters,
+func NewEcsTaskNetworkDnsAction() action_kit_sdk.Action[extssm.ActionState] {
+	var heartbeatParameters = extssm.UpdateFisActionStateParameter
+	return newEcsTaskSsmAction(getEcsTaskNetworkDnsDescription(), extssm.CommandInvocation{
+		DocumentName:              "AWSFIS-Run-Network-Blackhole-Port-ECS",
+		DocumentVersion:           "$DEFAULT",
+		StepNameToOutput:          "FaultInjection",
+		GetParameters:             getEcsTaskNetworkDnsParameters,
+		UpdateHeartbeatParameters: &heartbeatParameters,
 	})
 }
 
@@ -58,13 +59,13 @@ func getEcsTaskNetworkDnsParameters(request action_kit_api.PrepareActionRequestB
 	if duration.Seconds() > 43200 {
 		return nil, fmt.Errorf("duration longer than 43200 seconds is not supported")
 	}
-	fisActionStateParameter := newFisActionStateParameter(request.ExecutionId.String())
+	fisActionStateParameter := extssm.NewFisActionStateParameter(request.ExecutionId.String())
 	return map[string][]string{
-		"DurationSeconds":           {fmt.Sprintf("%d", int64(duration.Seconds()))},
-		"Protocol":                  {"udp"},
-		"Port":                      {strconv.Itoa(extutil.ToInt(request.Config["dnsPort"]))},
-		"TrafficType":               {"egress"},
-		"InstallDependencies":       {"True"},
-		fisActionStateParameterName: {fisActionStateParameter},
+		"DurationSeconds":                  {fmt.Sprintf("%d", int64(duration.Seconds()))},
+		"Protocol":                         {"udp"},
+		"Port":                             {strconv.Itoa(extutil.ToInt(request.Config["dnsPort"]))},
+		"TrafficType":                      {"egress"},
+		"InstallDependencies":              {"True"},
+		extssm.FisActionStateParameterName: {fisActionStateParameter},
 	}, nil
 }
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-aws/v2/extssm"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -38,8 +39,12 @@ func Test_getEcsTaskNetworkDnsParameters(t *testing.T) {
 	assert.Equal(t, "egress", params["TrafficType"][0])
 	assert.Equal(t, "True", params["InstallDependencies"][0])
 
-	var fisActionState fisActionState
-	err = json.Unmarshal([]byte(params[fisActionStateParameterName][0]), &fisActionState)
+	var fisActionState struct {
+		Id        string `json:"id"`
+		CallTime  int64  `json:"callTime"`
+		CallCount int    `json:"callCount"`
+	}
+	err = json.Unmarshal([]byte(params[extssm.FisActionStateParameterName][0]), &fisActionState)
 	assert.NoError(t, err)
 
 	assert.Equal(t, id.String(), fisActionState.Id)
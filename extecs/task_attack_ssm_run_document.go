@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extecs
+
+import (
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-aws/v2/extssm"
+)
+
+func NewEcsTaskRunSsmDocumentAction() action_kit_sdk.Action[extssm.ActionState] {
+	return newEcsTaskSsmAction(extssm.RunSsmDocumentDescription(ecsTaskTargetId), extssm.CommandInvocation{
+		GetParameters:  extssm.RunSsmDocumentParameters,
+		GetDocumentRun: extssm.RunSsmDocumentRun,
+	})
+}
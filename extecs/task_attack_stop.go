@@ -69,6 +69,10 @@ func (e *ecsTaskStopAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (e *ecsTaskStopAction) RequiredPermissions() []string {
+	return []string{"ecs:StopTask"}
+}
+
 func (e *ecsTaskStopAction) Prepare(_ context.Context, state *TaskStopState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
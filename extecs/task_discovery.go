@@ -17,14 +17,12 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/extec2"
 	"github.com/steadybit/extension-aws/v2/utils"
 	"github.com/steadybit/extension-kit/extbuild"
 	"strings"
-	"time"
 )
 
 // pageSize is restricted by AWS ECS API.
@@ -37,11 +35,14 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*ecsTaskDiscovery)(nil)
 )
 
+// EcsTaskDiscoveryPermissions lists the IAM actions called by the discovery.
+var EcsTaskDiscoveryPermissions = []string{"ecs:ListClusters", "ecs:ListTasks", "ecs:DescribeTasks"}
+
 func NewEcsTaskDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &ecsTaskDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalEcsTask)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalEcsTask }),
 	)
 }
 
@@ -49,7 +50,7 @@ func (e *ecsTaskDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: ecsTaskTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalEcsTask)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalEcsTask)),
 		},
 	}
 }
@@ -172,11 +173,11 @@ func GetAllEcsTasks(ctx context.Context, ecsApi EcsTasksApi, ec2Util taskDiscove
 		}
 	}
 
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesEcs), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesEcs, config.Config().DiscoveryAttributesIncludesEcs), nil
 }
 
 func ignoreTask(service types.Task) bool {
-	if config.Config.DisableDiscoveryExcludes {
+	if config.Config().DisableDiscoveryExcludes {
 		return false
 	}
 	for _, tag := range service.Tags {
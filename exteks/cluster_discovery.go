@@ -7,11 +7,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"slices"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -68,11 +66,14 @@ var eksEnrichmentAttributes = []discovery_kit_api.Attribute{
 	{Matcher: discovery_kit_api.Equals, Name: "aws.eks.cluster.deletion-protection"},
 }
 
+// EksClusterDiscoveryPermissions lists the IAM actions called by the discovery.
+var EksClusterDiscoveryPermissions = []string{"eks:ListClusters", "eks:DescribeCluster"}
+
 func NewEksClusterDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &eksClusterDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalEks)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalEks }),
 	)
 }
 
@@ -80,7 +81,7 @@ func (d *eksClusterDiscovery) Describe() discovery_kit_api.DiscoveryDescription
 	return discovery_kit_api.DiscoveryDescription{
 		Id: clusterTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalEks)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalEks)),
 		},
 	}
 }
@@ -161,31 +162,13 @@ func getAllEksClusters(ctx context.Context, client EksApi, account *utils.AwsAcc
 			if described.Cluster == nil {
 				continue
 			}
-			if !matchesClusterTagFilter(described.Cluster.Tags, account.TagFilters) {
+			if !utils.MatchesTags(described.Cluster.Tags, account.TagFilters) {
 				continue
 			}
 			result = append(result, toClusterTarget(*described.Cluster, account.AccountNumber, account.Region, account.AssumeRole))
 		}
 	}
-	return result, nil
-}
-
-func matchesClusterTagFilter(tags map[string]string, filters []config.TagFilter) bool {
-	if len(filters) == 0 {
-		return true
-	}
-	for _, filter := range filters {
-		matched := false
-		if value, ok := tags[filter.Key]; ok {
-			if slices.Contains(filter.Values, value) {
-				matched = true
-			}
-		}
-		if !matched {
-			return false
-		}
-	}
-	return true
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesEks, config.Config().DiscoveryAttributesIncludesEks), nil
 }
 
 func toClusterTarget(cluster types.Cluster, account string, region string, role *string) discovery_kit_api.Target {
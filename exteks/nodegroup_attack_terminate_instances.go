@@ -94,6 +94,10 @@ func (a *eksNodegroupTerminateInstancesAttack) Describe() action_kit_api.ActionD
 	}
 }
 
+func (a *eksNodegroupTerminateInstancesAttack) RequiredPermissions() []string {
+	return []string{"eks:DescribeNodegroup", "autoscaling:DescribeAutoScalingGroups", "ec2:TerminateInstances"}
+}
+
 func (a *eksNodegroupTerminateInstancesAttack) Prepare(ctx context.Context, state *EksNodegroupTerminateInstancesAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
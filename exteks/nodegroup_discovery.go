@@ -10,7 +10,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -32,11 +31,14 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*eksNodegroupDiscovery)(nil)
 )
 
+// EksNodegroupDiscoveryPermissions lists the IAM actions called by the discovery.
+var EksNodegroupDiscoveryPermissions = []string{"eks:ListClusters", "eks:ListNodegroups", "eks:DescribeNodegroup"}
+
 func NewEksNodegroupDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &eksNodegroupDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalEks)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalEks }),
 	)
 }
 
@@ -44,7 +46,7 @@ func (d *eksNodegroupDiscovery) Describe() discovery_kit_api.DiscoveryDescriptio
 	return discovery_kit_api.DiscoveryDescription{
 		Id: nodegroupTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalEks)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalEks)),
 		},
 	}
 }
@@ -141,7 +143,7 @@ func getAllEksNodegroups(ctx context.Context, client EksApi, account *utils.AwsA
 					if described.Nodegroup == nil {
 						continue
 					}
-					if !matchesClusterTagFilter(described.Nodegroup.Tags, account.TagFilters) {
+					if !utils.MatchesTags(described.Nodegroup.Tags, account.TagFilters) {
 						continue
 					}
 					result = append(result, toNodegroupTarget(*described.Nodegroup, account.AccountNumber, account.Region, account.AssumeRole))
@@ -149,7 +151,7 @@ func getAllEksNodegroups(ctx context.Context, client EksApi, account *utils.AwsA
 			}
 		}
 	}
-	return result, nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesEks, config.Config().DiscoveryAttributesIncludesEks), nil
 }
 
 func toNodegroupTarget(ng types.Nodegroup, account string, region string, role *string) discovery_kit_api.Target {
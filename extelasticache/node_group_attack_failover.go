@@ -54,6 +54,10 @@ func (f elasticacheNodeGroupFailoverAttack) Describe() action_kit_api.ActionDesc
 	}
 }
 
+func (f elasticacheNodeGroupFailoverAttack) RequiredPermissions() []string {
+	return []string{"elasticache:TestFailover"}
+}
+
 func (f elasticacheNodeGroupFailoverAttack) Prepare(_ context.Context, state *ElasticacheClusterAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -33,11 +32,14 @@ var (
 	missingPermissionTagsAlreadyLogged                                      = false
 )
 
+// ElasticacheReplicationGroupDiscoveryPermissions lists the IAM actions called by the discovery.
+var ElasticacheReplicationGroupDiscoveryPermissions = []string{"elasticache:DescribeReplicationGroups", "tag:GetResources"}
+
 func NewElasticacheReplicationGroupDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &elasticacheReplicationGroupDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalElasticacheReplicationGroup)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalElasticacheReplicationGroup }),
 	)
 }
 
@@ -45,7 +47,7 @@ func (r *elasticacheReplicationGroupDiscovery) Describe() discovery_kit_api.Disc
 	return discovery_kit_api.DiscoveryDescription{
 		Id: elasticacheNodeGroupTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalElasticacheReplicationGroup)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalElasticacheReplicationGroup)),
 		},
 	}
 }
@@ -181,7 +183,7 @@ func getAllElasticacheReplicationGroups(ctx context.Context, elasticacheApi Elas
 		}
 	}
 
-	return result, nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesElasticache, config.Config().DiscoveryAttributesIncludesElasticache), nil
 }
 
 func getTags(ctx context.Context, output *elasticache.DescribeReplicationGroupsOutput, tagsClient resourcegroupstaggingapi.GetResourcesAPIClient, tagsRequired bool) ([]tagTypes.ResourceTagMapping, error) {
@@ -207,6 +207,19 @@ func (e *albStaticResponseAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (e *albStaticResponseAction) RequiredPermissions() []string {
+	return []string{
+		"elasticloadbalancing:DescribeListeners",
+		"elasticloadbalancing:DescribeRules",
+		"elasticloadbalancing:CreateRule",
+		"elasticloadbalancing:DeleteRule",
+		"elasticloadbalancing:SetRulePriorities",
+		"elasticloadbalancing:DescribeTags",
+		"elasticloadbalancing:AddTags",
+		"elasticloadbalancing:RemoveTags",
+	}
+}
+
 func (e *albStaticResponseAction) Prepare(ctx context.Context, state *AlbStaticResponseState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
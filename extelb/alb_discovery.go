@@ -15,9 +15,9 @@ import (
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/extec2"
@@ -25,11 +25,8 @@ import (
 	extension_kit "github.com/steadybit/extension-kit"
 	"github.com/steadybit/extension-kit/extbuild"
 	"strings"
-	"time"
 )
 
-const describeTagsMaxPagesize = 20
-
 type albDiscovery struct {
 }
 
@@ -38,11 +35,14 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*albDiscovery)(nil)
 )
 
+// AlbDiscoveryPermissions lists the IAM actions called by the discovery.
+var AlbDiscoveryPermissions = []string{"elasticloadbalancing:DescribeLoadBalancers", "elasticloadbalancing:DescribeListeners", "tag:GetResources"}
+
 func NewAlbDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &albDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalElbAlb)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalElbAlb }),
 	)
 }
 
@@ -50,7 +50,7 @@ func (e *albDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: albTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalElbAlb)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalElbAlb)),
 		},
 	}
 }
@@ -99,12 +99,13 @@ func (e *albDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescrip
 }
 
 func (e *albDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
-	return utils.ForEveryConfiguredAwsAccess(getTargetsForAccount, ctx, "ecs-task")
+	return utils.ForEveryConfiguredAwsAccess(getTargetsForAccount, ctx, "alb")
 }
 
 func getTargetsForAccount(account *utils.AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client := elasticloadbalancingv2.NewFromConfig(account.AwsConfig)
-	result, err := GetAlbs(ctx, client, extec2.Util, account)
+	tagsClient := resourcegroupstaggingapi.NewFromConfig(account.AwsConfig)
+	result, err := GetAlbs(ctx, client, tagsClient, extec2.Util, account)
 	if err != nil {
 		var re *awshttp.ResponseError
 		if errors.As(err, &re) && re.HTTPStatusCode() == 403 {
@@ -119,7 +120,7 @@ func getTargetsForAccount(account *utils.AwsAccess, ctx context.Context) ([]disc
 type AlbDiscoveryApi interface {
 	elasticloadbalancingv2.DescribeLoadBalancersAPIClient
 	elasticloadbalancingv2.DescribeListenersAPIClient
-	DescribeTags(ctx context.Context, params *elasticloadbalancingv2.DescribeTagsInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeTagsOutput, error)
+	describeTagsApi
 }
 
 type albDiscoveryEc2Util interface {
@@ -127,9 +128,10 @@ type albDiscoveryEc2Util interface {
 	extec2.GetVpcNameUtil
 }
 
-func GetAlbs(ctx context.Context, albDiscoveryApi AlbDiscoveryApi, ec2Util albDiscoveryEc2Util, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
+func GetAlbs(ctx context.Context, albDiscoveryApi AlbDiscoveryApi, tagsClient resourcegroupstaggingapi.GetResourcesAPIClient, ec2Util albDiscoveryEc2Util, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
 	result := make([]discovery_kit_api.Target, 0, 20)
 
+	var resourceTags *utils.ResourceTags
 	paginator := elasticloadbalancingv2.NewDescribeLoadBalancersPaginator(albDiscoveryApi, &elasticloadbalancingv2.DescribeLoadBalancersInput{})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
@@ -137,45 +139,40 @@ func GetAlbs(ctx context.Context, albDiscoveryApi AlbDiscoveryApi, ec2Util albDi
 			return nil, extension_kit.ToError("Failed to fetch load balancers.", err)
 		}
 
-		lbPages := utils.SplitIntoPages(output.LoadBalancers, describeTagsMaxPagesize)
-		for _, lbPage := range lbPages {
-			lbArns := make([]string, 0, len(lbPage))
-			for _, loadBalancer := range lbPage {
-				lbArns = append(lbArns, *loadBalancer.LoadBalancerArn)
+		for _, loadBalancer := range output.LoadBalancers {
+			if loadBalancer.Type != types.LoadBalancerTypeEnumApplication {
+				continue
 			}
-			describeTagsResult, err := albDiscoveryApi.DescribeTags(ctx, &elasticloadbalancingv2.DescribeTagsInput{
-				ResourceArns: lbArns,
+
+			if resourceTags == nil {
+				fetched, err := utils.GetResourceTags(ctx, tagsClient, account, loadBalancerResourceType)
+				if err != nil {
+					return nil, extension_kit.ToError("Failed to fetch tags.", err)
+				}
+				resourceTags = &fetched
+			}
+			tagMap, err := resourceTags.Get(aws.ToString(loadBalancer.LoadBalancerArn), func() (map[string]string, error) {
+				return fetchLoadBalancerTags(ctx, albDiscoveryApi, aws.ToString(loadBalancer.LoadBalancerArn))
 			})
 			if err != nil {
 				return nil, extension_kit.ToError("Failed to fetch tags.", err)
 			}
+			tags := toElbTags(tagMap)
+			if !matchesTagFilter(tags, account.TagFilters) {
+				continue
+			}
 
-			for _, loadBalancer := range lbPage {
-				if loadBalancer.Type != types.LoadBalancerTypeEnumApplication {
-					continue
-				}
-
-				var tags []types.Tag
-				for _, tagDescription := range describeTagsResult.TagDescriptions {
-					if *tagDescription.ResourceArn == *loadBalancer.LoadBalancerArn {
-						tags = tagDescription.Tags
-					}
-				}
-
-				describeListenersResult, err := albDiscoveryApi.DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
-					LoadBalancerArn: loadBalancer.LoadBalancerArn,
-				})
-				if err != nil {
-					return nil, extension_kit.ToError("Failed to fetch load balancer listeners.", err)
-				}
-
-				if matchesTagFilter(tags, account.TagFilters) {
-					result = append(result, toTarget(&loadBalancer, tags, describeListenersResult.Listeners, ec2Util, account.AccountNumber, account.Region, account.AssumeRole))
-				}
+			describeListenersResult, err := albDiscoveryApi.DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
+				LoadBalancerArn: loadBalancer.LoadBalancerArn,
+			})
+			if err != nil {
+				return nil, extension_kit.ToError("Failed to fetch load balancer listeners.", err)
 			}
+
+			result = append(result, toTarget(&loadBalancer, tags, describeListenersResult.Listeners, ec2Util, account.AccountNumber, account.Region, account.AssumeRole))
 		}
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesEcs), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesElb, config.Config().DiscoveryAttributesIncludesElb), nil
 }
 
 func toTarget(lb *types.LoadBalancer, tags []types.Tag, listeners []types.Listener, ec2Util albDiscoveryEc2Util, awsAccountNumber string, awsRegion string, role *string) discovery_kit_api.Target {
@@ -8,6 +8,8 @@ import (
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	tagtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
 	extConfig "github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
 	"github.com/stretchr/testify/assert"
@@ -28,12 +30,16 @@ func (m *albDiscoveryApiMock) DescribeLoadBalancers(ctx context.Context, params
 	return args.Get(0).(*elasticloadbalancingv2.DescribeLoadBalancersOutput), args.Error(1)
 }
 
-func (m *albDiscoveryApiMock) DescribeTags(ctx context.Context, params *elasticloadbalancingv2.DescribeTagsInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeTagsOutput, error) {
+type tagClientMock struct {
+	mock.Mock
+}
+
+func (m *tagClientMock) GetResources(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
 	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*elasticloadbalancingv2.DescribeTagsOutput), args.Error(1)
+	return args.Get(0).(*resourcegroupstaggingapi.GetResourcesOutput), args.Error(1)
 }
 
 func (m *albDiscoveryApiMock) DescribeListeners(ctx context.Context, params *elasticloadbalancingv2.DescribeListenersInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeListenersOutput, error) {
@@ -44,6 +50,14 @@ func (m *albDiscoveryApiMock) DescribeListeners(ctx context.Context, params *ela
 	return args.Get(0).(*elasticloadbalancingv2.DescribeListenersOutput), args.Error(1)
 }
 
+func (m *albDiscoveryApiMock) DescribeTags(ctx context.Context, params *elasticloadbalancingv2.DescribeTagsInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeTagsOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*elasticloadbalancingv2.DescribeTagsOutput), args.Error(1)
+}
+
 type albDiscoveryEc2UtilMock struct {
 	mock.Mock
 }
@@ -96,15 +110,15 @@ func TestGetAllAlbTargets(t *testing.T) {
 	mockedApi.On("DescribeLoadBalancers", mock.Anything, mock.Anything).Return(&elasticloadbalancingv2.DescribeLoadBalancersOutput{
 		LoadBalancers: []types.LoadBalancer{alb, nlb},
 	}, nil)
-	mockedApi.On("DescribeTags", mock.Anything, mock.MatchedBy(func(params *elasticloadbalancingv2.DescribeTagsInput) bool {
-		require.Equal(t, albArn, params.ResourceArns[0])
-		require.Equal(t, nlbArn, params.ResourceArns[1])
+	mockedTagClient := new(tagClientMock)
+	mockedTagClient.On("GetResources", mock.Anything, mock.MatchedBy(func(params *resourcegroupstaggingapi.GetResourcesInput) bool {
+		require.Equal(t, []string{"elasticloadbalancing:loadbalancer"}, params.ResourceTypeFilters)
 		return true
-	})).Return(&elasticloadbalancingv2.DescribeTagsOutput{
-		TagDescriptions: []types.TagDescription{
+	})).Return(&resourcegroupstaggingapi.GetResourcesOutput{
+		ResourceTagMappingList: []tagtypes.ResourceTagMapping{
 			{
-				ResourceArn: new(albArn),
-				Tags: []types.Tag{
+				ResourceARN: new(albArn),
+				Tags: []tagtypes.Tag{
 					{
 						Key:   new("elbv2.k8s.aws/cluster"),
 						Value: new("test-cluster"),
@@ -119,10 +133,6 @@ func TestGetAllAlbTargets(t *testing.T) {
 					},
 				},
 			},
-			{
-				ResourceArn: new(nlbArn),
-				Tags:        []types.Tag{},
-			},
 		},
 	}, nil)
 	mockedApi.On("DescribeListeners", mock.Anything, mock.MatchedBy(func(params *elasticloadbalancingv2.DescribeListenersInput) bool {
@@ -159,7 +169,7 @@ func TestGetAllAlbTargets(t *testing.T) {
 	mockedZoneUtil.On("GetVpcName", mock.Anything, mock.Anything, mock.Anything).Return("vpc-123-name")
 
 	// When
-	targets, err := GetAlbs(context.Background(), mockedApi, mockedZoneUtil, &utils.AwsAccess{
+	targets, err := GetAlbs(context.Background(), mockedApi, mockedTagClient, mockedZoneUtil, &utils.AwsAccess{
 		AccountNumber: "42",
 		Region:        "us-east-1",
 		AssumeRole:    new("arn:aws:iam::42:role/extension-aws-role"),
@@ -193,4 +203,5 @@ func TestGetAllAlbTargets(t *testing.T) {
 	assert.Equal(t, []string{"test-cluster"}, target.Attributes["k8s.cluster-name"])
 	assert.Equal(t, []string{"arn:aws:iam::42:role/extension-aws-role"}, target.Attributes["extension-aws.discovered-by-role"])
 	mockedApi.AssertExpectations(t)
+	mockedTagClient.AssertExpectations(t)
 }
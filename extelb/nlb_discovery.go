@@ -9,15 +9,14 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/extec2"
@@ -33,10 +32,18 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*nlbDiscovery)(nil)
 )
 
+// NlbDiscoveryPermissions lists the IAM actions called by the discovery.
+var NlbDiscoveryPermissions = []string{
+	"elasticloadbalancing:DescribeLoadBalancers",
+	"elasticloadbalancing:DescribeLoadBalancerAttributes",
+	"elasticloadbalancing:DescribeListeners",
+	"tag:GetResources",
+}
+
 func NewNlbDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(&nlbDiscovery{},
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalElbNlb)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalElbNlb }),
 	)
 }
 
@@ -44,7 +51,7 @@ func (d *nlbDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: nlbTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalElbNlb)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalElbNlb)),
 		},
 	}
 }
@@ -89,7 +96,8 @@ func (d *nlbDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api
 
 func getNlbTargetsForAccount(account *utils.AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client := elasticloadbalancingv2.NewFromConfig(account.AwsConfig)
-	result, err := getNlbs(ctx, client, extec2.Util, account)
+	tagsClient := resourcegroupstaggingapi.NewFromConfig(account.AwsConfig)
+	result, err := getNlbs(ctx, client, tagsClient, extec2.Util, account)
 	if err != nil {
 		var re *awshttp.ResponseError
 		if errors.As(err, &re) && re.HTTPStatusCode() == 403 {
@@ -104,64 +112,56 @@ func getNlbTargetsForAccount(account *utils.AwsAccess, ctx context.Context) ([]d
 type NlbDiscoveryApi interface {
 	elasticloadbalancingv2.DescribeLoadBalancersAPIClient
 	elasticloadbalancingv2.DescribeListenersAPIClient
-	DescribeTags(ctx context.Context, params *elasticloadbalancingv2.DescribeTagsInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeTagsOutput, error)
 	DescribeLoadBalancerAttributes(ctx context.Context, params *elasticloadbalancingv2.DescribeLoadBalancerAttributesInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeLoadBalancerAttributesOutput, error)
+	describeTagsApi
 }
 
-func getNlbs(ctx context.Context, api NlbDiscoveryApi, ec2Util albDiscoveryEc2Util, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
+func getNlbs(ctx context.Context, api NlbDiscoveryApi, tagsClient resourcegroupstaggingapi.GetResourcesAPIClient, ec2Util albDiscoveryEc2Util, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
 	result := make([]discovery_kit_api.Target, 0, 20)
 
+	var resourceTags *utils.ResourceTags
 	paginator := elasticloadbalancingv2.NewDescribeLoadBalancersPaginator(api, &elasticloadbalancingv2.DescribeLoadBalancersInput{})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
 			return nil, extension_kit.ToError("Failed to fetch load balancers.", err)
 		}
-		lbPages := utils.SplitIntoPages(output.LoadBalancers, describeTagsMaxPagesize)
-		for _, lbPage := range lbPages {
-			lbArns := make([]string, 0, len(lbPage))
-			for _, lb := range lbPage {
-				if lb.Type == types.LoadBalancerTypeEnumNetwork {
-					lbArns = append(lbArns, *lb.LoadBalancerArn)
-				}
-			}
-			if len(lbArns) == 0 {
+		for _, lb := range output.LoadBalancers {
+			if lb.Type != types.LoadBalancerTypeEnumNetwork {
 				continue
 			}
-			tagsResult, err := api.DescribeTags(ctx, &elasticloadbalancingv2.DescribeTagsInput{ResourceArns: lbArns})
+			if resourceTags == nil {
+				fetched, err := utils.GetResourceTags(ctx, tagsClient, account, loadBalancerResourceType)
+				if err != nil {
+					return nil, extension_kit.ToError("Failed to fetch tags.", err)
+				}
+				resourceTags = &fetched
+			}
+			tagMap, err := resourceTags.Get(aws.ToString(lb.LoadBalancerArn), func() (map[string]string, error) {
+				return fetchLoadBalancerTags(ctx, api, aws.ToString(lb.LoadBalancerArn))
+			})
 			if err != nil {
 				return nil, extension_kit.ToError("Failed to fetch tags.", err)
 			}
+			tags := toElbTags(tagMap)
+			if !matchesTagFilter(tags, account.TagFilters) {
+				continue
+			}
 
-			for _, lb := range lbPage {
-				if lb.Type != types.LoadBalancerTypeEnumNetwork {
-					continue
-				}
-				var tags []types.Tag
-				for _, td := range tagsResult.TagDescriptions {
-					if *td.ResourceArn == *lb.LoadBalancerArn {
-						tags = td.Tags
-					}
-				}
-				if !matchesTagFilter(tags, account.TagFilters) {
-					continue
-				}
-
-				listenersOut, err := api.DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{LoadBalancerArn: lb.LoadBalancerArn})
-				if err != nil {
-					return nil, extension_kit.ToError("Failed to fetch NLB listeners.", err)
-				}
-
-				attrsOut, err := api.DescribeLoadBalancerAttributes(ctx, &elasticloadbalancingv2.DescribeLoadBalancerAttributesInput{LoadBalancerArn: lb.LoadBalancerArn})
-				if err != nil {
-					return nil, extension_kit.ToError("Failed to fetch NLB attributes.", err)
-				}
+			listenersOut, err := api.DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{LoadBalancerArn: lb.LoadBalancerArn})
+			if err != nil {
+				return nil, extension_kit.ToError("Failed to fetch NLB listeners.", err)
+			}
 
-				result = append(result, toNlbTarget(&lb, tags, listenersOut.Listeners, attrsOut.Attributes, ec2Util, account.AccountNumber, account.Region, account.AssumeRole))
+			attrsOut, err := api.DescribeLoadBalancerAttributes(ctx, &elasticloadbalancingv2.DescribeLoadBalancerAttributesInput{LoadBalancerArn: lb.LoadBalancerArn})
+			if err != nil {
+				return nil, extension_kit.ToError("Failed to fetch NLB attributes.", err)
 			}
+
+			result = append(result, toNlbTarget(&lb, tags, listenersOut.Listeners, attrsOut.Attributes, ec2Util, account.AccountNumber, account.Region, account.AssumeRole))
 		}
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesElb), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesElb, config.Config().DiscoveryAttributesIncludesElb), nil
 }
 
 func toNlbTarget(lb *types.LoadBalancer, tags []types.Tag, listeners []types.Listener, lbAttrs []types.LoadBalancerAttribute, ec2Util albDiscoveryEc2Util, awsAccount string, awsRegion string, role *string) discovery_kit_api.Target {
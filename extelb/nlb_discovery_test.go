@@ -10,6 +10,8 @@ import (
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	tagtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
 	"github.com/steadybit/extension-aws/v2/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -27,20 +29,20 @@ func (m *nlbDiscoveryApiMock) DescribeLoadBalancers(ctx context.Context, params
 	return args.Get(0).(*elasticloadbalancingv2.DescribeLoadBalancersOutput), args.Error(1)
 }
 
-func (m *nlbDiscoveryApiMock) DescribeTags(ctx context.Context, params *elasticloadbalancingv2.DescribeTagsInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeTagsOutput, error) {
+func (m *nlbDiscoveryApiMock) DescribeListeners(ctx context.Context, params *elasticloadbalancingv2.DescribeListenersInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeListenersOutput, error) {
 	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*elasticloadbalancingv2.DescribeTagsOutput), args.Error(1)
+	return args.Get(0).(*elasticloadbalancingv2.DescribeListenersOutput), args.Error(1)
 }
 
-func (m *nlbDiscoveryApiMock) DescribeListeners(ctx context.Context, params *elasticloadbalancingv2.DescribeListenersInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeListenersOutput, error) {
+func (m *nlbDiscoveryApiMock) DescribeTags(ctx context.Context, params *elasticloadbalancingv2.DescribeTagsInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeTagsOutput, error) {
 	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*elasticloadbalancingv2.DescribeListenersOutput), args.Error(1)
+	return args.Get(0).(*elasticloadbalancingv2.DescribeTagsOutput), args.Error(1)
 }
 
 func (m *nlbDiscoveryApiMock) DescribeLoadBalancerAttributes(ctx context.Context, params *elasticloadbalancingv2.DescribeLoadBalancerAttributesInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeLoadBalancerAttributesOutput, error) {
@@ -77,12 +79,10 @@ func TestGetNlbsFiltersOutAlb(t *testing.T) {
 			},
 		},
 	}, nil)
-	api.On("DescribeTags", mock.Anything, mock.MatchedBy(func(p *elasticloadbalancingv2.DescribeTagsInput) bool {
-		// Should ONLY include the NLB ARN (ALB filtered out)
-		return len(p.ResourceArns) == 1 && p.ResourceArns[0] == nlbArnLocal
-	})).Return(&elasticloadbalancingv2.DescribeTagsOutput{
-		TagDescriptions: []types.TagDescription{
-			{ResourceArn: &nlbArnLocal, Tags: []types.Tag{
+	tagClient := new(tagClientMock)
+	tagClient.On("GetResources", mock.Anything, mock.Anything).Return(&resourcegroupstaggingapi.GetResourcesOutput{
+		ResourceTagMappingList: []tagtypes.ResourceTagMapping{
+			{ResourceARN: &nlbArnLocal, Tags: []tagtypes.Tag{
 				{Key: new("application"), Value: new("Demo")},
 			}},
 		},
@@ -105,7 +105,7 @@ func TestGetNlbsFiltersOutAlb(t *testing.T) {
 	zoneUtil.On("GetZone", mock.Anything, mock.Anything, mock.Anything).Return(&ec2types.AvailabilityZone{ZoneId: new("us-east-1a-id")})
 	zoneUtil.On("GetVpcName", mock.Anything, mock.Anything, mock.Anything).Return("vpc-1-name")
 
-	targets, err := getNlbs(context.Background(), api, zoneUtil, &utils.AwsAccess{
+	targets, err := getNlbs(context.Background(), api, tagClient, zoneUtil, &utils.AwsAccess{
 		AccountNumber: "42",
 		Region:        "us-east-1",
 		AssumeRole:    new("arn:aws:iam::42:role/extension-aws-role"),
@@ -137,10 +137,11 @@ func TestGetNlbsEmptyWhenOnlyAlbs(t *testing.T) {
 			{LoadBalancerArn: new("arn:alb"), LoadBalancerName: new("only-alb"), Type: types.LoadBalancerTypeEnumApplication},
 		},
 	}, nil)
+	tagClient := new(tagClientMock)
 	zoneUtil := new(albDiscoveryEc2UtilMock)
-	targets, err := getNlbs(context.Background(), api, zoneUtil, &utils.AwsAccess{AccountNumber: "42", Region: "us-east-1"})
+	targets, err := getNlbs(context.Background(), api, tagClient, zoneUtil, &utils.AwsAccess{AccountNumber: "42", Region: "us-east-1"})
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(targets))
-	api.AssertNotCalled(t, "DescribeTags", mock.Anything, mock.Anything)
+	tagClient.AssertNotCalled(t, "GetResources", mock.Anything, mock.Anything)
 	api.AssertNotCalled(t, "DescribeLoadBalancerAttributes", mock.Anything, mock.Anything)
 }
@@ -71,6 +71,10 @@ func (a *ruleDisableAttack) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (a *ruleDisableAttack) RequiredPermissions() []string {
+	return []string{"events:DisableRule", "events:EnableRule"}
+}
+
 func (a *ruleDisableAttack) Prepare(_ context.Context, state *EventBridgeRuleAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
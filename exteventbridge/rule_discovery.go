@@ -7,18 +7,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"slices"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
@@ -32,10 +30,18 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*ruleDiscovery)(nil)
 )
 
+// RuleDiscoveryPermissions lists the IAM actions called by the discovery.
+var RuleDiscoveryPermissions = []string{
+	"events:ListEventBuses",
+	"events:ListRules",
+	"events:ListTargetsByRule",
+	"tag:GetResources",
+}
+
 func NewRuleDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(&ruleDiscovery{},
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalEventbridge)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalEventbridge }),
 	)
 }
 
@@ -43,7 +49,7 @@ func (d *ruleDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: ruleTargetType,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalEventbridge)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalEventbridge)),
 		},
 	}
 }
@@ -88,7 +94,8 @@ func (d *ruleDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_ap
 
 func getRuleTargets(account *utils.AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client := eventbridge.NewFromConfig(account.AwsConfig)
-	result, err := getAllRules(ctx, client, account)
+	tagsClient := resourcegroupstaggingapi.NewFromConfig(account.AwsConfig)
+	result, err := getAllRules(ctx, client, tagsClient, account)
 	if err != nil {
 		var re *awshttp.ResponseError
 		if errors.As(err, &re) && re.HTTPStatusCode() == 403 {
@@ -100,11 +107,15 @@ func getRuleTargets(account *utils.AwsAccess, ctx context.Context) ([]discovery_
 	return result, nil
 }
 
-func getAllRules(ctx context.Context, client EventBridgeApi, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
+func getAllRules(ctx context.Context, client EventBridgeApi, tagsClient resourcegroupstaggingapi.GetResourcesAPIClient, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
 	busNames, err := listAllBusNames(ctx, client)
 	if err != nil {
 		return nil, err
 	}
+	resourceTags, err := utils.GetResourceTags(ctx, tagsClient, account, "events:rule")
+	if err != nil {
+		return nil, err
+	}
 	result := make([]discovery_kit_api.Target, 0, 20)
 	for _, bus := range busNames {
 		rules, err := listAllRulesOnBus(ctx, client, bus)
@@ -116,30 +127,38 @@ func getAllRules(ctx context.Context, client EventBridgeApi, account *utils.AwsA
 			if rule.Name == nil {
 				continue
 			}
-			targets, err := listAllTargetsByRule(ctx, client, bus, *rule.Name)
+			tags, err := resourceTags.Get(aws.ToString(rule.Arn), func() (map[string]string, error) {
+				return fetchRuleTags(ctx, client, rule.Arn)
+			})
 			if err != nil {
-				log.Warn().Err(err).Msgf("Failed to list EventBridge rule targets for %s/%s", bus, *rule.Name)
+				return nil, err
 			}
-			tags := map[string]string{}
-			if rule.Arn != nil {
-				tagsOut, err := client.ListTagsForResource(ctx, &eventbridge.ListTagsForResourceInput{ResourceARN: rule.Arn})
-				if err != nil {
-					log.Debug().Err(err).Msgf("Failed to list tags for EventBridge rule %s", *rule.Arn)
-				} else {
-					for _, t := range tagsOut.Tags {
-						if t.Key != nil {
-							tags[*t.Key] = aws.ToString(t.Value)
-						}
-					}
-				}
-			}
-			if !matchesEventbridgeTagFilter(tags, account.TagFilters) {
+			if !utils.MatchesTags(tags, account.TagFilters) {
 				continue
 			}
+			targets, err := listAllTargetsByRule(ctx, client, bus, *rule.Name)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed to list EventBridge rule targets for %s/%s", bus, *rule.Name)
+			}
 			result = append(result, toRuleTarget(rule, bus, targets, tags, account.AccountNumber, account.Region, account.AssumeRole))
 		}
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesEventbridge), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesEventbridge, config.Config().DiscoveryAttributesIncludesEventbridge), nil
+}
+
+// fetchRuleTags reads the tags of the rule if they could not be fetched with the tagging API.
+func fetchRuleTags(ctx context.Context, client EventBridgeApi, ruleArn *string) (map[string]string, error) {
+	out, err := client.ListTagsForResource(ctx, &eventbridge.ListTagsForResourceInput{ResourceARN: ruleArn})
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(out.Tags))
+	for _, tag := range out.Tags {
+		if tag.Key != nil {
+			tags[*tag.Key] = aws.ToString(tag.Value)
+		}
+	}
+	return tags, nil
 }
 
 func listAllBusNames(ctx context.Context, client EventBridgeApi) ([]string, error) {
@@ -201,24 +220,6 @@ func listAllTargetsByRule(ctx context.Context, client EventBridgeApi, busName st
 	return targets, nil
 }
 
-func matchesEventbridgeTagFilter(tags map[string]string, filters []config.TagFilter) bool {
-	if len(filters) == 0 {
-		return true
-	}
-	for _, filter := range filters {
-		matched := false
-		if v, ok := tags[filter.Key]; ok {
-			if slices.Contains(filter.Values, v) {
-				matched = true
-			}
-		}
-		if !matched {
-			return false
-		}
-	}
-	return true
-}
-
 func toRuleTarget(rule types.Rule, busName string, targets []types.Target, tags map[string]string, account string, region string, role *string) discovery_kit_api.Target {
 	arn := aws.ToString(rule.Arn)
 	name := aws.ToString(rule.Name)
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package exteventbridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	tagtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-aws/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type eventBridgeApiMock struct {
+	mock.Mock
+}
+
+func (m *eventBridgeApiMock) ListEventBuses(ctx context.Context, params *eventbridge.ListEventBusesInput, optFns ...func(*eventbridge.Options)) (*eventbridge.ListEventBusesOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*eventbridge.ListEventBusesOutput), args.Error(1)
+}
+
+func (m *eventBridgeApiMock) ListRules(ctx context.Context, params *eventbridge.ListRulesInput, optFns ...func(*eventbridge.Options)) (*eventbridge.ListRulesOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*eventbridge.ListRulesOutput), args.Error(1)
+}
+
+func (m *eventBridgeApiMock) ListTargetsByRule(ctx context.Context, params *eventbridge.ListTargetsByRuleInput, optFns ...func(*eventbridge.Options)) (*eventbridge.ListTargetsByRuleOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*eventbridge.ListTargetsByRuleOutput), args.Error(1)
+}
+
+func (m *eventBridgeApiMock) ListTagsForResource(ctx context.Context, params *eventbridge.ListTagsForResourceInput, optFns ...func(*eventbridge.Options)) (*eventbridge.ListTagsForResourceOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*eventbridge.ListTagsForResourceOutput), args.Error(1)
+}
+
+func (m *eventBridgeApiMock) EnableRule(ctx context.Context, params *eventbridge.EnableRuleInput, optFns ...func(*eventbridge.Options)) (*eventbridge.EnableRuleOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*eventbridge.EnableRuleOutput), args.Error(1)
+}
+
+func (m *eventBridgeApiMock) DisableRule(ctx context.Context, params *eventbridge.DisableRuleInput, optFns ...func(*eventbridge.Options)) (*eventbridge.DisableRuleOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*eventbridge.DisableRuleOutput), args.Error(1)
+}
+
+type tagClientMock struct {
+	mock.Mock
+}
+
+func (m *tagClientMock) GetResources(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*resourcegroupstaggingapi.GetResourcesOutput), args.Error(1)
+}
+
+func newTagClientMock(mappings ...tagtypes.ResourceTagMapping) *tagClientMock {
+	tagClient := new(tagClientMock)
+	tagClient.On("GetResources", mock.Anything, mock.MatchedBy(func(p *resourcegroupstaggingapi.GetResourcesInput) bool {
+		return p.ResourceTypeFilters[0] == "events:rule"
+	})).Return(&resourcegroupstaggingapi.GetResourcesOutput{ResourceTagMappingList: mappings}, nil)
+	return tagClient
+}
+
+func newRuleApiMock() *eventBridgeApiMock {
+	api := new(eventBridgeApiMock)
+	api.On("ListEventBuses", mock.Anything, mock.Anything).Return(&eventbridge.ListEventBusesOutput{
+		EventBuses: []types.EventBus{{Name: aws.String("default")}},
+	}, nil)
+	api.On("ListRules", mock.Anything, mock.MatchedBy(func(p *eventbridge.ListRulesInput) bool {
+		return aws.ToString(p.EventBusName) == "default"
+	})).Return(&eventbridge.ListRulesOutput{Rules: []types.Rule{
+		{Name: aws.String("orders"), Arn: aws.String("arn:aws:events:us-east-1:42:rule/orders"), State: types.RuleStateEnabled},
+		{Name: aws.String("untagged"), Arn: aws.String("arn:aws:events:us-east-1:42:rule/untagged"), State: types.RuleStateEnabled},
+	}}, nil)
+	api.On("ListTargetsByRule", mock.Anything, mock.Anything).Return(&eventbridge.ListTargetsByRuleOutput{
+		Targets: []types.Target{{Id: aws.String("queue"), Arn: aws.String("arn:aws:sqs:us-east-1:42:orders")}},
+	}, nil)
+	return api
+}
+
+func TestGetAllRulesAddsTagsAsLabels(t *testing.T) {
+	api := newRuleApiMock()
+	tagClient := newTagClientMock(tagtypes.ResourceTagMapping{
+		ResourceARN: aws.String("arn:aws:events:us-east-1:42:rule/orders"),
+		Tags:        []tagtypes.Tag{{Key: aws.String("Application"), Value: aws.String("Demo")}},
+	})
+
+	targets, err := getAllRules(context.Background(), api, tagClient, &utils.AwsAccess{
+		AccountNumber: "42",
+		Region:        "us-east-1",
+	})
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, "default/orders", targets[0].Label)
+	assert.Equal(t, []string{"Demo"}, targets[0].Attributes["aws.eventbridge.rule.label.application"])
+	assert.Equal(t, []string{"1"}, targets[0].Attributes["aws.eventbridge.rule.target-count"])
+	assert.Equal(t, "default/untagged", targets[1].Label)
+	assert.NotContains(t, targets[1].Attributes, "aws.eventbridge.rule.label.application")
+}
+
+func TestGetAllRulesFiltersByTagsBeforeListingTargets(t *testing.T) {
+	api := newRuleApiMock()
+	tagClient := newTagClientMock(tagtypes.ResourceTagMapping{
+		ResourceARN: aws.String("arn:aws:events:us-east-1:42:rule/orders"),
+		Tags:        []tagtypes.Tag{{Key: aws.String("application"), Value: aws.String("Demo")}},
+	})
+
+	targets, err := getAllRules(context.Background(), api, tagClient, &utils.AwsAccess{
+		AccountNumber: "42",
+		Region:        "us-east-1",
+		TagFilters:    []extConfig.TagFilter{{Key: "application", Values: []string{"Demo"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "arn:aws:events:us-east-1:42:rule/orders", targets[0].Id)
+	assert.Equal(t, []string{"Demo"}, targets[0].Attributes["aws.eventbridge.rule.label.application"])
+	api.AssertNumberOfCalls(t, "ListTargetsByRule", 1)
+	api.AssertCalled(t, "ListTargetsByRule", mock.Anything, mock.MatchedBy(func(p *eventbridge.ListTargetsByRuleInput) bool {
+		return aws.ToString(p.Rule) == "orders"
+	}))
+}
@@ -91,6 +91,15 @@ func (f FisExperimentAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (f FisExperimentAction) RequiredPermissions() []string {
+	return []string{
+		"fis:StartExperiment",
+		"fis:GetExperiment",
+		"fis:StopExperiment",
+		"fis:TagResource",
+	}
+}
+
 func (f FisExperimentAction) Prepare(_ context.Context, state *FisExperimentState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.TemplateId = extutil.MustHaveValue(request.Target.Attributes, "aws.fis.experiment.template.id")[0]
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
@@ -14,12 +14,10 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/sosodev/duration"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
 	"github.com/steadybit/extension-kit/extbuild"
-	"slices"
 	"strings"
 	"time"
 )
@@ -31,11 +29,14 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*fisTemplateDiscovery)(nil)
 )
 
+// FisTemplateDiscoveryPermissions lists the IAM actions called by the discovery.
+var FisTemplateDiscoveryPermissions = []string{"fis:ListExperimentTemplates", "fis:GetExperimentTemplate"}
+
 func NewFisTemplateDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &fisTemplateDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalFis)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalFis }),
 	)
 }
 
@@ -43,7 +44,7 @@ func (f *fisTemplateDiscovery) Describe() discovery_kit_api.DiscoveryDescription
 	return discovery_kit_api.DiscoveryDescription{
 		Id: fisTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalFis)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalFis)),
 		},
 	}
 }
@@ -133,32 +134,13 @@ func GetAllFisTemplates(ctx context.Context, fisApi FisApi, account *utils.AwsAc
 			if err != nil {
 				return result, err
 			}
-			if matchesTagFilter(template.Tags, account.TagFilters) {
+			if utils.MatchesTags(template.Tags, account.TagFilters) {
 				result = append(result, toTarget(template, account.AccountNumber, account.Region, account.AssumeRole, totalDuration))
 			}
 		}
 	}
 
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesFis), nil
-}
-
-func matchesTagFilter(tags map[string]string, filters []config.TagFilter) bool {
-	if len(filters) == 0 {
-		return true
-	}
-
-	for _, filter := range filters {
-		matched := false
-		if value, exists := tags[filter.Key]; exists {
-			if slices.Contains(filter.Values, value) {
-				matched = true
-			}
-		}
-		if !matched {
-			return false
-		}
-	}
-	return true
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesFis, config.Config().DiscoveryAttributesIncludesFis), nil
 }
 
 func toTarget(template types.ExperimentTemplateSummary, awsAccountNumber string, awsRegion string, role *string, totalDuration *time.Duration) discovery_kit_api.Target {
@@ -64,6 +64,10 @@ func (a *lambdaAction) NewEmptyState() LambdaActionState {
 	return LambdaActionState{}
 }
 
+func (a *lambdaAction) RequiredPermissions() []string {
+	return []string{"ssm:PutParameter", "ssm:DeleteParameter", "ssm:AddTagsToResource"}
+}
+
 func (a *lambdaAction) Prepare(_ context.Context, state *LambdaActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	failureInjectionParam := request.Target.Attributes["aws.lambda.failure-injection-param"]
 	if len(failureInjectionParam) == 0 {
@@ -16,7 +16,6 @@ import (
 	tagTypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/extec2"
@@ -24,7 +23,6 @@ import (
 	"github.com/steadybit/extension-kit/extbuild"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type lambdaDiscovery struct{}
@@ -35,11 +33,14 @@ var (
 	missingPermissionTagsAlreadyLogged                                      = false
 )
 
+// LambdaDiscoveryPermissions lists the IAM actions called by the discovery.
+var LambdaDiscoveryPermissions = []string{"lambda:ListFunctions", "tag:GetResources"}
+
 func NewLambdaDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &lambdaDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalLambda)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalLambda }),
 	)
 }
 
@@ -47,7 +48,7 @@ func (l *lambdaDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: lambdaTargetID,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalLambda)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalLambda)),
 		},
 	}
 }
@@ -234,7 +235,7 @@ func getAllAwsLambdaFunctions(ctx context.Context, lambdaClient lambda.ListFunct
 			marker = output.NextMarker
 		}
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesLambda), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesLambda, config.Config().DiscoveryAttributesIncludesLambda), nil
 }
 
 func getTags(ctx context.Context, output *lambda.ListFunctionsOutput, tagsClient resourcegroupstaggingapi.GetResourcesAPIClient, tagsRequired bool) ([]tagTypes.ResourceTagMapping, error) {
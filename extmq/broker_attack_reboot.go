@@ -60,6 +60,10 @@ func (a brokerRebootAttack) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (a brokerRebootAttack) RequiredPermissions() []string {
+	return []string{"mq:DescribeBroker", "mq:RebootBroker"}
+}
+
 func (a brokerRebootAttack) Prepare(_ context.Context, state *BrokerAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
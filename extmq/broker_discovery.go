@@ -7,18 +7,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"slices"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/mq"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
@@ -32,10 +29,13 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*brokerDiscovery)(nil)
 )
 
+// BrokerDiscoveryPermissions lists the IAM actions called by the discovery.
+var BrokerDiscoveryPermissions = []string{"mq:ListBrokers", "mq:DescribeBroker"}
+
 func NewBrokerDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(&brokerDiscovery{},
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalMq)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalMq }),
 	)
 }
 
@@ -43,7 +43,7 @@ func (d *brokerDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: brokerTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalMq)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalMq)),
 		},
 	}
 }
@@ -117,31 +117,13 @@ func getAllBrokers(ctx context.Context, client MqApi, account *utils.AwsAccess)
 				log.Warn().Err(err).Msgf("Failed to describe Amazon MQ broker %s", aws.ToString(summary.BrokerId))
 				continue
 			}
-			if !matchesBrokerTagFilter(described.Tags, account.TagFilters) {
+			if !utils.MatchesTags(described.Tags, account.TagFilters) {
 				continue
 			}
 			result = append(result, toBrokerTarget(described, account.AccountNumber, account.Region, account.AssumeRole))
 		}
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesMq), nil
-}
-
-func matchesBrokerTagFilter(tags map[string]string, filters []config.TagFilter) bool {
-	if len(filters) == 0 {
-		return true
-	}
-	for _, filter := range filters {
-		matched := false
-		if value, ok := tags[filter.Key]; ok {
-			if slices.Contains(filter.Values, value) {
-				matched = true
-			}
-		}
-		if !matched {
-			return false
-		}
-	}
-	return true
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesMq, config.Config().DiscoveryAttributesIncludesMq), nil
 }
 
 func toBrokerTarget(b *mq.DescribeBrokerOutput, account string, region string, role *string) discovery_kit_api.Target {
@@ -12,7 +12,6 @@ import (
 	tagTypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -34,11 +33,14 @@ var (
 	missingPermissionTagsAlreadyLogged                                      = false
 )
 
+// MskClusterDiscoveryPermissions lists the IAM actions called by the discovery.
+var MskClusterDiscoveryPermissions = []string{"kafka:ListClustersV2", "kafka:ListNodes", "tag:GetResources"}
+
 func NewMskClusterDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &mskClusterDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalMsk)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalMsk }),
 	)
 }
 
@@ -46,7 +48,7 @@ func (r *mskClusterDiscovery) Describe() discovery_kit_api.DiscoveryDescription
 	return discovery_kit_api.DiscoveryDescription{
 		Id: mskBrokerTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalMsk)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalMsk)),
 		},
 	}
 }
@@ -219,7 +221,7 @@ func getAllMskClusters(ctx context.Context, mskApi MskApi, tagsClient resourcegr
 		}
 	}
 
-	return result, nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesMsk, config.Config().DiscoveryAttributesIncludesMsk), nil
 }
 
 func getTags(ctx context.Context, output *kafka.ListNodesOutput, tagsClient resourcegroupstaggingapi.GetResourcesAPIClient, tagsRequired bool) ([]tagTypes.ResourceTagMapping, error) {
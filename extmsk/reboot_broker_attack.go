@@ -54,6 +54,10 @@ func (f mskRebootBrokerAttack) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (f mskRebootBrokerAttack) RequiredPermissions() []string {
+	return []string{"kafka:RebootBroker"}
+}
+
 func (f mskRebootBrokerAttack) Prepare(_ context.Context, state *KafkaAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
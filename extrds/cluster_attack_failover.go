@@ -52,6 +52,10 @@ func (f rdsClusterFailoverAttack) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (f rdsClusterFailoverAttack) RequiredPermissions() []string {
+	return []string{"rds:FailoverDBCluster"}
+}
+
 func (f rdsClusterFailoverAttack) Prepare(_ context.Context, state *RdsClusterAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	return nil, convertClusterAttackState(request, state)
 }
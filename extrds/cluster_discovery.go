@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -30,11 +29,14 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*rdsClusterDiscovery)(nil)
 )
 
+// RdsClusterDiscoveryPermissions lists the IAM actions called by the discovery.
+var RdsClusterDiscoveryPermissions = []string{"rds:DescribeDBClusters"}
+
 func NewRdsClusterDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &rdsClusterDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalRds)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalRds }),
 	)
 }
 
@@ -42,7 +44,7 @@ func (r *rdsClusterDiscovery) Describe() discovery_kit_api.DiscoveryDescription
 	return discovery_kit_api.DiscoveryDescription{
 		Id: rdsClusterTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalRds)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalRds)),
 		},
 	}
 }
@@ -154,7 +156,7 @@ func getAllRdsClusters(ctx context.Context, rdsApi rdsDBClusterApi, account *uti
 		}
 	}
 
-	return result, nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesRds, config.Config().DiscoveryAttributesIncludesRds), nil
 }
 
 func toClusterTarget(dbCluster types.DBCluster, awsAccountNumber string, awsRegion string, role *string) discovery_kit_api.Target {
@@ -65,6 +65,10 @@ func (f rdsInstanceRebootAttack) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (f rdsInstanceRebootAttack) RequiredPermissions() []string {
+	return []string{"rds:RebootDBInstance"}
+}
+
 func (f rdsInstanceRebootAttack) Prepare(_ context.Context, state *RdsInstanceAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	return nil, convertInstanceAttackState(request, state)
 }
@@ -58,6 +58,10 @@ func (f rdsInstanceStopAttack) Describe() action_kit_api.ActionDescription {
 	}
 }
 
+func (f rdsInstanceStopAttack) RequiredPermissions() []string {
+	return []string{"rds:StopDBInstance"}
+}
+
 func (f rdsInstanceStopAttack) Prepare(_ context.Context, state *RdsInstanceAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	return nil, convertInstanceAttackState(request, state)
 }
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"github.com/steadybit/extension-aws/v2/extec2"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -17,7 +16,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/rds/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
@@ -32,11 +30,14 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*rdsInstanceDiscovery)(nil)
 )
 
+// RdsInstanceDiscoveryPermissions lists the IAM actions called by the discovery.
+var RdsInstanceDiscoveryPermissions = []string{"rds:DescribeDBInstances"}
+
 func NewRdsInstanceDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	discovery := &rdsInstanceDiscovery{}
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalRds)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalRds }),
 	)
 }
 
@@ -44,7 +45,7 @@ func (r *rdsInstanceDiscovery) Describe() discovery_kit_api.DiscoveryDescription
 	return discovery_kit_api.DiscoveryDescription{
 		Id: rdsInstanceTargetId,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalRds)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalRds)),
 		},
 	}
 }
@@ -133,7 +134,7 @@ func getAllRdsInstances(ctx context.Context, rdsApi rdsDBInstanceApi, ec2Util rd
 		}
 	}
 
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesRds), nil
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesRds, config.Config().DiscoveryAttributesIncludesRds), nil
 }
 
 func toInstanceTarget(dbInstance types.DBInstance, ec2util rdsInstanceDiscoveryEc2Util, awsAccountNumber string, awsRegion string, role *string) discovery_kit_api.Target {
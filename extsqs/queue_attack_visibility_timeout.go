@@ -84,6 +84,10 @@ func (a *queueVisibilityTimeoutAttack) Describe() action_kit_api.ActionDescripti
 	}
 }
 
+func (a *queueVisibilityTimeoutAttack) RequiredPermissions() []string {
+	return []string{"sqs:GetQueueAttributes", "sqs:SetQueueAttributes"}
+}
+
 func (a *queueVisibilityTimeoutAttack) Prepare(ctx context.Context, state *QueueVisibilityTimeoutAttackState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
 	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
@@ -8,18 +8,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"slices"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
-	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/steadybit/extension-aws/v2/utils"
@@ -33,10 +31,13 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*queueDiscovery)(nil)
 )
 
+// QueueDiscoveryPermissions lists the IAM actions called by the discovery.
+var QueueDiscoveryPermissions = []string{"sqs:ListQueues", "sqs:GetQueueAttributes", "tag:GetResources"}
+
 func NewQueueDiscovery(ctx context.Context) discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(&queueDiscovery{},
 		discovery_kit_sdk.WithRefreshTargetsNow(),
-		discovery_kit_sdk.WithRefreshTargetsInterval(ctx, time.Duration(config.Config.DiscoveryIntervalSqs)*time.Second),
+		utils.WithRefreshTargetsInterval(ctx, func() int { return config.Config().DiscoveryIntervalSqs }),
 	)
 }
 
@@ -44,7 +45,7 @@ func (d *queueDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 	return discovery_kit_api.DiscoveryDescription{
 		Id: queueTargetType,
 		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
-			CallInterval: new(fmt.Sprintf("%ds", config.Config.DiscoveryIntervalSqs)),
+			CallInterval: new(fmt.Sprintf("%ds", config.Config().DiscoveryIntervalSqs)),
 		},
 	}
 }
@@ -93,7 +94,8 @@ func (d *queueDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_a
 
 func getQueueTargets(account *utils.AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client := sqs.NewFromConfig(account.AwsConfig)
-	result, err := getAllQueues(ctx, client, account)
+	tagsClient := resourcegroupstaggingapi.NewFromConfig(account.AwsConfig)
+	result, err := getAllQueues(ctx, client, tagsClient, account)
 	if err != nil {
 		var re *awshttp.ResponseError
 		if errors.As(err, &re) && re.HTTPStatusCode() == 403 {
@@ -105,7 +107,11 @@ func getQueueTargets(account *utils.AwsAccess, ctx context.Context) ([]discovery
 	return result, nil
 }
 
-func getAllQueues(ctx context.Context, client SqsApi, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
+func getAllQueues(ctx context.Context, client SqsApi, tagsClient resourcegroupstaggingapi.GetResourcesAPIClient, account *utils.AwsAccess) ([]discovery_kit_api.Target, error) {
+	resourceTags, err := utils.GetResourceTags(ctx, tagsClient, account, "sqs")
+	if err != nil {
+		return nil, err
+	}
 	result := make([]discovery_kit_api.Target, 0)
 	paginator := sqs.NewListQueuesPaginator(client, &sqs.ListQueuesInput{})
 	for paginator.HasMorePages() {
@@ -114,6 +120,19 @@ func getAllQueues(ctx context.Context, client SqsApi, account *utils.AwsAccess)
 			return nil, err
 		}
 		for _, url := range out.QueueUrls {
+			tags, err := resourceTags.Get(utils.BuildArn("sqs", account.Region, account.AccountNumber, nameFromQueueUrl(url)), func() (map[string]string, error) {
+				tagsOut, err := client.ListQueueTags(ctx, &sqs.ListQueueTagsInput{QueueUrl: aws.String(url)})
+				if err != nil {
+					return nil, err
+				}
+				return tagsOut.Tags, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if !utils.MatchesTags(tags, account.TagFilters) {
+				continue
+			}
 			attrsOut, err := client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
 				QueueUrl:       aws.String(url),
 				AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameAll},
@@ -122,38 +141,10 @@ func getAllQueues(ctx context.Context, client SqsApi, account *utils.AwsAccess)
 				log.Warn().Err(err).Msgf("Failed to get attributes for SQS queue %s", url)
 				continue
 			}
-			tags := map[string]string{}
-			tagsOut, err := client.ListQueueTags(ctx, &sqs.ListQueueTagsInput{QueueUrl: aws.String(url)})
-			if err != nil {
-				log.Debug().Err(err).Msgf("Failed to list tags for SQS queue %s", url)
-			} else if tagsOut != nil {
-				tags = tagsOut.Tags
-			}
-			if !matchesSqsTagFilter(tags, account.TagFilters) {
-				continue
-			}
 			result = append(result, toQueueTarget(url, attrsOut.Attributes, tags, account.AccountNumber, account.Region, account.AssumeRole))
 		}
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesSqs), nil
-}
-
-func matchesSqsTagFilter(tags map[string]string, filters []config.TagFilter) bool {
-	if len(filters) == 0 {
-		return true
-	}
-	for _, filter := range filters {
-		matched := false
-		if v, ok := tags[filter.Key]; ok {
-			if slices.Contains(filter.Values, v) {
-				matched = true
-			}
-		}
-		if !matched {
-			return false
-		}
-	}
-	return true
+	return utils.ApplyAttributeFilters(result, config.Config().DiscoveryAttributesExcludesSqs, config.Config().DiscoveryAttributesIncludesSqs), nil
 }
 
 func toQueueTarget(url string, attrs map[string]string, tags map[string]string, account string, region string, role *string) discovery_kit_api.Target {
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extsqs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	tagtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/smithy-go"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-aws/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type tagClientMock struct {
+	mock.Mock
+}
+
+func (m *tagClientMock) GetResources(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*resourcegroupstaggingapi.GetResourcesOutput), args.Error(1)
+}
+
+func newTagClientMock(mappings ...tagtypes.ResourceTagMapping) *tagClientMock {
+	tagClient := new(tagClientMock)
+	tagClient.On("GetResources", mock.Anything, mock.MatchedBy(func(p *resourcegroupstaggingapi.GetResourcesInput) bool {
+		return p.ResourceTypeFilters[0] == "sqs"
+	})).Return(&resourcegroupstaggingapi.GetResourcesOutput{ResourceTagMappingList: mappings}, nil)
+	return tagClient
+}
+
+func newQueueApiMock(urls ...string) *sqsApiMock {
+	api := new(sqsApiMock)
+	api.On("ListQueues", mock.Anything, mock.Anything).Return(&sqs.ListQueuesOutput{QueueUrls: urls}, nil)
+	api.On("GetQueueAttributes", mock.Anything, mock.MatchedBy(func(p *sqs.GetQueueAttributesInput) bool {
+		return aws.ToString(p.QueueUrl) == "https://sqs.us-east-1.amazonaws.com/42/orders"
+	})).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{
+		"QueueArn":          "arn:aws:sqs:us-east-1:42:orders",
+		"VisibilityTimeout": "30",
+	}}, nil)
+	api.On("GetQueueAttributes", mock.Anything, mock.MatchedBy(func(p *sqs.GetQueueAttributesInput) bool {
+		return aws.ToString(p.QueueUrl) == "https://sqs.us-east-1.amazonaws.com/42/untagged"
+	})).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{
+		"QueueArn":          "arn:aws:sqs:us-east-1:42:untagged",
+		"VisibilityTimeout": "30",
+	}}, nil)
+	return api
+}
+
+func TestGetAllQueuesAddsTagsAsLabels(t *testing.T) {
+	api := newQueueApiMock("https://sqs.us-east-1.amazonaws.com/42/orders", "https://sqs.us-east-1.amazonaws.com/42/untagged")
+	tagClient := newTagClientMock(tagtypes.ResourceTagMapping{
+		ResourceARN: aws.String("arn:aws:sqs:us-east-1:42:orders"),
+		Tags:        []tagtypes.Tag{{Key: aws.String("Application"), Value: aws.String("Demo")}},
+	})
+
+	targets, err := getAllQueues(context.Background(), api, tagClient, &utils.AwsAccess{
+		AccountNumber: "42",
+		Region:        "us-east-1",
+	})
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, "orders", targets[0].Label)
+	assert.Equal(t, []string{"Demo"}, targets[0].Attributes["aws.sqs.queue.label.application"])
+	assert.Equal(t, "untagged", targets[1].Label)
+	assert.NotContains(t, targets[1].Attributes, "aws.sqs.queue.label.application")
+}
+
+func TestGetAllQueuesFiltersByTagsBeforeGettingAttributes(t *testing.T) {
+	api := newQueueApiMock("https://sqs.us-east-1.amazonaws.com/42/orders", "https://sqs.us-east-1.amazonaws.com/42/untagged")
+	tagClient := newTagClientMock(tagtypes.ResourceTagMapping{
+		ResourceARN: aws.String("arn:aws:sqs:us-east-1:42:orders"),
+		Tags:        []tagtypes.Tag{{Key: aws.String("application"), Value: aws.String("Demo")}},
+	})
+
+	targets, err := getAllQueues(context.Background(), api, tagClient, &utils.AwsAccess{
+		AccountNumber: "42",
+		Region:        "us-east-1",
+		TagFilters:    []extConfig.TagFilter{{Key: "application", Values: []string{"Demo"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "arn:aws:sqs:us-east-1:42:orders", targets[0].Id)
+	assert.Equal(t, []string{"Demo"}, targets[0].Attributes["aws.sqs.queue.label.application"])
+	api.AssertNumberOfCalls(t, "GetQueueAttributes", 1)
+}
+
+func TestGetAllQueuesFallsBackToQueueTagsIfTaggingApiIsDenied(t *testing.T) {
+	api := newQueueApiMock("https://sqs.us-east-1.amazonaws.com/42/orders", "https://sqs.us-east-1.amazonaws.com/42/untagged")
+	api.On("ListQueueTags", mock.Anything, mock.MatchedBy(func(p *sqs.ListQueueTagsInput) bool {
+		return aws.ToString(p.QueueUrl) == "https://sqs.us-east-1.amazonaws.com/42/orders"
+	})).Return(&sqs.ListQueueTagsOutput{Tags: map[string]string{"application": "Demo"}}, nil)
+	api.On("ListQueueTags", mock.Anything, mock.Anything).Return(&sqs.ListQueueTagsOutput{}, nil)
+	tagClient := new(tagClientMock)
+	tagClient.On("GetResources", mock.Anything, mock.Anything).Return(nil, &smithy.GenericAPIError{Code: "AccessDeniedException"})
+
+	targets, err := getAllQueues(context.Background(), api, tagClient, &utils.AwsAccess{
+		AccountNumber: "42",
+		Region:        "us-east-1",
+		TagFilters:    []extConfig.TagFilter{{Key: "application", Values: []string{"Demo"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "arn:aws:sqs:us-east-1:42:orders", targets[0].Id)
+	assert.Equal(t, []string{"Demo"}, targets[0].Attributes["aws.sqs.queue.label.application"])
+	api.AssertNumberOfCalls(t, "ListQueueTags", 2)
+}
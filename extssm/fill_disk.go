@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2024 Steadybit GmbH
+
+/*
+ * Copyright 2024 steadybit GmbH. All rights reserved.
+ */
+
+package extssm
+
+import (
+	"fmt"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"strconv"
+	"time"
+)
+
+func FillDiskParameters(request action_kit_api.PrepareActionRequestBody) (map[string][]string, error) {
+	duration := time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond
+
+	return map[string][]string{
+		"DurationSeconds": {fmt.Sprintf("%d", int64(duration.Seconds()))},
+		"Percent":         {strconv.Itoa(extutil.ToInt(request.Config["percent"]))},
+	}, nil
+}
+
+func FillDiskDescription(targetType string) action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.fill_disk", targetType),
+		Label:       "Fill Disk",
+		Description: "Fill ephemeral storage for the given duration.",
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "percent",
+				Label:        "Fill Percentage",
+				Description:  new("How many the percent of the allocated disk space dependent on the total available size shall be filled?"),
+				Type:         action_kit_api.ActionParameterTypePercentage,
+				DefaultValue: new("100"),
+				Required:     new(true),
+				Order:        new(0),
+				MinValue:     new(1),
+				MaxValue:     new(100),
+			},
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long should the disk be filled?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("30s"),
+				Required:     new(true),
+				Order:        new(2),
+			},
+		},
+	}
+}
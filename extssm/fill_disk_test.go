@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: MIT
 // SPDX-FileCopyrightText: 2024 Steadybit GmbH
 
-package extecs
+package extssm
 
 import (
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
@@ -17,7 +17,7 @@ func Test_getEcsTaskFillDiskParameters(t *testing.T) {
 		},
 	}
 
-	params, err := getEcsTaskFillDiskParameters(req)
+	params, err := FillDiskParameters(req)
 	assert.NoError(t, err)
 	assert.Equal(t, map[string][]string{
 		"DurationSeconds": {"1"},
@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: MIT
 // SPDX-FileCopyrightText: 2025 Steadybit GmbH
 
-package extecs
+package extssm
 
 import (
 	"encoding/json"
@@ -9,7 +9,7 @@ import (
 	"time"
 )
 
-const fisActionStateParameterName = "FISActionState"
+const FisActionStateParameterName = "FISActionState"
 
 // Field order is important. Do not change!
 type fisActionState struct {
@@ -18,7 +18,7 @@ type fisActionState struct {
 	CallCount int    `json:"callCount"`
 }
 
-func newFisActionStateParameter(id string) string {
+func NewFisActionStateParameter(id string) string {
 	fisActionState, _ := json.Marshal(fisActionState{
 		Id:        id,
 		CallCount: 1,
@@ -27,13 +27,13 @@ func newFisActionStateParameter(id string) string {
 	return string(fisActionState)
 }
 
-func updateFisActionStateParameter(parameters map[string][]string) error {
-	if len(parameters[fisActionStateParameterName]) == 0 || len(parameters[fisActionStateParameterName][0]) == 0 {
+func UpdateFisActionStateParameter(parameters map[string][]string) error {
+	if len(parameters[FisActionStateParameterName]) == 0 || len(parameters[FisActionStateParameterName][0]) == 0 {
 		return fmt.Errorf("missing FISActionState Parameter")
 	}
 
 	var fisActionState fisActionState
-	err := json.Unmarshal([]byte(parameters[fisActionStateParameterName][0]), &fisActionState)
+	err := json.Unmarshal([]byte(parameters[FisActionStateParameterName][0]), &fisActionState)
 	if err != nil {
 		return err
 	}
@@ -45,6 +45,6 @@ func updateFisActionStateParameter(parameters map[string][]string) error {
 		return err
 	}
 
-	parameters[fisActionStateParameterName][0] = string(f)
+	parameters[FisActionStateParameterName][0] = string(f)
 	return nil
 }
@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: MIT
 // SPDX-FileCopyrightText: 2025 Steadybit GmbH
 
-package extecs
+package extssm
 
 import (
 	"encoding/json"
@@ -12,7 +12,7 @@ import (
 
 func TestUpdateFisActionState_initial(t *testing.T) {
 	id := uuid.NewString()
-	initialFisActionStateParameter := newFisActionStateParameter(id)
+	initialFisActionStateParameter := NewFisActionStateParameter(id)
 	var initialFisActionState fisActionState
 	err := json.Unmarshal([]byte(initialFisActionStateParameter), &initialFisActionState)
 	assert.NoError(t, err)
@@ -21,13 +21,13 @@ func TestUpdateFisActionState_initial(t *testing.T) {
 	assert.NotZero(t, initialFisActionState.CallTime)
 
 	parametersToUpdate := map[string][]string{
-		fisActionStateParameterName: {initialFisActionStateParameter},
+		FisActionStateParameterName: {initialFisActionStateParameter},
 	}
-	err = updateFisActionStateParameter(parametersToUpdate)
+	err = UpdateFisActionStateParameter(parametersToUpdate)
 	assert.NoError(t, err)
 
 	var updatedFisActionState fisActionState
-	err = json.Unmarshal([]byte(parametersToUpdate[fisActionStateParameterName][0]), &updatedFisActionState)
+	err = json.Unmarshal([]byte(parametersToUpdate[FisActionStateParameterName][0]), &updatedFisActionState)
 	assert.NoError(t, err)
 	assert.Equal(t, initialFisActionState.Id, updatedFisActionState.Id)
 	assert.Equal(t, 2, updatedFisActionState.CallCount)
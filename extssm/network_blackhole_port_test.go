@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: MIT
 // SPDX-FileCopyrightText: 2025 Steadybit GmbH
 
-package extecs
+package extssm
 
 import (
 	"encoding/json"
@@ -12,11 +12,8 @@ import (
 )
 
 func Test_NetworkBlackholePort_Description(t *testing.T) {
-	action := NewEcsTaskNetworkBlockholePortAction()
-	assert.NotNil(t, action)
-
-	describe := action.Describe()
-	assert.NotNil(t, describe)
+	description := NetworkBlackholePortDescription("com.steadybit.extension_aws.ecs-task")
+	assert.Equal(t, "com.steadybit.extension_aws.ecs-task.network_blackhole_port", description.Id)
 }
 
 func Test_getEcsTaskNetworkBlackholePortParameters(t *testing.T) {
@@ -31,7 +28,7 @@ func Test_getEcsTaskNetworkBlackholePortParameters(t *testing.T) {
 		},
 	}
 
-	params, err := getEcsTaskNetworkBlackholePortParameters(req)
+	params, err := NetworkBlackholePortParameters(req)
 	assert.NoError(t, err)
 	assert.Len(t, params, 6)
 	assert.Equal(t, "60", params["DurationSeconds"][0])
@@ -41,7 +38,7 @@ func Test_getEcsTaskNetworkBlackholePortParameters(t *testing.T) {
 	assert.Equal(t, "True", params["InstallDependencies"][0])
 
 	var fisActionState fisActionState
-	err = json.Unmarshal([]byte(params[fisActionStateParameterName][0]), &fisActionState)
+	err = json.Unmarshal([]byte(params[FisActionStateParameterName][0]), &fisActionState)
 	assert.NoError(t, err)
 
 	assert.Equal(t, id.String(), fisActionState.Id)
@@ -57,6 +54,6 @@ func Test_getEcsTaskNetworkBlackholePortParameters_invalidDuration(t *testing.T)
 		},
 	}
 
-	_, err := getEcsTaskNetworkBlackholePortParameters(req)
+	_, err := NetworkBlackholePortParameters(req)
 	assert.Error(t, err)
 }
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extssm
+
+import (
+	"fmt"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"strings"
+	"time"
+)
+
+func NetworkDelayDescription(targetType string) action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.network_delay", targetType),
+		Label:       "Delay Outgoing Traffic",
+		Description: "Inject latency into egress network traffic.",
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("Duration of the attack."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("30s"),
+				Required:     new(true),
+				Order:        new(0),
+			},
+			{
+				Name:         "networkDelay",
+				Label:        "Network Delay",
+				Description:  new("How much should the traffic be delayed?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("500ms"),
+				Required:     new(true),
+				Order:        new(1),
+			},
+			{
+				Name:         "networkDelayJitter",
+				Label:        "Jitter",
+				Description:  new("Add random +/-30% jitter to network delay?"),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("false"),
+				Required:     new(true),
+				Order:        new(2),
+			},
+			{
+				Name:         "ip",
+				Label:        "IP Address/CIDR",
+				Description:  new("Restrict to which IP addresses, CIDR blocks or domain names the traffic is affected."),
+				Type:         action_kit_api.ActionParameterTypeStringArray,
+				DefaultValue: new(""),
+				Advanced:     new(true),
+				Order:        new(3),
+			},
+		},
+	}
+}
+
+func NetworkDelayParameters(request action_kit_api.PrepareActionRequestBody) (map[string][]string, error) {
+	fisActionStateParameter := NewFisActionStateParameter(request.ExecutionId.String())
+	delay := time.Duration(extutil.ToInt64(request.Config["networkDelay"])) * time.Millisecond
+	duration := time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond
+	if duration.Seconds() > 43200 {
+		return nil, fmt.Errorf("duration longer than 43200 seconds is not supported")
+	}
+	sources := strings.Join(extutil.ToStringArray(request.Config["ip"]), ",")
+	if sources == "" {
+		sources = "0.0.0.0/0"
+	}
+	if err := validateSourcesPattern(sources); err != nil {
+		return nil, err
+	}
+	jitter := 0 * time.Millisecond
+	if extutil.ToBool(request.Config["networkDelayJitter"]) {
+		jitter = delay * 30 / 100
+	}
+	//goland:noinspection GoDfaNilDereference
+	return map[string][]string{
+		"DurationSeconds":           {fmt.Sprintf("%d", int64(duration.Seconds()))},
+		"DelayMilliseconds":         {fmt.Sprintf("%d", delay.Milliseconds())},
+		"JitterMilliseconds":        {fmt.Sprintf("%d", jitter.Milliseconds())},
+		"Sources":                   {sources},
+		"InstallDependencies":       {"True"},
+		FisActionStateParameterName: {fisActionStateParameter},
+	}, nil
+}
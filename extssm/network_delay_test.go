@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: MIT
 // SPDX-FileCopyrightText: 2025 Steadybit GmbH
 
-package extecs
+package extssm
 
 import (
 	"encoding/json"
@@ -12,11 +12,8 @@ import (
 )
 
 func Test_NetworkLatency_Description(t *testing.T) {
-	action := NewEcsTaskNetworkDelayAction()
-	assert.NotNil(t, action)
-
-	describe := action.Describe()
-	assert.NotNil(t, describe)
+	description := NetworkDelayDescription("com.steadybit.extension_aws.ecs-task")
+	assert.Equal(t, "com.steadybit.extension_aws.ecs-task.network_delay", description.Id)
 }
 
 func Test_getEcsTaskNetworkDelayParameters(t *testing.T) {
@@ -31,7 +28,7 @@ func Test_getEcsTaskNetworkDelayParameters(t *testing.T) {
 		},
 	}
 
-	params, err := getEcsTaskNetworkDelayParameters(req)
+	params, err := NetworkDelayParameters(req)
 	assert.NoError(t, err)
 	assert.Len(t, params, 6)
 	assert.Equal(t, "60", params["DurationSeconds"][0])
@@ -41,7 +38,7 @@ func Test_getEcsTaskNetworkDelayParameters(t *testing.T) {
 	assert.Equal(t, "True", params["InstallDependencies"][0])
 
 	var fisActionState fisActionState
-	err = json.Unmarshal([]byte(params[fisActionStateParameterName][0]), &fisActionState)
+	err = json.Unmarshal([]byte(params[FisActionStateParameterName][0]), &fisActionState)
 	assert.NoError(t, err)
 
 	assert.Equal(t, id.String(), fisActionState.Id)
@@ -60,7 +57,7 @@ func Test_getEcsTaskNetworkDelayParameters_defaults(t *testing.T) {
 		},
 	}
 
-	params, err := getEcsTaskNetworkDelayParameters(req)
+	params, err := NetworkDelayParameters(req)
 	assert.NoError(t, err)
 	assert.Len(t, params, 6)
 	assert.Equal(t, "10", params["DurationSeconds"][0])
@@ -70,7 +67,7 @@ func Test_getEcsTaskNetworkDelayParameters_defaults(t *testing.T) {
 	assert.Equal(t, "True", params["InstallDependencies"][0])
 
 	var fisActionState fisActionState
-	err = json.Unmarshal([]byte(params[fisActionStateParameterName][0]), &fisActionState)
+	err = json.Unmarshal([]byte(params[FisActionStateParameterName][0]), &fisActionState)
 	assert.NoError(t, err)
 
 	assert.Equal(t, id.String(), fisActionState.Id)
@@ -89,7 +86,7 @@ func Test_getEcsTaskNetworkDelayParameters_invalidSources(t *testing.T) {
 		},
 	}
 
-	_, err := getEcsTaskNetworkDelayParameters(req)
+	_, err := NetworkDelayParameters(req)
 	assert.Error(t, err)
 }
 
@@ -101,6 +98,6 @@ func Test_getEcsTaskNetworkDelayParameters_invalidDuration(t *testing.T) {
 		},
 	}
 
-	_, err := getEcsTaskNetworkDelayParameters(req)
+	_, err := NetworkDelayParameters(req)
 	assert.Error(t, err)
 }
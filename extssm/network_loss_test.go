@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: MIT
 // SPDX-FileCopyrightText: 2025 Steadybit GmbH
 
-package extecs
+package extssm
 
 import (
 	"encoding/json"
@@ -12,11 +12,8 @@ import (
 )
 
 func Test_NetworkLoss_Description(t *testing.T) {
-	action := NewEcsTaskNetworkLossAction()
-	assert.NotNil(t, action)
-
-	describe := action.Describe()
-	assert.NotNil(t, describe)
+	description := NetworkLossDescription("com.steadybit.extension_aws.ecs-task")
+	assert.Equal(t, "com.steadybit.extension_aws.ecs-task.network_loss", description.Id)
 }
 
 func Test_getEcsTaskNetworkLossParameters(t *testing.T) {
@@ -30,7 +27,7 @@ func Test_getEcsTaskNetworkLossParameters(t *testing.T) {
 		},
 	}
 
-	params, err := getEcsTaskNetworkLossParameters(req)
+	params, err := NetworkLossParameters(req)
 	assert.NoError(t, err)
 	assert.Len(t, params, 5)
 	assert.Equal(t, "60", params["DurationSeconds"][0])
@@ -39,7 +36,7 @@ func Test_getEcsTaskNetworkLossParameters(t *testing.T) {
 	assert.Equal(t, "True", params["InstallDependencies"][0])
 
 	var fisActionState fisActionState
-	err = json.Unmarshal([]byte(params[fisActionStateParameterName][0]), &fisActionState)
+	err = json.Unmarshal([]byte(params[FisActionStateParameterName][0]), &fisActionState)
 	assert.NoError(t, err)
 
 	assert.Equal(t, id.String(), fisActionState.Id)
@@ -57,7 +54,7 @@ func Test_getEcsTaskNetworkLatencyParameters_defaults(t *testing.T) {
 		},
 	}
 
-	params, err := getEcsTaskNetworkLossParameters(req)
+	params, err := NetworkLossParameters(req)
 	assert.NoError(t, err)
 	assert.Len(t, params, 5)
 	assert.Equal(t, "10", params["DurationSeconds"][0])
@@ -66,7 +63,7 @@ func Test_getEcsTaskNetworkLatencyParameters_defaults(t *testing.T) {
 	assert.Equal(t, "True", params["InstallDependencies"][0])
 
 	var fisActionState fisActionState
-	err = json.Unmarshal([]byte(params[fisActionStateParameterName][0]), &fisActionState)
+	err = json.Unmarshal([]byte(params[FisActionStateParameterName][0]), &fisActionState)
 	assert.NoError(t, err)
 
 	assert.Equal(t, id.String(), fisActionState.Id)
@@ -84,7 +81,7 @@ func Test_getEcsTaskNetworkLatencyParameters_invalidSources(t *testing.T) {
 		},
 	}
 
-	_, err := getEcsTaskNetworkLossParameters(req)
+	_, err := NetworkLossParameters(req)
 	assert.Error(t, err)
 }
 
@@ -96,6 +93,6 @@ func Test_getEcsTaskNetworkLatencyParameters_invalidDuration(t *testing.T) {
 		},
 	}
 
-	_, err := getEcsTaskNetworkLossParameters(req)
+	_, err := NetworkLossParameters(req)
 	assert.Error(t, err)
 }
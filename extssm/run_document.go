@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extssm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// The Run SSM Document action runs a custom document, e.g. for application specific faults. The document keeps
+// running for the configured duration or ends earlier by itself. The optional rollback document runs on stop.
+
+func RunSsmDocumentDescription(targetType string) action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.run_ssm_document", targetType),
+		Label:       "Run SSM Document",
+		Description: "Runs a custom SSM document and optionally a rollback document at the end.",
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:        "documentName",
+				Label:       "Document Name",
+				Description: new("Name or ARN of the SSM document to run."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    new(true),
+				Order:       new(0),
+			},
+			{
+				Name:         "documentVersion",
+				Label:        "Document Version",
+				Description:  new("Version of the SSM document, e.g. $DEFAULT, $LATEST or a version number."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new("$DEFAULT"),
+				Required:     new(true),
+				Order:        new(1),
+			},
+			{
+				Name:         "parameters",
+				Label:        "Parameters",
+				Description:  new("Parameters of the SSM document as JSON object, e.g. {\"Path\": \"/var/cache/app\"}."),
+				Type:         action_kit_api.ActionParameterTypeTextarea,
+				DefaultValue: new("{}"),
+				Required:     new(true),
+				Order:        new(2),
+			},
+			{
+				Name:        "stepName",
+				Label:       "Output Step",
+				Description: new("Name of the document step whose output is reported. Leave empty for documents with a single step."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(3),
+			},
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long should the attack last?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("30s"),
+				Required:     new(true),
+				Order:        new(4),
+			},
+			{
+				Name:        "rollbackDocumentName",
+				Label:       "Rollback Document Name",
+				Description: new("Name or ARN of the SSM document to run when the attack ends. Leave empty to skip the rollback."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Advanced:    new(true),
+				Order:       new(5),
+			},
+			{
+				Name:         "rollbackDocumentVersion",
+				Label:        "Rollback Document Version",
+				Description:  new("Version of the rollback SSM document."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new("$DEFAULT"),
+				Advanced:     new(true),
+				Order:        new(6),
+			},
+			{
+				Name:         "rollbackParameters",
+				Label:        "Rollback Parameters",
+				Description:  new("Parameters of the rollback SSM document as JSON object."),
+				Type:         action_kit_api.ActionParameterTypeTextarea,
+				DefaultValue: new("{}"),
+				Advanced:     new(true),
+				Order:        new(7),
+			},
+		},
+	}
+}
+
+func RunSsmDocumentParameters(request action_kit_api.PrepareActionRequestBody) (map[string][]string, error) {
+	return parseSsmDocumentParameters(extutil.ToString(request.Config["parameters"]))
+}
+
+func RunSsmDocumentRun(request action_kit_api.PrepareActionRequestBody) (*DocumentRun, error) {
+	documentName := strings.TrimSpace(extutil.ToString(request.Config["documentName"]))
+	if documentName == "" {
+		return nil, fmt.Errorf("document name is required")
+	}
+	documentRun := &DocumentRun{
+		Document: Document{
+			Name:             documentName,
+			Version:          ssmDocumentVersion(request.Config["documentVersion"]),
+			StepNameToOutput: strings.TrimSpace(extutil.ToString(request.Config["stepName"])),
+		},
+	}
+
+	rollbackDocumentName := strings.TrimSpace(extutil.ToString(request.Config["rollbackDocumentName"]))
+	if rollbackDocumentName != "" {
+		rollbackParameters, err := parseSsmDocumentParameters(extutil.ToString(request.Config["rollbackParameters"]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rollback parameters: %w", err)
+		}
+		documentRun.Rollback = &Document{
+			Name:    rollbackDocumentName,
+			Version: ssmDocumentVersion(request.Config["rollbackDocumentVersion"]),
+		}
+		documentRun.RollbackParameters = rollbackParameters
+	}
+	return documentRun, nil
+}
+
+func ssmDocumentVersion(value any) string {
+	if version := strings.TrimSpace(extutil.ToString(value)); version != "" {
+		return version
+	}
+	return "$DEFAULT"
+}
+
+// parseSsmDocumentParameters parses a JSON object into SSM command parameters. Values may be strings, lists or other
+// scalars, e.g. {"Path": "/tmp", "Commands": ["a", "b"], "Count": 3}.
+func parseSsmDocumentParameters(value string) (map[string][]string, error) {
+	parameters := make(map[string][]string)
+	if strings.TrimSpace(value) == "" {
+		return parameters, nil
+	}
+
+	// Numbers are kept as written, as floats would format large integers like 1e+06.
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	var raw map[string]any
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("parameters must be a JSON object: %w", err)
+	}
+	for key, v := range raw {
+		if list, ok := v.([]any); ok {
+			values := make([]string, 0, len(list))
+			for _, item := range list {
+				values = append(values, ssmParameterValue(item))
+			}
+			parameters[key] = values
+		} else {
+			parameters[key] = []string{ssmParameterValue(v)}
+		}
+	}
+	return parameters, nil
+}
+
+func ssmParameterValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	case map[string]any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
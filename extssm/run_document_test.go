@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extssm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var runSsmDocumentAction = &ssmAction{
+	clientProvider: func(account string, region string, role *string) (Api, error) {
+		return &mockApi, nil
+	},
+	target: testSsmTarget,
+	invocation: CommandInvocation{
+		GetParameters:  RunSsmDocumentParameters,
+		GetDocumentRun: RunSsmDocumentRun,
+	},
+}
+
+func Test_RunSsmDocument_Description(t *testing.T) {
+	assert.Equal(t, "com.steadybit.extension_aws.ecs-task.run_ssm_document", RunSsmDocumentDescription("com.steadybit.extension_aws.ecs-task").Id)
+}
+
+func Test_parseSsmDocumentParameters(t *testing.T) {
+	params, err := parseSsmDocumentParameters(`{"Path": "/var/cache/app", "Commands": ["a", "b"], "Count": 3, "Force": true}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"Path":     {"/var/cache/app"},
+		"Commands": {"a", "b"},
+		"Count":    {"3"},
+		"Force":    {"true"},
+	}, params)
+
+	params, err = parseSsmDocumentParameters("")
+	require.NoError(t, err)
+	assert.Empty(t, params)
+
+	params, err = parseSsmDocumentParameters(`{"Count": 1000000, "Ratio": 0.5}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"Count": {"1000000"}, "Ratio": {"0.5"}}, params)
+
+	_, err = parseSsmDocumentParameters(`["a"]`)
+	assert.Error(t, err)
+}
+
+func Test_getSsmDocumentRun(t *testing.T) {
+	documentRun, err := RunSsmDocumentRun(action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Config: map[string]any{
+			"documentName":         "CorruptCacheFile",
+			"documentVersion":      "",
+			"stepName":             "Corrupt",
+			"rollbackDocumentName": "RestoreCacheFile",
+			"rollbackParameters":   `{"Path": "/var/cache/app"}`,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Document{Name: "CorruptCacheFile", Version: "$DEFAULT", StepNameToOutput: "Corrupt"}, documentRun.Document)
+	assert.Equal(t, &Document{Name: "RestoreCacheFile", Version: "$DEFAULT"}, documentRun.Rollback)
+	assert.Equal(t, map[string][]string{"Path": {"/var/cache/app"}}, documentRun.RollbackParameters)
+
+	documentRun, err = RunSsmDocumentRun(action_kit_api.PrepareActionRequestBody{Config: map[string]any{"documentName": "CorruptCacheFile"}})
+	require.NoError(t, err)
+	assert.Nil(t, documentRun.Rollback)
+
+	_, err = RunSsmDocumentRun(action_kit_api.PrepareActionRequestBody{Config: map[string]any{"documentName": " "}})
+	assert.Error(t, err)
+}
+
+func Test_runSsmDocument_StatusLastsForDuration(t *testing.T) {
+	mockApi.ExpectedCalls = nil
+	mockApi.On("GetCommandInvocation", mock.Anything, mock.Anything, mock.Anything).Return(&ssm.GetCommandInvocationOutput{Status: types.CommandInvocationStatusSuccess}, nil)
+
+	state := ActionState{
+		CommandId:         "command-0",
+		ManagedInstanceId: "mi-0",
+		DocumentRun:       &DocumentRun{Document: Document{Name: "CorruptCacheFile", Version: "$DEFAULT"}, End: time.Now().Add(time.Minute)},
+	}
+	result, err := runSsmDocumentAction.Status(context.Background(), &state)
+	require.NoError(t, err)
+	assert.False(t, result.Completed)
+	assert.Nil(t, result.Error)
+	assert.True(t, state.CommandEnded)
+	assert.NotEmpty(t, *result.Messages)
+
+	state.DocumentRun.End = time.Now()
+	result, err = runSsmDocumentAction.Status(context.Background(), &state)
+	require.NoError(t, err)
+	assert.True(t, result.Completed)
+	mockApi.AssertNumberOfCalls(t, "GetCommandInvocation", 2)
+}
+
+func Test_runSsmDocument_StopCancelsCommandOutlastingDuration(t *testing.T) {
+	mockApi.ExpectedCalls = nil
+	mockApi.Calls = nil
+	mockApi.On("GetCommandInvocation", mock.Anything, mock.Anything, mock.Anything).Return(&ssm.GetCommandInvocationOutput{Status: types.CommandInvocationStatusInProgress}, nil).Once()
+	mockApi.On("CancelCommand", mock.Anything, mock.Anything, mock.Anything).Return(&ssm.CancelCommandOutput{}, nil)
+	mockApi.On("GetCommandInvocation", mock.Anything, mock.Anything, mock.Anything).Return(&ssm.GetCommandInvocationOutput{Status: types.CommandInvocationStatusCancelled}, nil)
+
+	state := ActionState{
+		CommandId:         "command-0",
+		ManagedInstanceId: "mi-0",
+		DocumentRun:       &DocumentRun{Document: Document{Name: "CorruptCacheFile", Version: "$DEFAULT"}, End: time.Now()},
+	}
+	result, err := runSsmDocumentAction.Status(context.Background(), &state)
+	require.NoError(t, err)
+	assert.True(t, result.Completed)
+	assert.False(t, state.CommandEnded)
+
+	stopResult, err := runSsmDocumentAction.Stop(context.Background(), &state)
+	require.NoError(t, err)
+	require.NotNil(t, stopResult)
+	mockApi.AssertCalled(t, "CancelCommand", mock.Anything, mock.MatchedBy(func(params *ssm.CancelCommandInput) bool {
+		return *params.CommandId == "command-0"
+	}), mock.Anything)
+}
+
+func Test_runSsmDocument_StopRunsRollback(t *testing.T) {
+	mockApi.ExpectedCalls = nil
+	mockApi.Calls = nil
+	mockApi.On("SendCommand", mock.Anything, mock.MatchedBy(func(params *ssm.SendCommandInput) bool {
+		return *params.DocumentName == "RestoreCacheFile" && params.Parameters["Path"][0] == "/var/cache/app"
+	}), mock.Anything).Return(&ssm.SendCommandOutput{Command: &types.Command{CommandId: new("command-1")}}, nil)
+	mockApi.On("GetCommandInvocation", mock.Anything, mock.Anything, mock.Anything).Return(&ssm.GetCommandInvocationOutput{Status: types.CommandInvocationStatusSuccess}, nil)
+
+	state := ActionState{
+		CommandId:         "command-0",
+		CommandEnded:      true,
+		ManagedInstanceId: "mi-0",
+		DocumentRun: &DocumentRun{
+			Document:           Document{Name: "CorruptCacheFile", Version: "$DEFAULT"},
+			Rollback:           &Document{Name: "RestoreCacheFile", Version: "$DEFAULT"},
+			RollbackParameters: map[string][]string{"Path": {"/var/cache/app"}},
+		},
+	}
+	result, err := runSsmDocumentAction.Stop(context.Background(), &state)
+	require.NoError(t, err)
+	assert.Nil(t, result.Error)
+	mockApi.AssertNotCalled(t, "CancelCommand", mock.Anything, mock.Anything, mock.Anything)
+	mockApi.AssertCalled(t, "SendCommand", mock.Anything, mock.Anything, mock.Anything)
+	assert.Contains(t, (*result.Messages)[0].Message, "command-1")
+}
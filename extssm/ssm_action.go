@@ -0,0 +1,555 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extssm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-aws/v2/utils"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type HeartbeatConfig struct {
+	Duration time.Duration
+	Timeout  time.Duration
+}
+
+const (
+	defaultHeartbeatDuration = 30 * time.Second
+	defaultHeartbeatTimeout  = 15 * time.Second
+)
+
+type ssmAction struct {
+	clientProvider func(account string, region string, role *string) (Api, error)
+	description    action_kit_api.ActionDescription
+	target         Target
+	invocation     CommandInvocation
+	heartbeat      HeartbeatConfig
+}
+
+// Target maps the targets of an SSM action to the managed instance executing the command.
+type Target struct {
+	// Label names the target type in messages, e.g. "ECS Task".
+	Label string
+	// ReadTarget stores the attributes of the target needed to find the managed instance.
+	ReadTarget func(state *ActionState, target *action_kit_api.Target)
+	// TargetId returns the id of the target stored in the state.
+	TargetId            func(state *ActionState) string
+	FindManagedInstance func(ctx context.Context, client Api, state *ActionState) (string, error)
+	// NotFoundDetail explains how to make the target a managed instance.
+	NotFoundDetail func(target *action_kit_api.Target) string
+}
+
+var (
+	// Make sure ssmAction implements all required interfaces
+	_ action_kit_sdk.ActionWithStop[ActionState]   = (*ssmAction)(nil)
+	_ action_kit_sdk.ActionWithStatus[ActionState] = (*ssmAction)(nil)
+
+	ErrManagedInstanceNotFound    = errors.New("managed instance not found")
+	errorManagedInstanceAmbiguous = errors.New("found multiple managed instances")
+	maxWaitForOutput              = 10 * time.Second
+	maxWaitForRollback            = 60 * time.Second
+)
+
+type ActionState struct {
+	ExecutionId       uuid.UUID
+	Duration          time.Duration
+	Account           string
+	Region            string
+	DiscoveredByRole  *string
+	TaskArn           string
+	InstanceId        string
+	ManagedInstanceId string
+	CommandId         string
+	Parameters        map[string][]string
+	Comment           string
+	CommandEnded      bool
+	DocumentRun       *DocumentRun
+}
+
+// Document references an SSM document and the step whose output is reported.
+type Document struct {
+	Name             string
+	Version          string
+	StepNameToOutput string
+}
+
+// DocumentRun holds the documents chosen for the Run SSM Document action, which replace the document of the
+// invocation.
+type DocumentRun struct {
+	Document           Document
+	Rollback           *Document
+	RollbackParameters map[string][]string
+	End                time.Time
+}
+
+type Api interface {
+	SendCommand(ctx context.Context, params *ssm.SendCommandInput, optFns ...func(*ssm.Options)) (*ssm.SendCommandOutput, error)
+	CancelCommand(ctx context.Context, params *ssm.CancelCommandInput, optFns ...func(*ssm.Options)) (*ssm.CancelCommandOutput, error)
+	ssm.DescribeInstanceInformationAPIClient
+	ssm.GetCommandInvocationAPIClient
+}
+
+// CommandInvocation describes the SSM document run by an action.
+type CommandInvocation struct {
+	DocumentVersion           string
+	DocumentName              string
+	GetParameters             func(action_kit_api.PrepareActionRequestBody) (map[string][]string, error)
+	UpdateHeartbeatParameters *func(map[string][]string) error
+	StepNameToOutput          string
+	// GetDocumentRun optionally returns the documents chosen by the user.
+	GetDocumentRun func(action_kit_api.PrepareActionRequestBody) (*DocumentRun, error)
+}
+
+var (
+	heartbeatsMutex sync.Mutex
+	heartbeats      = make(map[uuid.UUID]func())
+)
+
+// NewAction creates an action running an SSM document on the managed instance of the target.
+func NewAction(description action_kit_api.ActionDescription, target Target, invocation CommandInvocation) action_kit_sdk.ActionWithStop[ActionState] {
+	description.Version = extbuild.GetSemverVersionStringOrUnknown()
+	description.Technology = new("AWS")
+	description.Kind = action_kit_api.Attack
+	description.TimeControl = action_kit_api.TimeControlInternal
+	description.Status = &action_kit_api.MutatingEndpointReferenceWithCallInterval{
+		CallInterval: new("5s"),
+	}
+	return &ssmAction{
+		clientProvider: defaultClientProvider,
+		description:    description,
+		target:         target,
+		invocation:     invocation,
+		heartbeat: HeartbeatConfig{
+			Duration: defaultHeartbeatDuration,
+			Timeout:  defaultHeartbeatTimeout,
+		},
+	}
+}
+
+func (e *ssmAction) NewEmptyState() ActionState {
+	return ActionState{}
+}
+
+func (e *ssmAction) Describe() action_kit_api.ActionDescription {
+	return e.description
+}
+
+func (e *ssmAction) RequiredPermissions() []string {
+	return []string{
+		"ssm:DescribeInstanceInformation",
+		"ssm:SendCommand",
+		"ssm:GetCommandInvocation",
+		"ssm:CancelCommand",
+	}
+}
+
+func (e *ssmAction) Prepare(ctx context.Context, state *ActionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.ExecutionId = request.ExecutionId
+	state.Duration = time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond
+	state.Account = extutil.MustHaveValue(request.Target.Attributes, "aws.account")[0]
+	state.Region = extutil.MustHaveValue(request.Target.Attributes, "aws.region")[0]
+	state.DiscoveredByRole = utils.GetOptionalTargetAttribute(request.Target.Attributes, "extension-aws.discovered-by-role")
+	e.target.ReadTarget(state, request.Target)
+
+	if parameters, err := e.invocation.GetParameters(request); err == nil {
+		state.Parameters = parameters
+	} else {
+		return nil, err
+	}
+
+	if e.invocation.GetDocumentRun != nil {
+		documentRun, err := e.invocation.GetDocumentRun(request)
+		if err != nil {
+			return nil, err
+		}
+		state.DocumentRun = documentRun
+	}
+
+	if request.ExecutionContext != nil && request.ExecutionContext.ExecutionId != nil && request.ExecutionContext.ExperimentKey != nil {
+		state.Comment = fmt.Sprintf("Steadybit Experiment %s #%d", *request.ExecutionContext.ExperimentKey, *request.ExecutionContext.ExecutionId)
+	} else {
+		state.Comment = "Steadybit Experiment"
+	}
+
+	client, err := e.clientProvider(state.Account, state.Region, state.DiscoveredByRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if managedInstanceId, err := e.target.FindManagedInstance(ctx, client, state); err == nil {
+		state.ManagedInstanceId = managedInstanceId
+	} else {
+		prepareErr := extension_kit.ToError(fmt.Sprintf("Failed to find managed instance for %s", e.targetName(state)), err)
+
+		if errors.Is(err, ErrManagedInstanceNotFound) {
+			prepareErr.Detail = new(e.target.NotFoundDetail(request.Target))
+		}
+		return nil, prepareErr
+	}
+
+	return nil, nil
+}
+
+func (e *ssmAction) Start(ctx context.Context, state *ActionState) (*action_kit_api.StartResult, error) {
+	client, err := e.clientProvider(state.Account, state.Region, state.DiscoveredByRole)
+	if err != nil {
+		return nil, err
+	}
+
+	document := e.document(state)
+	output, err := client.SendCommand(ctx, &ssm.SendCommandInput{
+		DocumentName:    &document.Name,
+		DocumentVersion: &document.Version,
+		InstanceIds:     []string{state.ManagedInstanceId},
+		Parameters:      state.Parameters,
+		Comment:         new(shorten(state.Comment, 100)),
+		TimeoutSeconds:  new(int32(30)),
+	}, func(options *ssm.Options) {
+		options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+	})
+
+	result := &action_kit_api.StartResult{Messages: &[]action_kit_api.Message{}}
+	if err == nil {
+		e.startHeartbeat(state)
+		state.CommandId = *output.Command.CommandId
+		if state.DocumentRun != nil {
+			state.DocumentRun.End = time.Now().Add(state.Duration)
+		}
+		result.Messages = utils.AppendInfof(result.Messages, "Sent SSM command (%s) on %s using document %s(%s) parameters %+v", state.CommandId, e.targetName(state), document.Name, document.Version, state.Parameters)
+	} else {
+		result.Error = &action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Failed to start %s on %s", e.description.Label, e.targetName(state)),
+			Detail: new(fmt.Sprintf("Sending SSM command on %s failed. Using document %s(%s) and parameters %+v: %s", e.targetName(state), document.Name, document.Version, state.Parameters, err.Error())),
+		}
+	}
+
+	return result, nil
+}
+
+// document returns the document to run, which is chosen by the user for the Run SSM Document action.
+func (e *ssmAction) document(state *ActionState) Document {
+	if state.DocumentRun != nil {
+		return state.DocumentRun.Document
+	}
+	return Document{
+		Name:             e.invocation.DocumentName,
+		Version:          e.invocation.DocumentVersion,
+		StepNameToOutput: e.invocation.StepNameToOutput,
+	}
+}
+
+// targetName names the attacked target in messages, e.g. "ECS Task arn:aws:ecs:...".
+func (e *ssmAction) targetName(state *ActionState) string {
+	return fmt.Sprintf("%s %s", e.target.Label, e.target.TargetId(state))
+}
+
+func shorten(s string, i int) string {
+	if len(s) > i {
+		return s[:i]
+	}
+	return s
+}
+
+func (e *ssmAction) Status(ctx context.Context, state *ActionState) (*action_kit_api.StatusResult, error) {
+	// The Run SSM Document action lasts for the configured duration, so that the rollback document runs at its end.
+	if state.CommandEnded && state.DocumentRun != nil {
+		return &action_kit_api.StatusResult{Completed: !time.Now().Before(state.DocumentRun.End)}, nil
+	}
+
+	client, err := e.clientProvider(state.Account, state.Region, state.DiscoveredByRole)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.GetCommandInvocation(ctx, &ssm.GetCommandInvocationInput{CommandId: &state.CommandId, InstanceId: &state.ManagedInstanceId})
+	if err != nil {
+		if isErrInvocationDoesNotExist(err) {
+			return nil, nil
+		} else {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed get status for %s on %s", e.description.Label, e.targetName(state)), err)
+		}
+	}
+
+	if hasEnded(output) {
+		state.CommandEnded = true
+		rMsg, rErr := e.evaluateResultForCommand(ctx, client, state, state.CommandId, e.document(state), output)
+		completed := rErr != nil || state.DocumentRun == nil || !time.Now().Before(state.DocumentRun.End)
+		return &action_kit_api.StatusResult{Completed: completed, Messages: rMsg, Error: rErr}, nil
+	}
+
+	// A document outlasting the duration is cancelled on stop.
+	if state.DocumentRun != nil && !time.Now().Before(state.DocumentRun.End) {
+		return &action_kit_api.StatusResult{
+			Completed: true,
+			Messages:  utils.AppendInfof(nil, "SSM command (%s) on %s is still running at the end of the attack", state.CommandId, e.targetName(state)),
+		}, nil
+	}
+
+	//As the command will be stuck "InProgress" if the executing managed instance has vanished, we need to check if it still there, so we don't wait on the command timeout.
+	if _, err := e.target.FindManagedInstance(ctx, client, state); err != nil {
+		if errors.Is(err, ErrManagedInstanceNotFound) {
+			return nil, extension_kit.ToError(fmt.Sprintf("Managed instance for %s on %s went offline during attack.", e.description.Label, e.targetName(state)), err)
+		} else {
+			return nil, extension_kit.ToError(fmt.Sprintf("Check on managed instance for %s on %s failed", e.description.Label, e.targetName(state)), err)
+		}
+	}
+
+	return nil, nil
+}
+
+func (e *ssmAction) Stop(ctx context.Context, state *ActionState) (*action_kit_api.StopResult, error) {
+	e.stopHeartbeat(state)
+
+	hasRollback := state.DocumentRun != nil && state.DocumentRun.Rollback != nil
+	if state.CommandId == "" || (state.CommandEnded && !hasRollback) {
+		return nil, nil
+	}
+
+	client, err := e.clientProvider(state.Account, state.Region, state.DiscoveredByRole)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &action_kit_api.StopResult{Messages: &[]action_kit_api.Message{}}
+	if !state.CommandEnded {
+		result.Messages = utils.AppendInfof(result.Messages, "Cancelling SSM command (%s) for %s on %s", state.CommandId, e.description.Label, e.targetName(state))
+
+		if _, err := client.CancelCommand(ctx, &ssm.CancelCommandInput{CommandId: &state.CommandId, InstanceIds: []string{state.ManagedInstanceId}}); err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to cancel SSM command (%s) for %s on %s", state.CommandId, e.description.Label, e.targetName(state)), err)
+		}
+
+		output, err := ssm.NewCommandExecutedWaiter(client, withCommandStatusRetryable()).WaitForOutput(ctx, &ssm.GetCommandInvocationInput{CommandId: &state.CommandId, InstanceId: &state.ManagedInstanceId}, maxWaitForOutput)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to await end of %s on %s", e.description.Label, e.targetName(state)), err)
+		}
+
+		rMsg, rErr := e.evaluateResultForCommand(ctx, client, state, state.CommandId, e.document(state), output)
+		result.Messages = new(append(*result.Messages, *rMsg...))
+		result.Error = rErr
+	}
+
+	if hasRollback {
+		rMsg, rErr := e.runRollback(ctx, client, state)
+		result.Messages = new(append(*result.Messages, *rMsg...))
+		if result.Error == nil {
+			result.Error = rErr
+		}
+	}
+	return result, nil
+}
+
+// runRollback runs the rollback document of the Run SSM Document action and awaits its end.
+func (e *ssmAction) runRollback(ctx context.Context, client Api, state *ActionState) (*action_kit_api.Messages, *action_kit_api.ActionKitError) {
+	rollback := *state.DocumentRun.Rollback
+	output, err := client.SendCommand(ctx, &ssm.SendCommandInput{
+		DocumentName:    &rollback.Name,
+		DocumentVersion: &rollback.Version,
+		InstanceIds:     []string{state.ManagedInstanceId},
+		Parameters:      state.DocumentRun.RollbackParameters,
+		Comment:         new(shorten(state.Comment+" rollback", 100)),
+		TimeoutSeconds:  new(int32(30)),
+	})
+	if err != nil {
+		return &[]action_kit_api.Message{}, &action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Failed to roll back %s on %s", e.description.Label, e.targetName(state)),
+			Detail: new(fmt.Sprintf("Sending SSM command using rollback document %s(%s) failed: %s", rollback.Name, rollback.Version, err.Error())),
+		}
+	}
+
+	commandId := aws.ToString(output.Command.CommandId)
+	messages := utils.AppendInfof(nil, "Sent rollback SSM command (%s) on %s using document %s(%s) parameters %+v", commandId, e.targetName(state), rollback.Name, rollback.Version, state.DocumentRun.RollbackParameters)
+	invocation, err := ssm.NewCommandExecutedWaiter(client, withCommandStatusRetryable()).WaitForOutput(ctx, &ssm.GetCommandInvocationInput{CommandId: &commandId, InstanceId: &state.ManagedInstanceId}, maxWaitForRollback)
+	if err != nil {
+		return messages, &action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Failed to await rollback of %s on %s", e.description.Label, e.targetName(state)),
+			Detail: new(err.Error()),
+		}
+	}
+	rMsg, rErr := e.evaluateResultForCommand(ctx, client, state, commandId, rollback, invocation)
+	return new(append(*messages, *rMsg...)), rErr
+}
+
+func (e *ssmAction) evaluateResultForCommand(ctx context.Context, client Api, state *ActionState, commandId string, document Document, output *ssm.GetCommandInvocationOutput) (*action_kit_api.Messages, *action_kit_api.ActionKitError) {
+	status := string(output.Status)
+	if output.StatusDetails != nil {
+		status = *output.StatusDetails
+	}
+	messages := utils.AppendInfof(nil, "SSM command (%s) using document %s(%s) ended with rc=%d and status %s", commandId, document.Name, document.Version, output.ResponseCode, status)
+
+	stepInput := &ssm.GetCommandInvocationInput{CommandId: &commandId, InstanceId: &state.ManagedInstanceId}
+	if document.StepNameToOutput != "" {
+		stepInput.PluginName = &document.StepNameToOutput
+	}
+	stepOutput, err := client.GetCommandInvocation(ctx, stepInput)
+	if err == nil {
+		if stepOutput.StandardOutputContent != nil {
+			messages = utils.AppendInfof(messages, "%s stdout:\n%s", commandId, *stepOutput.StandardOutputContent)
+		}
+		if stepOutput.StandardErrorContent != nil {
+			messages = utils.AppendInfof(messages, "%s stderr:\n%s", commandId, *stepOutput.StandardErrorContent)
+		}
+	} else {
+		messages = utils.AppendWarnf(messages, "Failed to read output for step %s: %v", document.StepNameToOutput, err)
+	}
+
+	var resultError *action_kit_api.ActionKitError
+	if output.Status != types.CommandInvocationStatusSuccess {
+		resultError = &action_kit_api.ActionKitError{
+			Title: fmt.Sprintf("Ended SSM command %s on %s with status %s", e.description.Label, e.targetName(state), status),
+		}
+		if stepOutput != nil && stepOutput.StandardOutputContent != nil {
+			if strings.Contains(*stepOutput.StandardOutputContent, "Another stress-ng command is running, exiting...") {
+				resultError.Detail = new("Parallel stress attack already running on this instance")
+			} else {
+				resultError.Detail = new(*stepOutput.StandardOutputContent)
+			}
+		}
+	}
+
+	return messages, resultError
+}
+
+func FindSingleManagedInstance(ctx context.Context, client Api, filter types.InstanceInformationStringFilter) (string, error) {
+	output, err := client.DescribeInstanceInformation(ctx, &ssm.DescribeInstanceInformationInput{
+		Filters: []types.InstanceInformationStringFilter{filter},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(output.InstanceInformationList) == 1 && output.InstanceInformationList[0].InstanceId != nil {
+		return *output.InstanceInformationList[0].InstanceId, nil
+	} else if len(output.InstanceInformationList) > 1 {
+		return "", errorManagedInstanceAmbiguous
+	} else {
+		return "", ErrManagedInstanceNotFound
+	}
+}
+
+func (e *ssmAction) startHeartbeat(state *ActionState) {
+	if e.invocation.UpdateHeartbeatParameters == nil {
+		return
+	}
+
+	heartbeatCtx, heartbeatCancel := context.WithTimeout(context.Background(), e.heartbeat.Timeout)
+	heartbeatsMutex.Lock()
+	heartbeats[state.ExecutionId] = heartbeatCancel
+	heartbeatsMutex.Unlock()
+
+	go func(parameters map[string][]string, ctx context.Context, cancel func()) {
+		client, err := e.clientProvider(state.Account, state.Region, state.DiscoveredByRole)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to create heartbeat client for %s", e.targetName(state))
+			return
+		}
+
+		ticker := time.NewTicker(e.heartbeat.Duration)
+		defer ticker.Stop()
+		defer cancel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msgf("Heartbeat stopped on %s", e.targetName(state))
+				return
+			case <-ticker.C:
+				log.Info().Msgf("Sending heartbeat to %s", e.targetName(state))
+
+				if err := (*e.invocation.UpdateHeartbeatParameters)(parameters); err != nil {
+					log.Warn().Err(err).Msgf("Failed to update heartbeat parameters for %s", e.targetName(state))
+					return
+				}
+				document := e.document(state)
+				_, err = client.SendCommand(ctx, &ssm.SendCommandInput{
+					DocumentName:    &document.Name,
+					DocumentVersion: &document.Version,
+					InstanceIds:     []string{state.ManagedInstanceId},
+					Parameters:      parameters,
+					Comment:         new(shorten(state.Comment+" heartbeat", 100)),
+					TimeoutSeconds:  new(int32(30)),
+				})
+				if err != nil {
+					log.Warn().Err(err).Msgf("Failed to send heartbeat command to %s", e.targetName(state))
+				}
+			}
+		}
+	}(state.Parameters, heartbeatCtx, heartbeatCancel)
+}
+
+func (e *ssmAction) stopHeartbeat(state *ActionState) {
+	heartbeatsMutex.Lock()
+	cancel, ok := heartbeats[state.ExecutionId]
+	if ok {
+		delete(heartbeats, state.ExecutionId)
+	}
+	heartbeatsMutex.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func hasEnded(output *ssm.GetCommandInvocationOutput) bool {
+	return output != nil && (output.Status == types.CommandInvocationStatusSuccess || output.Status == types.CommandInvocationStatusFailed || output.Status == types.CommandInvocationStatusTimedOut || output.Status == types.CommandInvocationStatusCancelled)
+}
+
+func withCommandStatusRetryable() func(options *ssm.CommandExecutedWaiterOptions) {
+	return func(options *ssm.CommandExecutedWaiterOptions) {
+		options.Retryable = func(ctx context.Context, input *ssm.GetCommandInvocationInput, output *ssm.GetCommandInvocationOutput, err error) (bool, error) {
+			if err != nil {
+				if isErrInvocationDoesNotExist(err) {
+					return true, nil
+				} else if isErrInvalidPluginName(err) {
+					return false, nil
+				}
+			}
+			return !hasEnded(output), nil
+		}
+	}
+}
+
+func isErrInvocationDoesNotExist(err error) bool {
+	var errorType *types.InvocationDoesNotExist
+	return errors.As(err, &errorType)
+}
+
+func isErrInvalidPluginName(err error) bool {
+	var errorType *types.InvalidPluginName
+	return errors.As(err, &errorType)
+}
+
+func defaultClientProvider(account string, region string, role *string) (Api, error) {
+	awsAccess, err := utils.GetAwsAccess(account, region, role)
+	if err != nil {
+		return nil, err
+	}
+	return ssm.NewFromConfig(awsAccess.AwsConfig), nil
+}
+
+func validateSourcesPattern(sources string) error {
+	pattern := `^[0-9a-zA-Z./,-]+$`
+	matched, err := regexp.MatchString(pattern, sources)
+	if err != nil {
+		return fmt.Errorf("failed to validate sources: %w", err)
+	}
+	if !matched {
+		return fmt.Errorf("invalid sources: %q must match %q", sources, pattern)
+	}
+	return nil
+}
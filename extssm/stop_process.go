@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extssm
+
+import (
+	"fmt"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"regexp"
+)
+
+func StopProcessDescription(targetType string) action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.stop-process", targetType),
+		Label:       "Stop Process",
+		Description: "Stop a particular process by name in an instance. ",
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:        "process",
+				Label:       "Process",
+				Description: new("Name of the process to stop."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    new(true),
+				Order:       new(0),
+			},
+			{
+				Name:         "graceful",
+				Label:        "Graceful",
+				Description:  new("If true a TERM signal is sent, otherwise the KILL signal."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("true"),
+				Required:     new(true),
+				Order:        new(1),
+			},
+		},
+	}
+}
+
+func StopProcessParameters(request action_kit_api.PrepareActionRequestBody) (map[string][]string, error) {
+	process := extutil.ToString(request.Config["process"])
+	pattern := `^[0-9a-zA-Z.\-=_]{1,128}$`
+	matched, err := regexp.MatchString(pattern, process)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate process name: %w", err)
+	}
+	if !matched {
+		return nil, fmt.Errorf("invalid process name: must match %q", pattern)
+	}
+	var signal = "SIGKILL"
+	if extutil.ToBool(request.Config["graceful"]) {
+		signal = "SIGTERM"
+	}
+	return map[string][]string{
+		"ProcessName":         {process},
+		"Signal":              {signal},
+		"InstallDependencies": {"True"},
+	}, nil
+}
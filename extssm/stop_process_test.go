@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: MIT
 // SPDX-FileCopyrightText: 2025 Steadybit GmbH
 
-package extecs
+package extssm
 
 import (
 	"github.com/google/uuid"
@@ -11,11 +11,8 @@ import (
 )
 
 func Test_StopProcess_Description(t *testing.T) {
-	action := NewEcsTaskStopProcessAction()
-	assert.NotNil(t, action)
-
-	describe := action.Describe()
-	assert.NotNil(t, describe)
+	description := StopProcessDescription("com.steadybit.extension_aws.ecs-task")
+	assert.Equal(t, "com.steadybit.extension_aws.ecs-task.stop-process", description.Id)
 }
 
 func Test_getEcsTaskStopProcessParameters(t *testing.T) {
@@ -27,7 +24,7 @@ func Test_getEcsTaskStopProcessParameters(t *testing.T) {
 		},
 	}
 
-	params, err := getEcsTaskStopProcessParameters(req)
+	params, err := StopProcessParameters(req)
 	assert.NoError(t, err)
 	assert.Len(t, params, 3)
 	assert.Equal(t, "foo", params["ProcessName"][0])
@@ -44,7 +41,7 @@ func Test_getEcsTaskStopProcessParameters_defaults(t *testing.T) {
 		},
 	}
 
-	params, err := getEcsTaskStopProcessParameters(req)
+	params, err := StopProcessParameters(req)
 	assert.NoError(t, err)
 	assert.Len(t, params, 3)
 	assert.Equal(t, "foo", params["ProcessName"][0])
@@ -61,6 +58,6 @@ func Test_getEcsTaskStopProcessParameters_invalidProcessName(t *testing.T) {
 		},
 	}
 
-	_, err := getEcsTaskStopProcessParameters(req)
+	_, err := StopProcessParameters(req)
 	assert.Error(t, err)
 }
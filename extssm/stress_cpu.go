@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2024 Steadybit GmbH
+
+/*
+ * Copyright 2024 steadybit GmbH. All rights reserved.
+ */
+
+package extssm
+
+import (
+	"fmt"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"strconv"
+	"time"
+)
+
+func StressCpuParameters(request action_kit_api.PrepareActionRequestBody) (map[string][]string, error) {
+	duration := time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond
+
+	return map[string][]string{
+		"DurationSeconds": {fmt.Sprintf("%d", int64(duration.Seconds()))},
+		"CPU":             {strconv.Itoa(extutil.ToInt(request.Config["workers"]))},
+		"LoadPercent":     {strconv.Itoa(extutil.ToInt(request.Config["cpuLoad"]))},
+	}, nil
+}
+
+func StressCpuDescription(targetType string) action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.stress_cpu", targetType),
+		Label:       "Stress CPU",
+		Description: "Stresses CPU for the given duration.",
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "cpuLoad",
+				Label:        "Load on Container CPU",
+				Description:  new("How much CPU load should be inflicted?"),
+				Type:         action_kit_api.ActionParameterTypePercentage,
+				DefaultValue: new("100"),
+				Required:     new(true),
+				Order:        new(0),
+				MinValue:     new(1),
+				MaxValue:     new(100),
+			},
+			{
+				Name:         "workers",
+				Label:        "Container CPUs",
+				Description:  new("How many workers should be used to stress the CPU?"),
+				Type:         action_kit_api.ActionParameterTypeStressngWorkers,
+				DefaultValue: new("0"),
+				Required:     new(true),
+				Order:        new(1),
+			},
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long should the CPU be stressed?"),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("30s"),
+				Required:     new(true),
+				Order:        new(2),
+			},
+		},
+	}
+}
@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: MIT
 // SPDX-FileCopyrightText: 2024 Steadybit GmbH
 
-package extecs
+package extssm
 
 import (
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
@@ -18,7 +18,7 @@ func Test_getEcsTaskStressMemoryParameters(t *testing.T) {
 		},
 	}
 
-	params, err := getEcsTaskStressMemoryParameters(req)
+	params, err := StressMemoryParameters(req)
 	assert.NoError(t, err)
 	assert.Equal(t, map[string][]string{
 		"DurationSeconds": {"60"},
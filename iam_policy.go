@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
+	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-aws/v2/extec2"
+	"github.com/steadybit/extension-aws/v2/utils"
+)
+
+// IamPolicies are the IAM policies printed by the iam-policy command.
+type IamPolicies struct {
+	// Policy grants all IAM actions needed by the enabled discoveries and actions.
+	Policy utils.IamPolicy `json:"policy"`
+	// DiscoveryPolicy only grants the read-only IAM actions needed by the enabled discoveries.
+	DiscoveryPolicy utils.IamPolicy `json:"discoveryPolicy"`
+	// ExtensionPolicy grants the IAM actions the extension calls with its own credentials, e.g. to assume the roles of
+	// the accounts. Only present if the configuration needs any.
+	ExtensionPolicy *utils.IamPolicy `json:"extensionPolicy,omitempty"`
+}
+
+// policyRegistry collects the IAM actions of the enabled discoveries and actions without creating the discoveries.
+type policyRegistry struct {
+	discoveryPermissions []string
+	actionPermissions    []string
+}
+
+func (r *policyRegistry) discovery(_ func(ctx context.Context) discovery_kit_sdk.TargetDiscovery, permissions []string) {
+	r.discoveryPermissions = append(r.discoveryPermissions, permissions...)
+}
+
+func (r *policyRegistry) action(action actionHandler) {
+	r.actionPermissions = append(r.actionPermissions, action.permissions...)
+}
+
+func (r *policyRegistry) start(_ func(ctx context.Context)) {
+}
+
+// printIamPolicies prints the least-privilege IAM policies for the current configuration to stdout.
+func printIamPolicies() {
+	region := ""
+	if awsConfig, err := awsconfig.LoadDefaultConfig(context.Background()); err == nil {
+		region = awsConfig.Region
+	}
+	config.ParseConfiguration(region)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newIamPolicies(*config.Config())); err != nil {
+		log.Fatal().Err(err).Msg("Failed to print the IAM policies")
+	}
+}
+
+func newIamPolicies(cfg config.Specification) IamPolicies {
+	registry := &policyRegistry{}
+	registerTargetHandlers(cfg, registry)
+
+	discoveryPermissions := slices.Concat(extec2.Ec2UtilPermissions, utils.TagCachePermissions, registry.discoveryPermissions)
+	policies := IamPolicies{
+		Policy:          utils.NewIamPolicy("SteadybitExtensionAws", slices.Concat(discoveryPermissions, utils.DryRunPermissions, registry.actionPermissions)),
+		DiscoveryPolicy: utils.NewIamPolicy("SteadybitExtensionAwsDiscovery", discoveryPermissions),
+	}
+	if extensionPermissions := utils.ExtensionPermissions(cfg); len(extensionPermissions) > 0 {
+		policies.ExtensionPolicy = new(utils.NewIamPolicy("SteadybitExtensionAwsOwnCredentials", extensionPermissions))
+	}
+	return policies
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newIamPolicies(t *testing.T) {
+	policies := newIamPolicies(createConfig(false, true, true, true, true, true, true, true, true, true, true))
+
+	require.Len(t, policies.Policy.Statement, 1)
+	actions := policies.Policy.Statement[0].Action
+	assert.Contains(t, actions, "ec2:DescribeInstances")
+	assert.Contains(t, actions, "ec2:StopInstances")
+	assert.Contains(t, actions, "ec2:DescribeVpcs")
+	assert.NotContains(t, actions, "ecs:StopTask")
+	assert.NotContains(t, actions, "rds:RebootDBInstance")
+
+	require.Len(t, policies.DiscoveryPolicy.Statement, 1)
+	discoveryActions := policies.DiscoveryPolicy.Statement[0].Action
+	assert.Contains(t, discoveryActions, "ec2:DescribeInstances")
+	assert.NotContains(t, discoveryActions, "ec2:StopInstances")
+	assert.Equal(t, "*", policies.DiscoveryPolicy.Statement[0].Resource)
+}
+
+func Test_newIamPolicies_allDiscoveriesDisabled(t *testing.T) {
+	policies := newIamPolicies(createConfig(true, true, true, true, true, true, true, true, true, true, true))
+
+	assert.ElementsMatch(t, []string{"ec2:DescribeAvailabilityZones", "ec2:DescribeVpcs", "iam:GetRole", "iam:SimulatePrincipalPolicy", "tag:GetResources"}, policies.Policy.Statement[0].Action)
+	assert.ElementsMatch(t, []string{"ec2:DescribeAvailabilityZones", "ec2:DescribeVpcs", "tag:GetResources"}, policies.DiscoveryPolicy.Statement[0].Action)
+	assert.Nil(t, policies.ExtensionPolicy)
+}
+
+func Test_newIamPolicies_extensionPolicy(t *testing.T) {
+	cfg := createConfig(true, true, true, true, true, true, true, true, true, true, true)
+	cfg.AssumeRoles = []string{"arn:aws:iam::123456789012:role/steadybit-extension-aws"}
+	cfg.OrganizationsEnabled = true
+	cfg.AttackJournalBackend = "dynamodb"
+
+	policies := newIamPolicies(cfg)
+
+	require.NotNil(t, policies.ExtensionPolicy)
+	assert.Equal(t, []string{
+		"dynamodb:DeleteItem",
+		"dynamodb:GetItem",
+		"dynamodb:PutItem",
+		"dynamodb:Query",
+		"organizations:ListAccounts",
+		"sts:AssumeRole",
+	}, policies.ExtensionPolicy.Statement[0].Action)
+	assert.NotContains(t, policies.Policy.Statement[0].Action, "sts:AssumeRole")
+}
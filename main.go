@@ -43,6 +43,11 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "iam-policy" {
+		printIamPolicies()
+		return
+	}
+
 	extlogging.InitZeroLog()
 	extbuild.PrintBuildInformation()
 	extruntime.LogRuntimeInformation(zerolog.DebugLevel)
@@ -56,17 +61,36 @@ func main() {
 	}
 
 	config.ParseConfiguration(awsConfigForRootAccount.Region)
+	shutdownTracing := utils.InitializeTracing(ctx)
 
-	utils.InitializeAwsAccess(config.Config, awsConfigForRootAccount)
+	utils.InitializeAwsAccess(*config.Config(), awsConfigForRootAccount)
+	utils.InitializeAttackJournal()
 	extec2.InitializeEc2Util()
+	utils.RegisterRequiredPermissions(utils.PermissionKindExtension, "ec2-util", extec2.Ec2UtilPermissions)
+	utils.RegisterRequiredPermissions(utils.PermissionKindExtension, "tag-cache", utils.TagCachePermissions)
+	utils.RegisterRequiredPermissions(utils.PermissionKindExtension, "dry-run", utils.DryRunPermissions)
 
 	ctx, cancel := SignalCanceledContext()
 
+	utils.AddAwsAccessListener(func(account *utils.AwsAccess) {
+		_, _ = extec2.InitEc2UtilForAccount(account, ctx)
+	})
+	utils.StartRoleSupervisor(ctx)
+	utils.StartOrganizationAccountSync(ctx)
+	config.AddReloadListener(utils.ApplyConfigurationChange)
+	config.StartConfigurationReload(ctx)
+
 	registerHandlers(ctx)
+	utils.StartAttackJournalRollback(ctx)
+	utils.StartPermissionSelfCheck(ctx)
+	extec2.StartBlackholeReconciler(ctx)
 
 	extsignals.AddSignalHandler(extsignals.SignalHandler{
 		Handler: func(signal os.Signal) {
 			cancel()
+			if err := shutdownTracing(context.Background()); err != nil {
+				log.Warn().Err(err).Msg("Failed to flush traces")
+			}
 		},
 		Order: extsignals.OrderStopCustom,
 		Name:  "custom-extension-aws",
@@ -74,7 +98,7 @@ func main() {
 	extsignals.ActivateSignalHandlers()
 
 	action_kit_sdk.RegisterCoverageEndpoints()
-	exthealth.SetReady(true)
+	utils.StartReadinessCheck(ctx)
 
 	exthttp.Listen(exthttp.ListenOpts{
 		Port: 8085,
@@ -101,129 +125,195 @@ func SignalCanceledContext() (context.Context, context.CancelFunc) {
 }
 
 func registerHandlers(ctx context.Context) {
-	cfg := config.Config
 	discovery_kit_sdk.Register(utils.NewCommonAttributeDescriber())
+	registerTargetHandlers(*config.Config(), sdkRegistry{ctx: ctx})
+
+	exthttp.RegisterHttpHandler("/aws/roles", exthttp.GetterAsHandler(utils.GetRoleStatuses))
+	exthttp.RegisterHttpHandler("/aws/permissions", exthttp.GetterAsHandler(utils.GetPermissionReport))
+	exthttp.RegisterHttpHandler("/aws/accounts", exthttp.GetterAsHandler(utils.GetAccountHealth))
+	exthttp.RegisterHttpHandler("/aws/leases", exthttp.GetterAsHandler(utils.GetLeases))
+	utils.RegisterMetricsHandler()
+	exthttp.RegisterRevisionedHandler("/", getExtensionList)
+}
 
+// registerTargetHandlers passes the discoveries and actions enabled by the configuration to the registry.
+func registerTargetHandlers(cfg config.Specification, r handlerRegistry) {
 	if !cfg.DiscoveryDisabledApigateway {
-		discovery_kit_sdk.Register(extapigateway.NewApigatewayDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extapigateway.NewApigatewayThrottleAttack())
+		r.discovery(extapigateway.NewApigatewayDiscovery, extapigateway.ApigatewayDiscoveryPermissions)
+		r.action(journaled(extapigateway.NewApigatewayThrottleAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledAsg {
-		discovery_kit_sdk.Register(extasg.NewAsgDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extasg.NewAsgSuspendProcessesAttack())
+		r.discovery(extasg.NewAsgDiscovery, extasg.AsgDiscoveryPermissions)
+		r.action(journaled(extasg.NewAsgSuspendProcessesAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledRds {
-		discovery_kit_sdk.Register(extrds.NewRdsInstanceDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extrds.NewRdsInstanceRebootAttack())
-		action_kit_sdk.RegisterAction(extrds.NewRdsInstanceStopAttack())
+		r.discovery(extrds.NewRdsInstanceDiscovery, extrds.RdsInstanceDiscoveryPermissions)
+		r.action(instrumented(extrds.NewRdsInstanceRebootAttack()))
+		r.action(instrumented(extrds.NewRdsInstanceStopAttack()))
 
-		discovery_kit_sdk.Register(extrds.NewRdsClusterDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extrds.NewRdsClusterFailoverAttack())
+		r.discovery(extrds.NewRdsClusterDiscovery, extrds.RdsClusterDiscoveryPermissions)
+		r.action(instrumented(extrds.NewRdsClusterFailoverAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledZone {
-		discovery_kit_sdk.Register(extec2.NewAzDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extec2.NewAzBlackholeAction())
+		r.discovery(extec2.NewAzDiscovery, extec2.AzDiscoveryPermissions)
+		r.action(instrumented(extec2.NewAzBlackholeAction()))
 	}
 
 	if !cfg.DiscoveryDisabledSubnet {
-		discovery_kit_sdk.Register(extec2.NewSubnetDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extec2.NewSubnetBlackholeAction())
+		r.discovery(extec2.NewSubnetDiscovery, extec2.SubnetDiscoveryPermissions)
+		r.action(instrumented(extec2.NewSubnetBlackholeAction()))
+		r.action(instrumented(extec2.NewSubnetScopedBlackholeAction()))
 	}
 
 	if !cfg.DiscoveryDisabledEc2 {
-		discovery_kit_sdk.Register(extec2.NewEc2InstanceDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extec2.NewEc2InstanceStateAction())
+		r.discovery(extec2.NewEc2InstanceDiscovery, extec2.Ec2InstanceDiscoveryPermissions)
+		r.action(instrumented(extec2.NewEc2InstanceStateAction()))
+		r.action(instrumented(extec2.NewEc2InstanceStressCpuAction()))
+		r.action(instrumented(extec2.NewEc2InstanceStressMemoryAction()))
+		r.action(instrumented(extec2.NewEc2InstanceStressIoAction()))
+		r.action(instrumented(extec2.NewEc2InstanceFillDiskAction()))
+		r.action(instrumented(extec2.NewEc2InstanceStopProcessAction()))
+		r.action(instrumented(extec2.NewEc2InstanceNetworkDelayAction()))
+		r.action(instrumented(extec2.NewEc2InstanceNetworkLossAction()))
+		r.action(instrumented(extec2.NewEc2InstanceNetworkBlackholePortAction()))
+		r.action(instrumented(extec2.NewEc2InstanceRunSsmDocumentAction()))
 	}
 
 	if !cfg.DiscoveryDisabledNatGateway {
-		discovery_kit_sdk.Register(extec2.NewNatGatewayDiscovery(ctx))
+		r.discovery(extec2.NewNatGatewayDiscovery, extec2.NatGatewayDiscoveryPermissions)
 	}
 
 	if !cfg.DiscoveryDisabledEbs {
-		discovery_kit_sdk.Register(extec2.NewEbsVolumeDiscovery(ctx))
+		r.discovery(extec2.NewEbsVolumeDiscovery, extec2.EbsVolumeDiscoveryPermissions)
 	}
 
 	if !cfg.DiscoveryDisabledSqs {
-		discovery_kit_sdk.Register(extsqs.NewQueueDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extsqs.NewQueueVisibilityTimeoutAttack())
+		r.discovery(extsqs.NewQueueDiscovery, extsqs.QueueDiscoveryPermissions)
+		r.action(journaled(extsqs.NewQueueVisibilityTimeoutAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledEventbridge {
-		discovery_kit_sdk.Register(exteventbridge.NewRuleDiscovery(ctx))
-		action_kit_sdk.RegisterAction(exteventbridge.NewRuleDisableAttack())
+		r.discovery(exteventbridge.NewRuleDiscovery, exteventbridge.RuleDiscoveryPermissions)
+		r.action(journaled(exteventbridge.NewRuleDisableAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledFis {
-		discovery_kit_sdk.Register(extfis.NewFisTemplateDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extfis.NewFisExperimentAction())
+		r.discovery(extfis.NewFisTemplateDiscovery, extfis.FisTemplateDiscoveryPermissions)
+		r.action(instrumented(extfis.NewFisExperimentAction()))
 	}
 
 	if !cfg.DiscoveryDisabledMq {
-		discovery_kit_sdk.Register(extmq.NewBrokerDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extmq.NewBrokerRebootAttack())
+		r.discovery(extmq.NewBrokerDiscovery, extmq.BrokerDiscoveryPermissions)
+		r.action(instrumented(extmq.NewBrokerRebootAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledMsk {
-		discovery_kit_sdk.Register(extmsk.NewMskClusterDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extmsk.NewMskRebootBrokerAttack())
+		r.discovery(extmsk.NewMskClusterDiscovery, extmsk.MskClusterDiscoveryPermissions)
+		r.action(instrumented(extmsk.NewMskRebootBrokerAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledLambda {
-		discovery_kit_sdk.Register(extlambda.NewLambdaDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extlambda.NewInjectStatusCodeAction())
-		action_kit_sdk.RegisterAction(extlambda.NewInjectExceptionAction())
-		action_kit_sdk.RegisterAction(extlambda.NewInjectLatencyAction())
-		action_kit_sdk.RegisterAction(extlambda.NewFillDiskspaceAction())
-		action_kit_sdk.RegisterAction(extlambda.NewDenylistAction())
+		r.discovery(extlambda.NewLambdaDiscovery, extlambda.LambdaDiscoveryPermissions)
+		r.action(instrumented(extlambda.NewInjectStatusCodeAction()))
+		r.action(instrumented(extlambda.NewInjectExceptionAction()))
+		r.action(instrumented(extlambda.NewInjectLatencyAction()))
+		r.action(instrumented(extlambda.NewFillDiskspaceAction()))
+		r.action(instrumented(extlambda.NewDenylistAction()))
 	}
 
 	if !cfg.DiscoveryDisabledEcs {
 		serviceDiscoveryPoller := extecs.NewServiceDescriptionPoller()
-		serviceDiscoveryPoller.Start(ctx)
-
-		discovery_kit_sdk.Register(extecs.NewEcsTaskDiscovery(ctx))
-		discovery_kit_sdk.Register(extecs.NewEcsServiceDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskStopAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsServiceScaleAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskStopProcessAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskStressCpuAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskStressMemoryAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskStressIoAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskFillDiskAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskNetworkBlockholePortAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskNetworkDnsAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskNetworkDelayAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsTaskNetworkLossAction())
-		action_kit_sdk.RegisterAction(extecs.NewEcsServiceEventLogAction(serviceDiscoveryPoller))
-		action_kit_sdk.RegisterAction(extecs.NewEcsServiceTaskCountCheckAction(serviceDiscoveryPoller))
+		r.start(serviceDiscoveryPoller.Start)
+
+		r.discovery(extecs.NewEcsTaskDiscovery, extecs.EcsTaskDiscoveryPermissions)
+		r.discovery(extecs.NewEcsServiceDiscovery, extecs.EcsServiceDiscoveryPermissions)
+		r.action(instrumented(extecs.NewEcsTaskStopAction()))
+		r.action(instrumented(extecs.NewEcsServiceScaleAction()))
+		r.action(instrumented(extecs.NewEcsTaskStopProcessAction()))
+		r.action(instrumented(extecs.NewEcsTaskStressCpuAction()))
+		r.action(instrumented(extecs.NewEcsTaskStressMemoryAction()))
+		r.action(instrumented(extecs.NewEcsTaskStressIoAction()))
+		r.action(instrumented(extecs.NewEcsTaskFillDiskAction()))
+		r.action(instrumented(extecs.NewEcsTaskNetworkBlockholePortAction()))
+		r.action(instrumented(extecs.NewEcsTaskNetworkDnsAction()))
+		r.action(instrumented(extecs.NewEcsTaskNetworkDelayAction()))
+		r.action(instrumented(extecs.NewEcsTaskNetworkLossAction()))
+		r.action(instrumented(extecs.NewEcsTaskRunSsmDocumentAction()))
+		r.action(instrumented(extecs.NewEcsServiceEventLogAction(serviceDiscoveryPoller)))
+		r.action(instrumented(extecs.NewEcsServiceTaskCountCheckAction(serviceDiscoveryPoller)))
 	}
 
 	if !cfg.DiscoveryDisabledDynamodb {
-		discovery_kit_sdk.Register(extdynamodb.NewTableDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extdynamodb.NewTableThrottleAttack())
+		r.discovery(extdynamodb.NewTableDiscovery, extdynamodb.TableDiscoveryPermissions)
+		r.action(journaled(extdynamodb.NewTableThrottleAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledEks {
-		discovery_kit_sdk.Register(exteks.NewEksClusterDiscovery(ctx))
-		discovery_kit_sdk.Register(exteks.NewEksNodegroupDiscovery(ctx))
-		action_kit_sdk.RegisterAction(exteks.NewEksNodegroupTerminateInstancesAttack())
+		r.discovery(exteks.NewEksClusterDiscovery, exteks.EksClusterDiscoveryPermissions)
+		r.discovery(exteks.NewEksNodegroupDiscovery, exteks.EksNodegroupDiscoveryPermissions)
+		r.action(instrumented(exteks.NewEksNodegroupTerminateInstancesAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledElasticache {
-		discovery_kit_sdk.Register(extelasticache.NewElasticacheReplicationGroupDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extelasticache.NewElasticacheNodeGroupFailoverAttack())
+		r.discovery(extelasticache.NewElasticacheReplicationGroupDiscovery, extelasticache.ElasticacheReplicationGroupDiscoveryPermissions)
+		r.action(instrumented(extelasticache.NewElasticacheNodeGroupFailoverAttack()))
 	}
 
 	if !cfg.DiscoveryDisabledElb {
-		discovery_kit_sdk.Register(extelb.NewAlbDiscovery(ctx))
-		action_kit_sdk.RegisterAction(extelb.NewAlbStaticResponseAction())
-		discovery_kit_sdk.Register(extelb.NewNlbDiscovery(ctx))
+		r.discovery(extelb.NewAlbDiscovery, extelb.AlbDiscoveryPermissions)
+		r.action(instrumented(extelb.NewAlbStaticResponseAction()))
+		r.discovery(extelb.NewNlbDiscovery, extelb.NlbDiscoveryPermissions)
 	}
+}
 
-	exthttp.RegisterRevisionedHandler("/", getExtensionList)
+// handlerRegistry receives the discoveries and actions enabled by the configuration. The extension registers them with
+// the SDKs, the iam-policy command only collects the IAM actions they need.
+type handlerRegistry interface {
+	discovery(newDiscovery func(ctx context.Context) discovery_kit_sdk.TargetDiscovery, permissions []string)
+	action(action actionHandler)
+	start(background func(ctx context.Context))
+}
+
+type actionHandler struct {
+	permissions []string
+	register    func()
+}
+
+func instrumented[T any](action action_kit_sdk.Action[T]) actionHandler {
+	return actionHandler{permissions: requiredPermissionsOf(action), register: func() { utils.RegisterInstrumentedAction(action) }}
+}
+
+func journaled[T any](action action_kit_sdk.ActionWithStop[T]) actionHandler {
+	return actionHandler{permissions: requiredPermissionsOf(action), register: func() { utils.RegisterJournaledAction(action) }}
+}
+
+func requiredPermissionsOf(action any) []string {
+	if withPermissions, ok := action.(utils.ActionWithRequiredPermissions); ok {
+		return withPermissions.RequiredPermissions()
+	}
+	return nil
+}
+
+// sdkRegistry registers the discoveries and actions and records the IAM actions they call for the permission self-check.
+type sdkRegistry struct {
+	ctx context.Context
+}
+
+func (r sdkRegistry) discovery(newDiscovery func(ctx context.Context) discovery_kit_sdk.TargetDiscovery, permissions []string) {
+	discovery := newDiscovery(r.ctx)
+	discovery_kit_sdk.Register(discovery)
+	utils.RegisterRequiredPermissions(utils.PermissionKindDiscovery, discovery.Describe().Id, permissions)
+}
+
+func (sdkRegistry) action(action actionHandler) {
+	action.register()
+}
+
+func (r sdkRegistry) start(background func(ctx context.Context)) {
+	background(r.ctx)
 }
 
 type ExtensionListResponse struct {
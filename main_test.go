@@ -60,6 +60,15 @@ func Test_getExtensionList(t *testing.T) {
 			config: createConfig(false, true, true, true, true, true, true, true, true, true, true),
 			wantedRoutes: []string{
 				"/com.steadybit.extension_aws.ec2_instance.state",
+				"/com.steadybit.extension_aws.ec2-instance.fill_disk",
+				"/com.steadybit.extension_aws.ec2-instance.network_blackhole_port",
+				"/com.steadybit.extension_aws.ec2-instance.network_delay",
+				"/com.steadybit.extension_aws.ec2-instance.network_loss",
+				"/com.steadybit.extension_aws.ec2-instance.run_ssm_document",
+				"/com.steadybit.extension_aws.ec2-instance.stop-process",
+				"/com.steadybit.extension_aws.ec2-instance.stress_cpu",
+				"/com.steadybit.extension_aws.ec2-instance.stress_io",
+				"/com.steadybit.extension_aws.ec2-instance.stress_mem",
 				"/com.steadybit.extension_aws.ec2-instance/discovery",
 				"/com.steadybit.extension_aws.ec2-instance/discovery/target-description",
 				"/discovery/attributes",
@@ -74,6 +83,7 @@ func Test_getExtensionList(t *testing.T) {
 			config: createConfig(true, true, true, true, true, true, true, true, false, true, true),
 			wantedRoutes: []string{
 				"/com.steadybit.extension_aws.ec2-subnet.blackhole",
+				"/com.steadybit.extension_aws.ec2-subnet.blackhole_ports",
 				"/com.steadybit.extension_aws.ec2-subnet/discovery",
 				"/com.steadybit.extension_aws.ec2-subnet/discovery/target-description",
 				"/discovery/attributes",
@@ -93,6 +103,7 @@ func Test_getExtensionList(t *testing.T) {
 				"/com.steadybit.extension_aws.ecs-task.network_dns",
 				"/com.steadybit.extension_aws.ecs-task.network_delay",
 				"/com.steadybit.extension_aws.ecs-task.network_loss",
+				"/com.steadybit.extension_aws.ecs-task.run_ssm_document",
 				"/com.steadybit.extension_aws.ecs-task.stress_cpu",
 				"/com.steadybit.extension_aws.ecs-task.stress_mem",
 				"/com.steadybit.extension_aws.ecs-task.stress_io",
@@ -190,7 +201,7 @@ func Test_getExtensionList(t *testing.T) {
 			action_kit_sdk.ClearRegisteredActions()
 			discovery_kit_sdk.ClearRegisteredDiscoveries()
 			http.DefaultServeMux = http.NewServeMux()
-			config.Config = tt.config
+			config.Set(tt.config)
 			background := t.Context()
 			registerHandlers(background)
 
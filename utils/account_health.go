@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/rs/zerolog/log"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-kit/exthealth"
+)
+
+const readinessCheckInterval = 10 * time.Second
+
+// AccountHealth describes the state of one entry of the configured accounts, i.e. one account, region and role.
+type AccountHealth struct {
+	Account                 string     `json:"account"`
+	Region                  string     `json:"region"`
+	Role                    *string    `json:"role,omitempty"`
+	Reachable               bool       `json:"reachable"`
+	LastSuccessfulDiscovery *time.Time `json:"lastSuccessfulDiscovery,omitempty"`
+	LastError               string     `json:"lastError,omitempty"`
+	LastErrorDiscovery      string     `json:"lastErrorDiscovery,omitempty"`
+	LastErrorAt             *time.Time `json:"lastErrorAt,omitempty"`
+	CredentialsExpireAt     *time.Time `json:"credentialsExpireAt,omitempty"`
+	// FailingDiscoveries maps the discoveries whose last run failed for the account to their error, even if the
+	// discovery still reports the targets of the other accounts.
+	FailingDiscoveries map[string]string `json:"failingDiscoveries,omitempty"`
+}
+
+type accountHealthRecord struct {
+	lastSuccess         time.Time
+	lastError           string
+	lastErrorDiscovery  string
+	lastErrorAt         time.Time
+	credentialsExpireAt time.Time
+	failingDiscoveries  map[string]string
+}
+
+var (
+	accountHealthRecords = make(map[string]*accountHealthRecord)
+	accountHealthMutex   sync.Mutex
+)
+
+// recordAccountHealth remembers the outcome of a discovery run for the account. On success, the expiry of the cached
+// credentials is recorded as well.
+func recordAccountHealth(ctx context.Context, discovery string, account *AwsAccess, err error) {
+	now := time.Now()
+	var expiresAt time.Time
+	if err == nil && account.AwsConfig.Credentials != nil {
+		if credentials, credentialsErr := account.AwsConfig.Credentials.Retrieve(ctx); credentialsErr == nil && credentials.CanExpire {
+			expiresAt = credentials.Expires
+		}
+	}
+
+	accountHealthMutex.Lock()
+	defer accountHealthMutex.Unlock()
+	key := getMapKey(account.AccountNumber, account.Region, account.AssumeRole)
+	record, ok := accountHealthRecords[key]
+	if !ok {
+		record = &accountHealthRecord{}
+		accountHealthRecords[key] = record
+	}
+	if err != nil {
+		record.lastError = err.Error()
+		record.lastErrorDiscovery = discovery
+		record.lastErrorAt = now
+		if record.failingDiscoveries == nil {
+			record.failingDiscoveries = make(map[string]string)
+		}
+		record.failingDiscoveries[discovery] = err.Error()
+		return
+	}
+	delete(record.failingDiscoveries, discovery)
+	record.lastSuccess = now
+	record.credentialsExpireAt = expiresAt
+}
+
+// GetAccountHealth returns the health of every configured account, region and role, ordered by account and region.
+func GetAccountHealth() []AccountHealth {
+	accountsMutex.RLock()
+	snapshot := make(map[string]AwsAccess, len(accounts))
+	for _, access := range accounts {
+		snapshot[getMapKey(access.AccountNumber, access.Region, access.AssumeRole)] = access
+	}
+	accountsMutex.RUnlock()
+
+	accountHealthMutex.Lock()
+	defer accountHealthMutex.Unlock()
+	now := time.Now()
+	result := make([]AccountHealth, 0, len(snapshot))
+	for key, access := range snapshot {
+		health := AccountHealth{Account: access.AccountNumber, Region: access.Region, Role: access.AssumeRole}
+		if record, ok := accountHealthRecords[key]; ok {
+			health.Reachable = isReachable(record, now)
+			health.LastSuccessfulDiscovery = optionalTime(record.lastSuccess)
+			health.LastError = record.lastError
+			health.LastErrorDiscovery = record.lastErrorDiscovery
+			health.LastErrorAt = optionalTime(record.lastErrorAt)
+			health.CredentialsExpireAt = optionalTime(record.credentialsExpireAt)
+			if len(record.failingDiscoveries) > 0 {
+				health.FailingDiscoveries = maps.Clone(record.failingDiscoveries)
+			}
+		}
+		result = append(result, health)
+	}
+	// Drop the records of accounts which were removed in the meantime.
+	for key := range accountHealthRecords {
+		if _, ok := snapshot[key]; !ok {
+			delete(accountHealthRecords, key)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Account != result[j].Account {
+			return result[i].Account < result[j].Account
+		}
+		if result[i].Region != result[j].Region {
+			return result[i].Region < result[j].Region
+		}
+		return aws.ToString(result[i].Role) < aws.ToString(result[j].Role)
+	})
+	return result
+}
+
+// isReachable reports whether a discovery succeeded for the account within the configured timeout. Failing discoveries
+// of single services, e.g. because of missing permissions, do not make an account unreachable.
+func isReachable(record *accountHealthRecord, now time.Time) bool {
+	timeout := time.Duration(max(extConfig.Config().AccountReachableTimeout, 1)) * time.Second
+	return !record.lastSuccess.IsZero() && now.Sub(record.lastSuccess) <= timeout
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// StartReadinessCheck marks the extension as ready. If `ReadinessRequiresReachableAccount` is enabled, the readiness is
+// evaluated periodically instead and only reported while at least one account is reachable.
+func StartReadinessCheck(ctx context.Context) {
+	if !extConfig.Config().ReadinessRequiresReachableAccount {
+		exthealth.SetReady(true)
+		return
+	}
+	ready := updateReadiness(false)
+	go func() {
+		ticker := time.NewTicker(readinessCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ready = updateReadiness(ready)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func updateReadiness(wasReady bool) bool {
+	ready := isAnyAccountReachable()
+	if ready != wasReady {
+		if ready {
+			log.Info().Msg("At least one AWS account is reachable. Reporting ready.")
+		} else {
+			log.Warn().Msg("No AWS account is reachable. Reporting not ready.")
+		}
+	}
+	exthealth.SetReady(ready)
+	return ready
+}
+
+func isAnyAccountReachable() bool {
+	for _, health := range GetAccountHealth() {
+		if health.Reachable {
+			return true
+		}
+	}
+	return false
+}
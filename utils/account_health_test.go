@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountHealthAfterDiscovery(t *testing.T) {
+	config.Update(func(spec *config.Specification) {
+		spec.WorkerThreads = 4
+		spec.AccountReachableTimeout = 600
+	})
+	accounts = getTestAccountsWithRoleAssumption()
+	accountHealthRecords = make(map[string]*accountHealthRecord)
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	for key, access := range accounts {
+		access.AwsConfig.Credentials = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{CanExpire: true, Expires: expiry}, nil
+		})
+		accounts[key] = access
+	}
+
+	_, err := ForEveryConfiguredAwsAccess(func(account *AwsAccess, _ context.Context) ([]discovery_kit_api.Target, error) {
+		if account.AccountNumber == "22222222" {
+			return nil, errors.New("access denied")
+		}
+		return []discovery_kit_api.Target{}, nil
+	}, context.Background(), "discovery")
+	var discoveryErr *AccountDiscoveryError
+	require.ErrorAs(t, err, &discoveryErr, "no targets are left, so the failing accounts are reported")
+	assert.Len(t, discoveryErr.Failures, 2)
+
+	health := GetAccountHealth()
+	require.Len(t, health, 6)
+	assert.Equal(t, "11111111", health[0].Account)
+	assert.Equal(t, "eu-central-1", health[0].Region)
+	assert.True(t, health[0].Reachable)
+	assert.NotNil(t, health[0].LastSuccessfulDiscovery)
+	assert.Equal(t, expiry, *health[0].CredentialsExpireAt)
+	assert.Empty(t, health[0].LastError)
+
+	assert.Equal(t, "22222222", health[2].Account)
+	assert.False(t, health[2].Reachable)
+	assert.Nil(t, health[2].LastSuccessfulDiscovery)
+	assert.Equal(t, "access denied", health[2].LastError)
+	assert.Equal(t, "discovery", health[2].LastErrorDiscovery)
+	assert.True(t, isAnyAccountReachable())
+}
+
+func TestAccountIsUnreachableAfterTimeout(t *testing.T) {
+	config.Update(func(spec *config.Specification) { spec.AccountReachableTimeout = 60 })
+	accounts = getTestAccountsWithoutRoleAssumption()
+	accountHealthRecords = make(map[string]*accountHealthRecord)
+	for _, access := range accounts {
+		accountHealthRecords[getMapKey(access.AccountNumber, access.Region, access.AssumeRole)] = &accountHealthRecord{lastSuccess: time.Now().Add(-2 * time.Minute)}
+	}
+	accountHealthRecords["removed"] = &accountHealthRecord{lastSuccess: time.Now()}
+
+	assert.False(t, isAnyAccountReachable())
+	assert.NotContains(t, accountHealthRecords, "removed")
+}
+
+func TestGetAccountHealthReportsFailingDiscoveriesWhileOtherAccountsProvideTargets(t *testing.T) {
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 4 })
+	accounts = getTestAccountsWithRoleAssumption()
+	accountHealthRecords = make(map[string]*accountHealthRecord)
+
+	result, err := ForEveryConfiguredAwsAccess(getTestFunction(new("22222222"), nil), context.Background(), "partial-discovery")
+	require.NoError(t, err)
+	assert.Len(t, result, 4)
+
+	health := GetAccountHealth()
+	assert.Equal(t, "22222222", health[2].Account)
+	assert.Equal(t, map[string]string{"partial-discovery": "damn broken discovery"}, health[2].FailingDiscoveries)
+	assert.Empty(t, health[0].FailingDiscoveries)
+
+	_, err = ForEveryConfiguredAwsAccess(getTestFunction(nil, nil), context.Background(), "partial-discovery")
+	require.NoError(t, err)
+	assert.Empty(t, GetAccountHealth()[2].FailingDiscoveries, "the discovery recovered")
+}
@@ -0,0 +1,297 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RegisterInstrumentedAction registers the action, records the outcomes of its operations as metrics and creates a
+// span for each operation. Prepare rejects protected targets before the action is invoked. The optional status and
+// stop operations are only exposed if the wrapped action implements them.
+func RegisterInstrumentedAction[T any](action action_kit_sdk.Action[T]) {
+	registerInstrumentedAction(action, false)
+}
+
+// RegisterJournaledAction registers the action like RegisterInstrumentedAction and records its executions in the attack
+// journal. Executions which are never stopped are rolled back once their extension is gone. Only use it for actions
+// whose stop restores the target from the state alone.
+func RegisterJournaledAction[T any](action action_kit_sdk.ActionWithStop[T]) {
+	registerInstrumentedAction[T](action, true)
+	registerJournalRollback(action.Describe().Id, journalRollbackFor(func(ctx context.Context, state *T) error {
+		result, err := action.Stop(ctx, state)
+		if err == nil && result != nil && result.Error != nil {
+			return errors.New(result.Error.Title)
+		}
+		return err
+	}))
+}
+
+func registerInstrumentedAction[T any](action action_kit_sdk.Action[T], journaled bool) {
+	withStatus, hasStatus := action.(action_kit_sdk.ActionWithStatus[T])
+	withStop, hasStop := action.(action_kit_sdk.ActionWithStop[T])
+	base := instrumentedAction[T]{delegate: action, id: action.Describe().Id, journaled: journaled, hasStop: hasStop}
+	if withPermissions, ok := action.(ActionWithRequiredPermissions); ok {
+		RegisterRequiredPermissions(PermissionKindAction, base.id, withPermissions.RequiredPermissions())
+	}
+	switch {
+	case hasStatus && hasStop:
+		action_kit_sdk.RegisterAction[InstrumentedState[T]](&instrumentedActionWithStatusAndStop[T]{
+			instrumentedActionWithStatus: instrumentedActionWithStatus[T]{instrumentedAction: base, status: withStatus},
+			stop:                         withStop,
+		})
+	case hasStatus:
+		action_kit_sdk.RegisterAction[InstrumentedState[T]](&instrumentedActionWithStatus[T]{instrumentedAction: base, status: withStatus})
+	case hasStop:
+		action_kit_sdk.RegisterAction[InstrumentedState[T]](&instrumentedActionWithStop[T]{instrumentedAction: base, stop: withStop})
+	default:
+		action_kit_sdk.RegisterAction[InstrumentedState[T]](&base)
+	}
+}
+
+// InstrumentedState wraps the state of an action with the execution details needed to correlate all operations of
+// one execution in a single trace.
+type InstrumentedState[T any] struct {
+	State         T                 `json:"state"`
+	ExecutionId   string            `json:"executionId,omitempty"`
+	ExperimentKey string            `json:"experimentKey,omitempty"`
+	ExperimentRun int               `json:"experimentRun,omitempty"`
+	TraceContext  map[string]string `json:"traceContext,omitempty"`
+	DryRun        *DryRunState      `json:"dryRun,omitempty"`
+	Leases        []string          `json:"leases,omitempty"`
+}
+
+var traceContextPropagator = propagation.TraceContext{}
+
+type instrumentedAction[T any] struct {
+	delegate  action_kit_sdk.Action[T]
+	id        string
+	journaled bool
+	hasStop   bool
+}
+
+func (a *instrumentedAction[T]) NewEmptyState() InstrumentedState[T] {
+	return InstrumentedState[T]{State: a.delegate.NewEmptyState()}
+}
+
+// Describe adds the dry run parameter to every attack.
+func (a *instrumentedAction[T]) Describe() action_kit_api.ActionDescription {
+	description := a.delegate.Describe()
+	if description.Kind == action_kit_api.Attack {
+		description.Parameters = append(slices.Clone(description.Parameters), dryRunParameter())
+	}
+	return description
+}
+
+func (a *instrumentedAction[T]) isAttack() bool {
+	return a.delegate.Describe().Kind == action_kit_api.Attack
+}
+
+func (a *instrumentedAction[T]) Prepare(ctx context.Context, state *InstrumentedState[T], request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.ExecutionId = request.ExecutionId.String()
+	if request.ExecutionContext != nil {
+		if request.ExecutionContext.ExperimentKey != nil {
+			state.ExperimentKey = *request.ExecutionContext.ExperimentKey
+		}
+		if request.ExecutionContext.ExecutionId != nil {
+			state.ExperimentRun = *request.ExecutionContext.ExecutionId
+		}
+	}
+
+	ctx, span := a.startSpan(ctx, "prepare", state)
+	state.TraceContext = map[string]string{}
+	traceContextPropagator.Inject(ctx, propagation.MapCarrier(state.TraceContext))
+
+	if err := checkTargetProtection(request.Target); err != nil {
+		a.finish(span, "prepare", err, nil)
+		return nil, err
+	}
+	dryRun := a.isAttack() && isDryRun(request)
+	if a.isAttack() && !dryRun {
+		resourceArns, err := leaseResourceArns(ctx, a.delegate, request.Target, a.journaled)
+		if err == nil && len(resourceArns) > 0 {
+			err = acquireLeases(ctx, resourceArns, Lease{ActionId: a.id, ExecutionId: state.ExecutionId, TargetName: request.Target.Name})
+		}
+		if err != nil {
+			a.finish(span, "prepare", err, nil)
+			return nil, err
+		}
+		state.Leases = resourceArns
+	}
+	result, err := a.delegate.Prepare(ctx, &state.State, request)
+	if err != nil || errorOf(result) != nil {
+		releaseLeases(state.Leases, state.ExecutionId)
+	}
+	if err == nil && dryRun {
+		state.DryRun = newDryRunState(request.Target)
+		if result == nil {
+			result = &action_kit_api.PrepareResult{}
+		}
+		var messages []action_kit_api.Message
+		if result.Messages != nil {
+			messages = *result.Messages
+		}
+		messages = append(messages, dryRunMessage(action_kit_api.Info, "The attack only checks its permissions and does not change anything."))
+		result.Messages = &messages
+	}
+	a.finish(span, "prepare", err, errorOf(result))
+	return result, err
+}
+
+func (a *instrumentedAction[T]) Start(ctx context.Context, state *InstrumentedState[T]) (*action_kit_api.StartResult, error) {
+	ctx, span := a.startSpan(ctx, "start", state)
+	if state.DryRun != nil {
+		result, err := a.dryRun(ctx, state)
+		a.finish(span, "start", err, nil)
+		return result, err
+	}
+	if a.journaled {
+		journalAttackStarted(ctx, a.id, state.ExecutionId, state.State)
+	}
+	result, err := a.delegate.Start(ctx, &state.State)
+	if !a.hasStop {
+		releaseLeases(state.Leases, state.ExecutionId)
+	}
+	if a.journaled {
+		// the state may have been extended during start
+		journalAttackStarted(ctx, a.id, state.ExecutionId, state.State)
+	}
+	a.finish(span, "start", err, errorOf(result))
+	return result, err
+}
+
+// dryRun checks the permissions of the action instead of starting it.
+func (a *instrumentedAction[T]) dryRun(ctx context.Context, state *InstrumentedState[T]) (*action_kit_api.StartResult, error) {
+	var messages []action_kit_api.Message
+	var err error
+	if withDryRun, ok := a.delegate.(ActionWithDryRun[T]); ok {
+		messages, err = withDryRun.DryRun(ctx, &state.State)
+	} else {
+		var permissions []string
+		if withPermissions, ok := a.delegate.(ActionWithRequiredPermissions); ok {
+			permissions = withPermissions.RequiredPermissions()
+		}
+		messages, err = SimulatePermissions(ctx, state.DryRun, permissions)
+	}
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to check the permissions of the dry run.", err)
+	}
+	return &action_kit_api.StartResult{Messages: &messages}, nil
+}
+
+// startSpan starts the span of an operation. Operations after prepare continue the trace stored in the state.
+func (a *instrumentedAction[T]) startSpan(ctx context.Context, operation string, state *InstrumentedState[T]) (context.Context, trace.Span) {
+	if len(state.TraceContext) > 0 {
+		ctx = traceContextPropagator.Extract(ctx, propagation.MapCarrier(state.TraceContext))
+	}
+	return tracer().Start(ctx, fmt.Sprintf("%s %s", a.id, operation), trace.WithAttributes(
+		attribute.String("steadybit.action.id", a.id),
+		attribute.String("steadybit.action.operation", operation),
+		attribute.String("steadybit.execution.id", state.ExecutionId),
+		attribute.String("steadybit.experiment.key", state.ExperimentKey),
+		attribute.Int("steadybit.experiment.execution", state.ExperimentRun),
+	))
+}
+
+func (a *instrumentedAction[T]) finish(span trace.Span, operation string, err error, resultErr *action_kit_api.ActionKitError) {
+	defer span.End()
+	recordSpanError(span, err)
+	if err == nil && resultErr != nil {
+		span.SetStatus(codes.Error, resultErr.Title)
+	}
+	recordActionOperation(a.id, operation, err != nil || resultErr != nil)
+}
+
+func errorOf(result any) *action_kit_api.ActionKitError {
+	switch r := result.(type) {
+	case *action_kit_api.PrepareResult:
+		if r != nil {
+			return r.Error
+		}
+	case *action_kit_api.StartResult:
+		if r != nil {
+			return r.Error
+		}
+	case *action_kit_api.StatusResult:
+		if r != nil {
+			return r.Error
+		}
+	case *action_kit_api.StopResult:
+		if r != nil {
+			return r.Error
+		}
+	}
+	return nil
+}
+
+type instrumentedActionWithStatus[T any] struct {
+	instrumentedAction[T]
+	status action_kit_sdk.ActionWithStatus[T]
+}
+
+func (a *instrumentedActionWithStatus[T]) Status(ctx context.Context, state *InstrumentedState[T]) (*action_kit_api.StatusResult, error) {
+	ctx, span := a.startSpan(ctx, "status", state)
+	if state.DryRun != nil {
+		a.finish(span, "status", nil, nil)
+		return &action_kit_api.StatusResult{Completed: true}, nil
+	}
+	result, err := a.status.Status(ctx, &state.State)
+	a.finish(span, "status", err, errorOf(result))
+	return result, err
+}
+
+type instrumentedActionWithStop[T any] struct {
+	instrumentedAction[T]
+	stop action_kit_sdk.ActionWithStop[T]
+}
+
+func (a *instrumentedActionWithStop[T]) Stop(ctx context.Context, state *InstrumentedState[T]) (*action_kit_api.StopResult, error) {
+	return instrumentedStop(ctx, &a.instrumentedAction, a.stop, state)
+}
+
+type instrumentedActionWithStatusAndStop[T any] struct {
+	instrumentedActionWithStatus[T]
+	stop action_kit_sdk.ActionWithStop[T]
+}
+
+func (a *instrumentedActionWithStatusAndStop[T]) Stop(ctx context.Context, state *InstrumentedState[T]) (*action_kit_api.StopResult, error) {
+	return instrumentedStop(ctx, &a.instrumentedAction, a.stop, state)
+}
+
+func instrumentedStop[T any](ctx context.Context, a *instrumentedAction[T], action action_kit_sdk.ActionWithStop[T], state *InstrumentedState[T]) (*action_kit_api.StopResult, error) {
+	ctx, span := a.startSpan(ctx, "stop", state)
+	defer releaseLeases(state.Leases, state.ExecutionId)
+	if state.DryRun != nil {
+		a.finish(span, "stop", nil, nil)
+		return nil, nil
+	}
+	if a.journaled && journalAttackRolledBack(ctx, state.ExecutionId) {
+		journalAttackStopped(ctx, state.ExecutionId)
+		a.finish(span, "stop", nil, nil)
+		return &action_kit_api.StopResult{
+			Messages: new([]action_kit_api.Message{{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: "The target has already been restored by the extension, as the extension which started the attack was gone.",
+			}}),
+		}, nil
+	}
+	result, err := action.Stop(ctx, &state.State)
+	if a.journaled && err == nil && errorOf(result) == nil {
+		journalAttackStopped(ctx, state.ExecutionId)
+	}
+	a.finish(span, "stop", err, errorOf(result))
+	return result, err
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type instrumentationTestAction struct {
+	prepareErr error
+}
+
+func (a *instrumentationTestAction) NewEmptyState() string { return "" }
+func (a *instrumentationTestAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{Id: "instrumentation-test-action"}
+}
+func (a *instrumentationTestAction) Prepare(_ context.Context, state *string, _ action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	*state = "prepared"
+	return nil, a.prepareErr
+}
+func (a *instrumentationTestAction) Start(_ context.Context, _ *string) (*action_kit_api.StartResult, error) {
+	return &action_kit_api.StartResult{}, nil
+}
+func (a *instrumentationTestAction) Stop(_ context.Context, _ *string) (*action_kit_api.StopResult, error) {
+	return &action_kit_api.StopResult{Error: &action_kit_api.ActionKitError{Title: "failed"}}, nil
+}
+
+func TestInstrumentedActionRecordsOutcomesAndSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	originalProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(originalProvider)
+
+	delegate := &instrumentationTestAction{}
+	action := &instrumentedActionWithStop[string]{
+		instrumentedAction: instrumentedAction[string]{delegate: delegate, id: "instrumentation-test-action"},
+		stop:               delegate,
+	}
+	var _ action_kit_sdk.ActionWithStop[InstrumentedState[string]] = action
+	_, isWithStatus := any(action).(action_kit_sdk.ActionWithStatus[InstrumentedState[string]])
+	assert.False(t, isWithStatus)
+
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+		ExecutionId:      uuid.New(),
+		ExecutionContext: &action_kit_api.ExecutionContext{ExperimentKey: new("ADM-1"), ExecutionId: new(42)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "prepared", state.State)
+	_, _ = action.Start(context.Background(), &state)
+	_, _ = action.Stop(context.Background(), &state)
+
+	delegate.prepareErr = errors.New("invalid")
+	failedState := action.NewEmptyState()
+	_, _ = action.Prepare(context.Background(), &failedState, action_kit_api.PrepareActionRequestBody{ExecutionId: uuid.New()})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(actionOperations.WithLabelValues("instrumentation-test-action", "prepare", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(actionOperations.WithLabelValues("instrumentation-test-action", "prepare", "failure")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(actionOperations.WithLabelValues("instrumentation-test-action", "start", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(actionOperations.WithLabelValues("instrumentation-test-action", "stop", "failure")))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	assert.Equal(t, "instrumentation-test-action prepare", spans[0].Name())
+	assert.Equal(t, "instrumentation-test-action stop", spans[2].Name())
+	assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID(), "start continues the trace of prepare")
+	assert.Equal(t, spans[0].SpanContext().TraceID(), spans[2].SpanContext().TraceID(), "stop continues the trace of prepare")
+	assert.NotEqual(t, spans[0].SpanContext().TraceID(), spans[3].SpanContext().TraceID())
+	assert.Contains(t, spans[1].Attributes(), attribute.String("steadybit.experiment.key", "ADM-1"))
+	assert.Equal(t, "failed", spans[2].Status().Description)
+}
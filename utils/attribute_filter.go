@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+)
+
+// requiredAttributes are kept by the allow-list mode, as the actions need them to find the account of a target.
+var requiredAttributes = []string{"aws.account", "aws.region", "extension-aws.discovered-by-role"}
+
+// ApplyAttributeFilters removes the attributes of the targets matching one of the excludes. If includes are given, only
+// matching attributes are kept. Both support the glob wildcards * and ?. Afterward, label attributes exceeding
+// `DiscoveryAttributesMaxLabels` are dropped in alphabetical order. Labels of protected tags are always kept, as the
+// protection of the targets relies on them.
+func ApplyAttributeFilters(targets []discovery_kit_api.Target, excludes []string, includes []string) []discovery_kit_api.Target {
+	maxLabels := extConfig.Config().DiscoveryAttributesMaxLabels
+	if len(excludes) == 0 && len(includes) == 0 && maxLabels <= 0 {
+		return targets
+	}
+	excludePatterns := globsToRegex(excludes)
+	includePatterns := globsToRegex(includes)
+	for _, target := range targets {
+		var labels []string
+		for attribute := range target.Attributes {
+			if isProtectedTagLabel(attribute) {
+				continue
+			}
+			if matchesAnyPattern(excludePatterns, attribute) ||
+				(len(includePatterns) > 0 && !matchesAnyPattern(includePatterns, attribute) && !slices.Contains(requiredAttributes, attribute)) {
+				delete(target.Attributes, attribute)
+				continue
+			}
+			if isLabelAttribute(attribute) {
+				labels = append(labels, attribute)
+			}
+		}
+		if maxLabels > 0 && len(labels) > maxLabels {
+			slices.Sort(labels)
+			for _, attribute := range labels[maxLabels:] {
+				delete(target.Attributes, attribute)
+			}
+		}
+	}
+	return targets
+}
+
+func globsToRegex(globs []string) []string {
+	patterns := make([]string, 0, len(globs))
+	for _, glob := range globs {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			patterns = append(patterns, globToRegex(glob))
+		}
+	}
+	return patterns
+}
+
+func matchesAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchesPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLabelAttribute(attribute string) bool {
+	return strings.Contains(attribute, ".label.") && !strings.Contains(attribute, "k8s-label")
+}
+
+func isProtectedTagLabel(attribute string) bool {
+	if !isLabelAttribute(attribute) {
+		return false
+	}
+	for _, protectedTag := range extConfig.Config().ProtectedTags {
+		key, _, _ := strings.Cut(protectedTag, "=")
+		if strings.HasSuffix(attribute, ".label."+strings.ToLower(strings.TrimSpace(key))) {
+			return true
+		}
+	}
+	return false
+}
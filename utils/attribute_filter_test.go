@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFilterTestTarget() []discovery_kit_api.Target {
+	return []discovery_kit_api.Target{{Attributes: map[string][]string{
+		"aws.account":                           {"42"},
+		"aws.region":                            {"eu-central-1"},
+		"aws-ec2.instance.id":                   {"i-1"},
+		"aws-ec2.image":                         {"ami-1"},
+		"aws-ec2.hostname.internal":             {"ip-10-0-0-1"},
+		"aws-ec2.label.team":                    {"chaos"},
+		"aws-ec2.label.cost-center":             {"1"},
+		"aws-ec2.label.app":                     {"shop"},
+		"aws-ec2.label.steadybit.com/protected": {"true"},
+	}}}
+}
+
+func attributeNames(targets []discovery_kit_api.Target) []string {
+	return slices.Sorted(maps.Keys(targets[0].Attributes))
+}
+
+func TestApplyAttributeFiltersWithGlobExcludes(t *testing.T) {
+	extConfig.Update(func(spec *extConfig.Specification) { spec.ProtectedTags = []string{"steadybit.com/protected=true"} })
+	defer extConfig.Update(func(spec *extConfig.Specification) { spec.ProtectedTags = nil })
+
+	targets := ApplyAttributeFilters(newFilterTestTarget(), []string{"aws-ec2.label.*", "aws-ec2.host?ame.*", "aws-ec2.image"}, nil)
+
+	assert.Equal(t, []string{"aws-ec2.instance.id", "aws-ec2.label.steadybit.com/protected", "aws.account", "aws.region"}, attributeNames(targets))
+}
+
+func TestApplyAttributeFiltersWithIncludes(t *testing.T) {
+	targets := ApplyAttributeFilters(newFilterTestTarget(), []string{"aws-ec2.label.cost-center"}, []string{"aws-ec2.instance.id", "aws-ec2.label.*"})
+
+	assert.Equal(t, []string{"aws-ec2.instance.id", "aws-ec2.label.app", "aws-ec2.label.steadybit.com/protected", "aws-ec2.label.team", "aws.account", "aws.region"}, attributeNames(targets))
+}
+
+func TestApplyAttributeFiltersWithMaxLabels(t *testing.T) {
+	extConfig.Update(func(spec *extConfig.Specification) {
+		spec.DiscoveryAttributesMaxLabels = 2
+		spec.ProtectedTags = []string{"steadybit.com/protected"}
+	})
+	defer extConfig.Update(func(spec *extConfig.Specification) {
+		spec.DiscoveryAttributesMaxLabels = 0
+		spec.ProtectedTags = nil
+	})
+
+	targets := ApplyAttributeFilters(newFilterTestTarget(), nil, nil)
+
+	assert.Equal(t, []string{"aws-ec2.hostname.internal", "aws-ec2.image", "aws-ec2.instance.id", "aws-ec2.label.app", "aws-ec2.label.cost-center", "aws-ec2.label.steadybit.com/protected", "aws.account", "aws.region"}, attributeNames(targets))
+}
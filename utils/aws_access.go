@@ -7,14 +7,24 @@ import (
 	"context"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
 	"github.com/aws/smithy-go/middleware"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
 	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
 )
 
+const defaultSessionName = "steadybit-extension-aws"
+
 type AwsAccess struct {
 	AccountNumber string
 	Region        string
@@ -25,7 +35,19 @@ type AwsAccess struct {
 
 var (
 	rootAccountNumber string
+	rootPartition     string
+	rootAwsConfig     aws.Config
+	rootStsClient     stscreds.AssumeRoleAPIClient
 	accounts          map[string]AwsAccess
+	accountsMutex     sync.RWMutex
+	accessListeners   []func(account *AwsAccess)
+	identifyAccount   = func(ctx context.Context, awsConfig aws.Config) (string, error) {
+		identityOutput, err := sts.NewFromConfig(awsConfig).GetCallerIdentity(ctx, nil)
+		if err != nil {
+			return "", err
+		}
+		return aws.ToString(identityOutput.Account), nil
+	}
 )
 
 func InitializeAwsAccess(specification extConfig.Specification, awsConfigForRootAccount aws.Config) {
@@ -35,11 +57,21 @@ func InitializeAwsAccess(specification extConfig.Specification, awsConfigForRoot
 	awsConfigForRootAccount.APIOptions = append(awsConfigForRootAccount.APIOptions, func(stack *middleware.Stack) error {
 		return stack.Initialize.Add(customLoggerMiddleware, middleware.After)
 	})
+	if specification.TracingEnabled {
+		awsConfigForRootAccount.APIOptions = append(awsConfigForRootAccount.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(tracingMiddleware, middleware.Before)
+		})
+	}
 
 	if specification.AwsEndpointOverride != "" {
 		log.Warn().Msgf("Overriding AWS base endpoint with '%s'", specification.AwsEndpointOverride)
 		awsConfigForRootAccount.BaseEndpoint = &specification.AwsEndpointOverride
 	}
+	if len(specification.AwsEndpointOverrides) > 0 {
+		log.Warn().Msgf("Overriding AWS service endpoints with %v", specification.AwsEndpointOverrides)
+		// Prepended, so that the overrides take precedence over the endpoints of the environment and shared config.
+		awsConfigForRootAccount.ConfigSources = append([]any{newServiceEndpointOverrides(specification.AwsEndpointOverrides)}, awsConfigForRootAccount.ConfigSources...)
+	}
 
 	stsClientForRootAccount := sts.NewFromConfig(awsConfigForRootAccount)
 	identityOutputRoot, err := stsClientForRootAccount.GetCallerIdentity(context.Background(), nil)
@@ -48,43 +80,216 @@ func InitializeAwsAccess(specification extConfig.Specification, awsConfigForRoot
 	}
 
 	rootAccountNumber = aws.ToString(identityOutputRoot.Account)
+	rootPartition = getPartition(aws.ToString(identityOutputRoot.Arn), awsConfigForRootAccount.Region)
+	rootAwsConfig = awsConfigForRootAccount
+	rootStsClient = stsClientForRootAccount
 	accounts = make(map[string]AwsAccess)
 
-	if len(specification.AssumeRolesAdvanced) > 0 {
+	if len(specification.AssumeRolesAdvanced) > 0 || specification.OrganizationsEnabled {
 		log.Debug().Msgf("Executing role assumption in other AWS Accounts.")
 		for _, assumeRoleConfig := range specification.AssumeRolesAdvanced {
-			awsConfig := awsConfigForRootAccount.Copy()
-			awsConfig.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClientForRootAccount, assumeRoleConfig.RoleArn, func(o *stscreds.AssumeRoleOptions) {
-				o.RoleSessionName = "steadybit-extension-aws"
-			}))
-
-			stsClient := sts.NewFromConfig(awsConfig)
-			identityOutput, err := stsClient.GetCallerIdentity(context.Background(), nil)
-			if err != nil {
-				log.Error().Err(err).Msgf("Failed to identify AWS account number for account assumed via role '%s'. The roleArn will be ignored until the next restart of the extension.", assumeRoleConfig.RoleArn)
-				continue
-			}
-			assumedAccount := aws.ToString(identityOutput.Account)
-			log.Info().Msgf("Successfully assumed role '%s' in account '%s'", assumeRoleConfig.RoleArn, assumedAccount)
-			prepareRegionConfigs(awsConfig, &assumeRoleConfig.RoleArn, assumedAccount, assumeRoleConfig.Regions, assumeRoleConfig.TagFilters)
+			assumeRoleAndAddAccess(context.Background(), assumeRoleConfig)
+		}
+		if specification.OrganizationsEnabled {
+			syncOrganizationAccounts(context.Background(), organizations.NewFromConfig(awsConfigForRootAccount))
 		}
 	} else {
 		prepareRegionConfigs(awsConfigForRootAccount, nil, aws.ToString(identityOutputRoot.Account), specification.Regions, specification.TagFilters)
 	}
 }
 
-func prepareRegionConfigs(awsConfig aws.Config, assumedRole *string, account string, regions []string, tagFilters []extConfig.TagFilter) {
+// ApplyConfigurationChange adds and removes accounts after the configuration has been reloaded. Roles whose regions or
+// tag filters changed are removed and assumed again.
+func ApplyConfigurationChange(previous extConfig.Specification, current extConfig.Specification) {
+	ctx := context.Background()
+	previousRoles := rolesByArn(previous.AssumeRolesAdvanced)
+	currentRoles := rolesByArn(current.AssumeRolesAdvanced)
+
+	for roleArn, assumeRole := range previousRoles {
+		if currentRole, ok := currentRoles[roleArn]; !ok || !reflect.DeepEqual(assumeRole, currentRole) {
+			log.Info().Msgf("Role '%s' was removed or changed in the configuration. Removing it.", roleArn)
+			removeAssumedRole(roleArn)
+		}
+	}
+
+	if current.OrganizationsEnabled {
+		syncOrganizationAccounts(ctx, organizations.NewFromConfig(rootAwsConfig))
+	} else if previous.OrganizationsEnabled {
+		reconcileOrganizationRoles(ctx, map[string]extConfig.AssumeRole{})
+	}
+
+	for _, assumeRole := range current.AssumeRolesAdvanced {
+		if previousRole, ok := previousRoles[assumeRole.RoleArn]; !ok || !reflect.DeepEqual(assumeRole, previousRole) {
+			log.Info().Msgf("Role '%s' was added or changed in the configuration. Assuming it.", assumeRole.RoleArn)
+			assumeRoleAndAddAccess(ctx, assumeRole)
+		}
+	}
+
+	previousUsesRootAccount := usesRootAccount(previous)
+	currentUsesRootAccount := usesRootAccount(current)
+	rootAccountChanged := !reflect.DeepEqual(previous.Regions, current.Regions) || !reflect.DeepEqual(previous.TagFilters, current.TagFilters)
+	if previousUsesRootAccount && (!currentUsesRootAccount || rootAccountChanged) {
+		removeRootAccountAccess()
+	}
+	if currentUsesRootAccount && (!previousUsesRootAccount || rootAccountChanged) {
+		log.Info().Msgf("Using root account '%s' in regions %v.", rootAccountNumber, current.Regions)
+		notifyAccessListeners(prepareRegionConfigs(rootAwsConfig, nil, rootAccountNumber, current.Regions, current.TagFilters))
+	}
+}
+
+func rolesByArn(assumeRoles extConfig.AssumeRoles) map[string]extConfig.AssumeRole {
+	result := make(map[string]extConfig.AssumeRole, len(assumeRoles))
+	for _, assumeRole := range assumeRoles {
+		result[assumeRole.RoleArn] = assumeRole
+	}
+	return result
+}
+
+func usesRootAccount(specification extConfig.Specification) bool {
+	return len(specification.AssumeRolesAdvanced) == 0 && !specification.OrganizationsEnabled
+}
+
+func removeRootAccountAccess() {
+	accountsMutex.Lock()
+	defer accountsMutex.Unlock()
+	for key, access := range accounts {
+		if access.AssumeRole == nil {
+			delete(accounts, key)
+		}
+	}
+}
+
+// newAssumedRoleConfig returns the configuration using the credentials of the given role. Chained roles are assumed in
+// order, each one with the credentials of the previous one.
+func newAssumedRoleConfig(assumeRole extConfig.AssumeRole) aws.Config {
+	sessionName := assumeRole.SessionName
+	if sessionName == "" {
+		sessionName = defaultSessionName
+	}
+	stsClient := rootStsClient
+	for _, chained := range assumeRole.ChainedRoles {
+		chainedConfig := rootAwsConfig.Copy()
+		chainedConfig.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, chained.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = sessionName
+			o.ExternalID = optionalString(chained.ExternalId)
+		}))
+		stsClient = sts.NewFromConfig(chainedConfig)
+	}
+
+	awsConfig := rootAwsConfig.Copy()
+	awsConfig.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, assumeRole.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = sessionName
+		o.ExternalID = optionalString(assumeRole.ExternalId)
+		if assumeRole.DurationSeconds > 0 {
+			o.Duration = time.Duration(assumeRole.DurationSeconds) * time.Second
+		}
+		o.Tags = sessionTags(assumeRole.SessionTags)
+	}))
+	return awsConfig
+}
+
+func sessionTags(tags map[string]string) []ststypes.Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	result := make([]ststypes.Tag, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, ststypes.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	return result
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
+
+// assumeRoleAndAddAccess assumes the given role and adds an AwsAccess per configured region. If the role cannot be
+// assumed, it is handed over to the role supervisor which keeps retrying it in the background.
+func assumeRoleAndAddAccess(ctx context.Context, assumeRoleConfig extConfig.AssumeRole) {
+	awsConfig := newAssumedRoleConfig(assumeRoleConfig)
+	assumedAccount, err := identifyAccount(ctx, awsConfig)
+	if err != nil {
+		nextAttempt := supervisor.markFailed(assumeRoleConfig, awsConfig, err)
+		log.Error().Err(err).Msgf("Failed to identify AWS account number for account assumed via role '%s'. The role will be retried in the background, next attempt at %s.", assumeRoleConfig.RoleArn, nextAttempt.Format(time.RFC3339))
+		return
+	}
+	log.Info().Msgf("Successfully assumed role '%s' in account '%s'", assumeRoleConfig.RoleArn, assumedAccount)
+	supervisor.markAssumed(assumeRoleConfig, assumedAccount)
+	addAssumedRole(awsConfig, assumeRoleConfig, assumedAccount)
+}
+
+func removeAssumedRole(roleArn string) {
+	supervisor.forget(roleArn)
+	accountsMutex.Lock()
+	defer accountsMutex.Unlock()
+	for key, access := range accounts {
+		if access.AssumeRole != nil && *access.AssumeRole == roleArn {
+			delete(accounts, key)
+		}
+	}
+}
+
+func getPartition(callerArn string, region string) string {
+	parsed, err := arn.Parse(callerArn)
+	if err != nil {
+		return PartitionForRegion(region)
+	}
+	return parsed.Partition
+}
+
+// AddAwsAccessListener registers a callback which is invoked for every AwsAccess that is added after the initial setup,
+// e.g. when a role that failed during startup could finally be assumed.
+func AddAwsAccessListener(listener func(account *AwsAccess)) {
+	accountsMutex.Lock()
+	defer accountsMutex.Unlock()
+	accessListeners = append(accessListeners, listener)
+}
+
+func addAssumedRole(awsConfig aws.Config, assumeRole extConfig.AssumeRole, account string) {
+	added := prepareRegionConfigs(awsConfig, &assumeRole.RoleArn, account, assumeRole.Regions, assumeRole.TagFilters)
+	notifyAccessListeners(added)
+}
+
+func notifyAccessListeners(added []AwsAccess) {
+	accountsMutex.RLock()
+	listeners := accessListeners
+	accountsMutex.RUnlock()
+	for _, access := range added {
+		for _, listener := range listeners {
+			listener(&access)
+		}
+	}
+}
+
+func prepareRegionConfigs(awsConfig aws.Config, assumedRole *string, account string, regions []string, tagFilters []extConfig.TagFilter) []AwsAccess {
+	accountsMutex.Lock()
+	defer accountsMutex.Unlock()
+	added := make([]AwsAccess, 0, len(regions))
 	for _, region := range regions {
 		regionalConfig := awsConfig.Copy()
 		regionalConfig.Region = region
-		accounts[getMapKey(account, region, assumedRole)] = AwsAccess{
+		if extConfig.Config().AwsApiRateLimit > 0 {
+			regionalConfig.APIOptions = append(slices.Clone(awsConfig.APIOptions), addRateLimitMiddleware(account))
+		}
+		access := AwsAccess{
 			AccountNumber: account,
 			AwsConfig:     regionalConfig,
 			Region:        region,
 			AssumeRole:    assumedRole,
 			TagFilters:    tagFilters,
 		}
+		accounts[getMapKey(account, region, assumedRole)] = access
+		added = append(added, access)
 	}
+	return added
 }
 
 func getMapKey(account string, region string, assumedRole *string) string {
@@ -101,7 +306,9 @@ func GetRootAccountNumber() string {
 
 func GetAwsAccess(accountNumber string, region string, assumedRole *string) (*AwsAccess, error) {
 	mapKey := getMapKey(accountNumber, region, assumedRole)
+	accountsMutex.RLock()
 	account, ok := accounts[mapKey]
+	accountsMutex.RUnlock()
 	if ok {
 		return &account, nil
 	}
@@ -112,33 +319,86 @@ func GetAwsAccess(accountNumber string, region string, assumedRole *string) (*Aw
 	}
 }
 
+// GetConfiguredAwsAccesses returns a snapshot of all configured accesses, ordered by account, region and role.
+func GetConfiguredAwsAccesses() []AwsAccess {
+	accountsMutex.RLock()
+	keys := make([]string, 0, len(accounts))
+	for key := range accounts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]AwsAccess, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, accounts[key])
+	}
+	accountsMutex.RUnlock()
+	return result
+}
+
+// ForEveryConfiguredAwsAccess calls the supplier for every configured account and merges the targets. Accounts which
+// fail or time out contribute their last good targets instead. Failing accounts are always logged and reported by the
+// account health (see GetAccountHealth). An AccountDiscoveryError is only returned if no targets are left, as it makes
+// the discovery drop the targets of the other accounts, so that an unreachable account is not mistaken for an account
+// without targets.
 func ForEveryConfiguredAwsAccess(supplier func(account *AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error), ctx context.Context, discovery string) ([]discovery_kit_api.Target, error) {
-	count := len(accounts)
+	accountsMutex.RLock()
+	snapshot := make([]AwsAccess, 0, len(accounts))
+	for _, account := range accounts {
+		snapshot = append(snapshot, account)
+	}
+	accountsMutex.RUnlock()
+	forgetRemovedAccounts(discovery, snapshot)
+
+	type accountResult struct {
+		targets []discovery_kit_api.Target
+		failure *AccountDiscoveryFailure
+	}
+
+	count := len(snapshot)
 	if count > 0 {
 		accountsChannel := make(chan AwsAccess, count)
-		resultsChannel := make(chan []discovery_kit_api.Target, count)
-		for w := 1; w <= extConfig.Config.WorkerThreads; w++ {
-			go func(w int, accounts <-chan AwsAccess, result <-chan []discovery_kit_api.Target) {
+		resultsChannel := make(chan accountResult, count)
+		for w := 1; w <= extConfig.Config().WorkerThreads; w++ {
+			go func(w int, accounts <-chan AwsAccess, result chan<- accountResult) {
 				for account := range accounts {
 					log.Trace().Str("role", aws.ToString(account.AssumeRole)).Str("account", account.AccountNumber).Str("region", account.Region).Int("worker", w).Msgf("Collecting %s", discovery)
-					eachResult, eachErr := supplier(&account, ctx)
-					if eachErr != nil {
+					start := time.Now()
+					eachResult, eachErr := discoverAccount(ctx, supplier, &account)
+					recordDiscovery(discovery, &account, time.Since(start), len(eachResult), eachErr)
+					recordAccountHealth(ctx, discovery, &account, eachErr)
+					if eachErr == nil {
+						targets, _ := rememberTargets(discovery, &account, eachResult, nil)
+						result <- accountResult{targets: targets}
+						continue
+					}
+					staleResult, stale := rememberTargets(discovery, &account, nil, eachErr)
+					if stale {
+						log.Warn().Err(eachErr).Str("role", aws.ToString(account.AssumeRole)).Str("account", account.AccountNumber).Str("region", account.Region).Msgf("Failed to collect %s, serving %d targets of the last successful discovery", discovery, len(staleResult))
+					} else {
 						log.Err(eachErr).Str("role", aws.ToString(account.AssumeRole)).Str("account", account.AccountNumber).Str("region", account.Region).Msgf("Failed to collect %s", discovery)
 					}
-					resultsChannel <- eachResult
+					result <- accountResult{targets: staleResult, failure: &AccountDiscoveryFailure{Account: account.AccountNumber, Region: account.Region, Role: account.AssumeRole, Err: eachErr}}
 				}
 			}(w, accountsChannel, resultsChannel)
 		}
-		for _, account := range accounts {
+		for _, account := range snapshot {
 			accountsChannel <- account
 		}
 		close(accountsChannel)
 		resultTargets := make([]discovery_kit_api.Target, 0)
+		var failures []AccountDiscoveryFailure
 		for a := 1; a <= count; a++ {
-			targets := <-resultsChannel
-			if targets != nil {
-				resultTargets = append(resultTargets, targets...)
+			result := <-resultsChannel
+			if result.targets != nil {
+				resultTargets = append(resultTargets, result.targets...)
 			}
+			if result.failure != nil {
+				failures = append(failures, *result.failure)
+			}
+		}
+		// failing accounts are logged by the workers and reported in the account health, partial results are no error
+		if len(failures) > 0 && len(resultTargets) == 0 {
+			return resultTargets, &AccountDiscoveryError{Discovery: discovery, Accounts: count, Failures: failures}
 		}
 		return resultTargets, nil
 	}
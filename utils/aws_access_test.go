@@ -4,13 +4,19 @@
 package utils
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
 	"github.com/steadybit/extension-aws/v2/config"
 	"github.com/stretchr/testify/require"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -46,7 +52,7 @@ func TestGetAccountReportsErrorWhenMissing(t *testing.T) {
 }
 
 func TestForEachAccountWithoutRoleAssumption(t *testing.T) {
-	config.Config.WorkerThreads = 1
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 1 })
 	accounts = getTestAccountsWithoutRoleAssumption()
 
 	result, err := ForEveryConfiguredAwsAccess(getTestFunction(nil, nil), context.Background(), "discovery")
@@ -60,7 +66,7 @@ func TestForEachAccountWithoutRoleAssumption(t *testing.T) {
 }
 
 func TestForEachAccountWithRoleAssumptionAndSingleWorker(t *testing.T) {
-	config.Config.WorkerThreads = 1
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 1 })
 	accounts = getTestAccountsWithRoleAssumption()
 
 	result, err := ForEveryConfiguredAwsAccess(getTestFunction(nil, nil), context.Background(), "discovery")
@@ -76,7 +82,7 @@ func TestForEachAccountWithRoleAssumptionAndSingleWorker(t *testing.T) {
 }
 
 func TestForEachAccountWithRoleAssumptionAndMultipleWorkers(t *testing.T) {
-	config.Config.WorkerThreads = 4
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 4 })
 	accounts = getTestAccountsWithRoleAssumption()
 
 	result, err := ForEveryConfiguredAwsAccess(getTestFunction(nil, nil), context.Background(), "discovery")
@@ -92,7 +98,7 @@ func TestForEachAccountWithRoleAssumptionAndMultipleWorkers(t *testing.T) {
 }
 
 func TestForEachAccountWithRoleAssumptionAndError(t *testing.T) {
-	config.Config.WorkerThreads = 4
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 4 })
 	accounts = getTestAccountsWithRoleAssumption()
 
 	result, err := ForEveryConfiguredAwsAccess(getTestFunction(new("22222222"), nil), context.Background(), "discovery")
@@ -107,8 +113,27 @@ func TestForEachAccountWithRoleAssumptionAndError(t *testing.T) {
 	require.Equal(t, []string{"11111111@eu-central-1@arn:aws:iam::11111111:role/test", "11111111@us-east-1@arn:aws:iam::11111111:role/test", "33333333@us-east-1@arn:aws:iam::33333333:role/test1", "33333333@us-east-1@arn:aws:iam::33333333:role/test2"}, values)
 }
 
+func TestForEachAccountLogsEveryFailingAccountOnce(t *testing.T) {
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 4 })
+	accounts = getTestAccountsWithRoleAssumption()
+	var output bytes.Buffer
+	originalLogger := log.Logger
+	log.Logger = zerolog.New(&output).Level(zerolog.WarnLevel)
+	defer func() { log.Logger = originalLogger }()
+
+	_, err := ForEveryConfiguredAwsAccess(getTestFunction(new("22222222"), nil), context.Background(), "discovery-logged-once")
+
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 2, "one line for each region of the failing account")
+	for _, line := range lines {
+		require.Contains(t, line, "22222222")
+		require.Contains(t, line, "Failed to collect discovery-logged-once")
+	}
+}
+
 func TestForEachAccountWithRoleAssumptionAndEmptyLists(t *testing.T) {
-	config.Config.WorkerThreads = 4
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 4 })
 	accounts = getTestAccountsWithRoleAssumption()
 
 	result, err := ForEveryConfiguredAwsAccess(getTestFunction(nil, new("22222222")), context.Background(), "discovery")
@@ -123,6 +148,89 @@ func TestForEachAccountWithRoleAssumptionAndEmptyLists(t *testing.T) {
 	require.Equal(t, []string{"11111111@eu-central-1@arn:aws:iam::11111111:role/test", "11111111@us-east-1@arn:aws:iam::11111111:role/test", "33333333@us-east-1@arn:aws:iam::33333333:role/test1", "33333333@us-east-1@arn:aws:iam::33333333:role/test2"}, values)
 }
 
+func TestApplyConfigurationChange(t *testing.T) {
+	accounts = getTestAccountsWithoutRoleAssumption()
+	rootAccountNumber = "12345678"
+	supervisor = newRoleSupervisor()
+	originalIdentify := identifyAccount
+	defer func() { identifyAccount = originalIdentify }()
+	identifyAccount = func(ctx context.Context, awsConfig aws.Config) (string, error) {
+		return "44444444", nil
+	}
+
+	rootOnly := config.Specification{Regions: []string{"us-east-1"}}
+	rootTwoRegions := config.Specification{Regions: []string{"us-east-1", "eu-west-1"}}
+	ApplyConfigurationChange(rootOnly, rootTwoRegions)
+	_, err := GetAwsAccess("12345678", "eu-west-1", nil)
+	require.NoError(t, err)
+
+	withRole := config.Specification{Regions: []string{"us-east-1"}, AssumeRolesAdvanced: config.AssumeRoles{
+		{RoleArn: "arn:aws:iam::44444444:role/test", Regions: []string{"us-east-1"}},
+	}}
+	ApplyConfigurationChange(rootTwoRegions, withRole)
+	_, err = GetAwsAccess("12345678", "us-east-1", nil)
+	require.Error(t, err, "root account is no longer used once roles are configured")
+	_, err = GetAwsAccess("44444444", "us-east-1", new("arn:aws:iam::44444444:role/test"))
+	require.NoError(t, err)
+
+	withChangedRole := config.Specification{Regions: []string{"us-east-1"}, AssumeRolesAdvanced: config.AssumeRoles{
+		{RoleArn: "arn:aws:iam::44444444:role/test", Regions: []string{"eu-west-1"}},
+	}}
+	ApplyConfigurationChange(withRole, withChangedRole)
+	_, err = GetAwsAccess("44444444", "us-east-1", new("arn:aws:iam::44444444:role/test"))
+	require.Error(t, err)
+	_, err = GetAwsAccess("44444444", "eu-west-1", new("arn:aws:iam::44444444:role/test"))
+	require.NoError(t, err)
+
+	ApplyConfigurationChange(withChangedRole, rootOnly)
+	require.Len(t, accounts, 1)
+	_, err = GetAwsAccess("12345678", "us-east-1", nil)
+	require.NoError(t, err)
+}
+
+type assumeRoleStsStub struct {
+	input *sts.AssumeRoleInput
+}
+
+func (s *assumeRoleStsStub) AssumeRole(_ context.Context, params *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	s.input = params
+	return &sts.AssumeRoleOutput{Credentials: &ststypes.Credentials{
+		AccessKeyId:     aws.String("key"),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(time.Now().Add(time.Hour)),
+	}}, nil
+}
+
+func TestNewAssumedRoleConfigPassesSessionOptions(t *testing.T) {
+	stub := &assumeRoleStsStub{}
+	originalStsClient := rootStsClient
+	defer func() { rootStsClient = originalStsClient }()
+	rootStsClient = stub
+
+	awsConfig := newAssumedRoleConfig(config.AssumeRole{
+		RoleArn:         "arn:aws:iam::44444444:role/test",
+		ExternalId:      "4711",
+		SessionName:     "steadybit-prod",
+		DurationSeconds: 1800,
+		SessionTags:     map[string]string{"team": "chaos", "env": "prod"},
+	})
+	_, err := awsConfig.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+
+	require.NotNil(t, stub.input)
+	require.Equal(t, "arn:aws:iam::44444444:role/test", aws.ToString(stub.input.RoleArn))
+	require.Equal(t, "4711", aws.ToString(stub.input.ExternalId))
+	require.Equal(t, "steadybit-prod", aws.ToString(stub.input.RoleSessionName))
+	require.Equal(t, int32(1800), aws.ToInt32(stub.input.DurationSeconds))
+	require.Equal(t, []ststypes.Tag{{Key: aws.String("env"), Value: aws.String("prod")}, {Key: aws.String("team"), Value: aws.String("chaos")}}, stub.input.Tags)
+
+	_, err = newAssumedRoleConfig(config.AssumeRole{RoleArn: "arn:aws:iam::44444444:role/test"}).Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, defaultSessionName, aws.ToString(stub.input.RoleSessionName))
+	require.Nil(t, stub.input.ExternalId)
+}
+
 func getTestFunction(errorForAccount *string, emptyForAccount *string) func(account *AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error) {
 	return func(account *AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error) {
 		if (errorForAccount != nil) && (*errorForAccount == account.AccountNumber) {
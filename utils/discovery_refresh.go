@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
+)
+
+// WithRefreshTargetsInterval works like discovery_kit_sdk.WithRefreshTargetsInterval, but the interval (in seconds) is
+// evaluated again after every refresh. Changes of a reloaded configuration are picked up without a restart.
+func WithRefreshTargetsInterval(ctx context.Context, intervalSeconds func() int) discovery_kit_sdk.CachedDiscoveryOpt {
+	return discovery_kit_sdk.WithRefreshTargetsTrigger(ctx, intervalTrigger(ctx, intervalSeconds), time.Second)
+}
+
+func intervalTrigger(ctx context.Context, intervalSeconds func() int) <-chan struct{} {
+	trigger := make(chan struct{})
+	go func() {
+		defer close(trigger)
+		for {
+			select {
+			case <-time.After(time.Duration(max(intervalSeconds(), 1)) * time.Second):
+				select {
+				case trigger <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return trigger
+}
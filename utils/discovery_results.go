@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+)
+
+// AccountDiscoveryFailure describes an account whose discovery failed or timed out.
+type AccountDiscoveryFailure struct {
+	Account string
+	Region  string
+	Role    *string
+	Err     error
+}
+
+// AccountDiscoveryError is returned by ForEveryConfiguredAwsAccess if accounts failed and neither the other accounts nor
+// the previous discoveries provided any targets. It tells an unreachable account apart from an account without targets.
+type AccountDiscoveryError struct {
+	Discovery string
+	Accounts  int
+	Failures  []AccountDiscoveryFailure
+}
+
+func (e *AccountDiscoveryError) Error() string {
+	failures := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		description := fmt.Sprintf("account '%s' in region '%s'", failure.Account, failure.Region)
+		if failure.Role != nil {
+			description += fmt.Sprintf(" (role '%s')", aws.ToString(failure.Role))
+		}
+		failures = append(failures, fmt.Sprintf("%s: %s", description, failure.Err))
+	}
+	return fmt.Sprintf("failed to discover %s in %d of %d accounts: %s", e.Discovery, len(e.Failures), e.Accounts, strings.Join(failures, "; "))
+}
+
+func (e *AccountDiscoveryError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errs = append(errs, failure.Err)
+	}
+	return errs
+}
+
+type lastGoodTargets struct {
+	targets      []discovery_kit_api.Target
+	discoveredAt time.Time
+}
+
+var (
+	lastGoodTargetsByAccount = make(map[string]lastGoodTargets)
+	lastGoodTargetsMutex     sync.Mutex
+)
+
+func lastGoodTargetsKey(discovery string, account *AwsAccess) string {
+	return discovery + "|" + getMapKey(account.AccountNumber, account.Region, account.AssumeRole)
+}
+
+// discoverAccount calls the supplier for one account. If `DiscoveryAccountTimeout` is set, the supplier is abandoned
+// once it has passed, so that a hanging account does not block the discovery of the others.
+func discoverAccount(ctx context.Context, supplier func(account *AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error), account *AwsAccess) ([]discovery_kit_api.Target, error) {
+	if extConfig.Config().DiscoveryAccountTimeout <= 0 {
+		return supplier(account, ctx)
+	}
+	timeout := time.Duration(extConfig.Config().DiscoveryAccountTimeout) * time.Second
+	accountCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		targets []discovery_kit_api.Target
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		targets, err := supplier(account, accountCtx)
+		done <- result{targets, err}
+	}()
+	select {
+	case r := <-done:
+		return r.targets, r.err
+	case <-accountCtx.Done():
+		return nil, fmt.Errorf("timed out after %s: %w", timeout, accountCtx.Err())
+	}
+}
+
+// rememberTargets stores the targets of a successful discovery, or returns the last good targets of the account if the
+// discovery failed. Stale targets are served up to `DiscoveryStaleTargetsMaxAge`.
+func rememberTargets(discovery string, account *AwsAccess, targets []discovery_kit_api.Target, err error) ([]discovery_kit_api.Target, bool) {
+	maxAge := time.Duration(extConfig.Config().DiscoveryStaleTargetsMaxAge) * time.Second
+	if maxAge <= 0 {
+		return targets, false
+	}
+	key := lastGoodTargetsKey(discovery, account)
+
+	lastGoodTargetsMutex.Lock()
+	defer lastGoodTargetsMutex.Unlock()
+	if err == nil {
+		lastGoodTargetsByAccount[key] = lastGoodTargets{targets: targets, discoveredAt: time.Now()}
+		return targets, false
+	}
+	if last, ok := lastGoodTargetsByAccount[key]; ok && time.Since(last.discoveredAt) <= maxAge {
+		return last.targets, true
+	}
+	delete(lastGoodTargetsByAccount, key)
+	return nil, false
+}
+
+// forgetRemovedAccounts drops the last good targets of accounts which are no longer configured.
+func forgetRemovedAccounts(discovery string, configured []AwsAccess) {
+	keys := make(map[string]bool, len(configured))
+	for _, account := range configured {
+		keys[lastGoodTargetsKey(discovery, &account)] = true
+	}
+	lastGoodTargetsMutex.Lock()
+	defer lastGoodTargetsMutex.Unlock()
+	for key := range lastGoodTargetsByAccount {
+		if strings.HasPrefix(key, discovery+"|") && !keys[key] {
+			delete(lastGoodTargetsByAccount, key)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachAccountAbandonsHangingAccount(t *testing.T) {
+	config.Update(func(spec *config.Specification) {
+		spec.WorkerThreads = 1
+		spec.DiscoveryAccountTimeout = 1
+	})
+	defer config.Update(func(spec *config.Specification) { spec.DiscoveryAccountTimeout = 0 })
+	accounts = getTestAccountsWithRoleAssumption()
+	working := getTestFunction(nil, nil)
+
+	start := time.Now()
+	result, err := ForEveryConfiguredAwsAccess(func(account *AwsAccess, ctx context.Context) ([]discovery_kit_api.Target, error) {
+		if account.AccountNumber == "22222222" {
+			time.Sleep(time.Hour)
+		}
+		return working(account, ctx)
+	}, context.Background(), "hanging-discovery")
+
+	require.NoError(t, err)
+	assert.Len(t, result, 4)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestForEachAccountServesLastGoodTargetsOfFailingAccount(t *testing.T) {
+	config.Update(func(spec *config.Specification) {
+		spec.WorkerThreads = 4
+		spec.DiscoveryStaleTargetsMaxAge = 60
+	})
+	defer config.Update(func(spec *config.Specification) { spec.DiscoveryStaleTargetsMaxAge = 0 })
+	accounts = getTestAccountsWithRoleAssumption()
+
+	_, err := ForEveryConfiguredAwsAccess(getTestFunction(nil, nil), context.Background(), "stale-discovery")
+	require.NoError(t, err)
+
+	result, err := ForEveryConfiguredAwsAccess(getTestFunction(new("22222222"), nil), context.Background(), "stale-discovery")
+	require.NoError(t, err)
+	assert.Len(t, result, 6, "the targets of the failing account are kept")
+
+	accounts = getTestAccountsWithoutRoleAssumption()
+	_, err = ForEveryConfiguredAwsAccess(getTestFunction(nil, nil), context.Background(), "stale-discovery")
+	require.NoError(t, err)
+	assert.Len(t, lastGoodTargetsByAccount, 1, "the targets of removed accounts are forgotten")
+}
+
+func TestForEachAccountReportsUnreachableAccount(t *testing.T) {
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 1 })
+	accounts = getTestAccountsWithoutRoleAssumption()
+
+	result, err := ForEveryConfiguredAwsAccess(getTestFunction(new("12345678"), nil), context.Background(), "failing-discovery")
+
+	assert.Empty(t, result)
+	var discoveryErr *AccountDiscoveryError
+	require.ErrorAs(t, err, &discoveryErr)
+	assert.Equal(t, "failed to discover failing-discovery in 1 of 1 accounts: account '12345678' in region 'us-east-1': damn broken discovery", err.Error())
+}
+
+func TestForEachAccountReportsNoErrorForAccountWithoutTargets(t *testing.T) {
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 1 })
+	accounts = getTestAccountsWithoutRoleAssumption()
+
+	result, err := ForEveryConfiguredAwsAccess(getTestFunction(nil, new("12345678")), context.Background(), "empty-discovery")
+
+	assert.Empty(t, result)
+	assert.NoError(t, err)
+}
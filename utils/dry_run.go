@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const dryRunParameterName = "dryRun"
+
+// ActionWithRequiredPermissions is implemented by actions which list the IAM actions they call. A dry run simulates
+// these IAM actions for the role used to access the target.
+type ActionWithRequiredPermissions interface {
+	RequiredPermissions() []string
+}
+
+// ActionWithDryRun is implemented by actions which check their permissions themselves during a dry run, e.g. by using
+// the DryRun flag of the EC2 API. The returned messages are reported as the result of the start.
+type ActionWithDryRun[T any] interface {
+	DryRun(ctx context.Context, state *T) ([]action_kit_api.Message, error)
+}
+
+// DryRunState identifies the AWS access used for the target of a dry run.
+type DryRunState struct {
+	Account     string  `json:"account"`
+	Region      string  `json:"region"`
+	Role        *string `json:"role,omitempty"`
+	ResourceArn string  `json:"resourceArn,omitempty"`
+}
+
+type DryRunIamApi interface {
+	GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+	SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+}
+
+type DryRunStsApi interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+var simulationClientProvider = func(account string, region string, role *string) (DryRunIamApi, DryRunStsApi, *AwsAccess, error) {
+	awsAccess, err := GetAwsAccess(account, region, role)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return iam.NewFromConfig(awsAccess.AwsConfig), sts.NewFromConfig(awsAccess.AwsConfig), awsAccess, nil
+}
+
+func dryRunParameter() action_kit_api.ActionParameter {
+	return action_kit_api.ActionParameter{
+		Name:         dryRunParameterName,
+		Label:        "Dry Run",
+		Description:  new("Only check the permissions needed by the attack, without changing anything."),
+		Type:         action_kit_api.ActionParameterTypeBoolean,
+		DefaultValue: new("false"),
+		Advanced:     new(true),
+	}
+}
+
+func isDryRun(request action_kit_api.PrepareActionRequestBody) bool {
+	return extConfig.Config().DryRun || extutil.ToBool(request.Config[dryRunParameterName])
+}
+
+func newDryRunState(target *action_kit_api.Target) *DryRunState {
+	state := &DryRunState{}
+	if target == nil {
+		return state
+	}
+	if values := target.Attributes["aws.account"]; len(values) > 0 {
+		state.Account = values[0]
+	}
+	if values := target.Attributes["aws.region"]; len(values) > 0 {
+		state.Region = values[0]
+	}
+	if values := target.Attributes["aws.arn"]; len(values) > 0 {
+		state.ResourceArn = values[0]
+	}
+	state.Role = GetOptionalTargetAttribute(target.Attributes, "extension-aws.discovered-by-role")
+	return state
+}
+
+// SimulatePermissions checks with the IAM policy simulator whether the principal used for the account may call the
+// given IAM actions on the resource. The simulator needs `iam:SimulatePrincipalPolicy` and, if the extension runs with
+// an assumed role of its own, `iam:GetRole`.
+func SimulatePermissions(ctx context.Context, state *DryRunState, actions []string) ([]action_kit_api.Message, error) {
+	if len(actions) == 0 {
+		return []action_kit_api.Message{dryRunMessage(action_kit_api.Warn, "The action does not declare the permissions it needs. Nothing was checked.")}, nil
+	}
+	iamClient, stsClient, awsAccess, err := simulationClientProvider(state.Account, state.Region, state.Role)
+	if err != nil {
+		return nil, err
+	}
+	principal, err := principalArn(ctx, iamClient, stsClient, awsAccess)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the IAM principal: %w", err)
+	}
+	resource := state.ResourceArn
+	if resource == "" {
+		resource = "*"
+	}
+
+	results, err := simulatePrincipalPolicy(ctx, iamClient, principal, actions, resource)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]action_kit_api.Message, 0, len(results))
+	for _, result := range results {
+		action := aws.ToString(result.EvalActionName)
+		if result.EvalDecision == iamtypes.PolicyEvaluationDecisionTypeAllowed {
+			messages = append(messages, dryRunMessage(action_kit_api.Info, fmt.Sprintf("%s is allowed for %s on %s.", action, principal, resource)))
+		} else {
+			messages = append(messages, dryRunMessage(action_kit_api.Warn, fmt.Sprintf("%s is denied (%s) for %s on %s.", action, result.EvalDecision, principal, resource)))
+		}
+	}
+	return messages, nil
+}
+
+func simulatePrincipalPolicy(ctx context.Context, iamClient DryRunIamApi, principal string, actions []string, resource string) ([]iamtypes.EvaluationResult, error) {
+	results := make([]iamtypes.EvaluationResult, 0, len(actions))
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(iamClient, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal),
+		ActionNames:     actions,
+		ResourceArns:    []string{resource},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate the IAM policies of %s: %w", principal, err)
+		}
+		results = append(results, page.EvaluationResults...)
+	}
+	return results, nil
+}
+
+// principalArn returns the ARN of the role or user used for the account. Sessions of assumed roles are resolved to the
+// role, as the policy simulator does not accept session ARNs.
+func principalArn(ctx context.Context, iamClient DryRunIamApi, stsClient DryRunStsApi, awsAccess *AwsAccess) (string, error) {
+	if awsAccess.AssumeRole != nil {
+		return *awsAccess.AssumeRole, nil
+	}
+	identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+	callerArn := aws.ToString(identity.Arn)
+	parsed, err := arn.Parse(callerArn)
+	if err != nil || !strings.HasPrefix(parsed.Resource, "assumed-role/") {
+		return callerArn, nil
+	}
+	roleName := strings.Split(strings.TrimPrefix(parsed.Resource, "assumed-role/"), "/")[0]
+	role, err := iamClient.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(role.Role.Arn), nil
+}
+
+// Ec2DryRunMessage converts the result of an EC2 call made with `DryRun` into a message. EC2 answers an authorized dry
+// run with the `DryRunOperation` error and a denied one with `UnauthorizedOperation`. Any other error is returned.
+func Ec2DryRunMessage(operation string, err error) (action_kit_api.Message, error) {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "DryRunOperation":
+			return dryRunMessage(action_kit_api.Info, fmt.Sprintf("ec2:%s is allowed.", operation)), nil
+		case "UnauthorizedOperation":
+			return dryRunMessage(action_kit_api.Warn, fmt.Sprintf("ec2:%s is denied.", operation)), nil
+		}
+	}
+	if err == nil {
+		return action_kit_api.Message{}, fmt.Errorf("ec2:%s was executed although DryRun was set", operation)
+	}
+	return action_kit_api.Message{}, err
+}
+
+func dryRunMessage(level action_kit_api.MessageLevel, message string) action_kit_api.Message {
+	return action_kit_api.Message{Level: extutil.Ptr(level), Message: "Dry run: " + message}
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type dryRunIamMock struct {
+	mock.Mock
+}
+
+func (m *dryRunIamMock) GetRole(ctx context.Context, params *iam.GetRoleInput, _ ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*iam.GetRoleOutput), args.Error(1)
+}
+
+func (m *dryRunIamMock) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, _ ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*iam.SimulatePrincipalPolicyOutput), args.Error(1)
+}
+
+type dryRunStsMock struct {
+	mock.Mock
+}
+
+func (m *dryRunStsMock) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, _ ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*sts.GetCallerIdentityOutput), args.Error(1)
+}
+
+func mockDryRunClients(t *testing.T, iamClient DryRunIamApi, stsClient DryRunStsApi, awsAccess *AwsAccess) {
+	original := simulationClientProvider
+	t.Cleanup(func() { simulationClientProvider = original })
+	simulationClientProvider = func(account string, region string, role *string) (DryRunIamApi, DryRunStsApi, *AwsAccess, error) {
+		return iamClient, stsClient, awsAccess, nil
+	}
+}
+
+func TestSimulatePermissionsResolvesAssumedRoleSession(t *testing.T) {
+	iamClient := new(dryRunIamMock)
+	stsClient := new(dryRunStsMock)
+	mockDryRunClients(t, iamClient, stsClient, &AwsAccess{AccountNumber: "123456789012", Region: "eu-central-1"})
+
+	stsClient.On("GetCallerIdentity", mock.Anything, mock.Anything).Return(&sts.GetCallerIdentityOutput{
+		Arn: aws.String("arn:aws:sts::123456789012:assumed-role/steadybit-extension-aws/session"),
+	}, nil)
+	iamClient.On("GetRole", mock.Anything, mock.MatchedBy(func(params *iam.GetRoleInput) bool {
+		return aws.ToString(params.RoleName) == "steadybit-extension-aws"
+	})).Return(&iam.GetRoleOutput{Role: &iamtypes.Role{Arn: aws.String("arn:aws:iam::123456789012:role/path/steadybit-extension-aws")}}, nil)
+	iamClient.On("SimulatePrincipalPolicy", mock.Anything, mock.MatchedBy(func(params *iam.SimulatePrincipalPolicyInput) bool {
+		return aws.ToString(params.PolicySourceArn) == "arn:aws:iam::123456789012:role/path/steadybit-extension-aws" &&
+			assert.ObjectsAreEqual([]string{"arn:aws:dynamodb:eu-central-1:123456789012:table/orders"}, params.ResourceArns)
+	})).Return(&iam.SimulatePrincipalPolicyOutput{
+		EvaluationResults: []iamtypes.EvaluationResult{
+			{EvalActionName: aws.String("dynamodb:DescribeTable"), EvalDecision: iamtypes.PolicyEvaluationDecisionTypeAllowed},
+			{EvalActionName: aws.String("dynamodb:UpdateTable"), EvalDecision: iamtypes.PolicyEvaluationDecisionTypeImplicitDeny},
+		},
+	}, nil)
+
+	messages, err := SimulatePermissions(context.Background(), &DryRunState{
+		Account:     "123456789012",
+		Region:      "eu-central-1",
+		ResourceArn: "arn:aws:dynamodb:eu-central-1:123456789012:table/orders",
+	}, []string{"dynamodb:DescribeTable", "dynamodb:UpdateTable"})
+
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, action_kit_api.Info, *messages[0].Level)
+	assert.Contains(t, messages[0].Message, "dynamodb:DescribeTable is allowed")
+	assert.Equal(t, action_kit_api.Warn, *messages[1].Level)
+	assert.Contains(t, messages[1].Message, "dynamodb:UpdateTable is denied (implicitDeny)")
+	iamClient.AssertExpectations(t)
+	stsClient.AssertExpectations(t)
+}
+
+func TestSimulatePermissionsUsesConfiguredRole(t *testing.T) {
+	iamClient := new(dryRunIamMock)
+	stsClient := new(dryRunStsMock)
+	mockDryRunClients(t, iamClient, stsClient, &AwsAccess{AssumeRole: aws.String("arn:aws:iam::123456789012:role/target")})
+
+	iamClient.On("SimulatePrincipalPolicy", mock.Anything, mock.MatchedBy(func(params *iam.SimulatePrincipalPolicyInput) bool {
+		return aws.ToString(params.PolicySourceArn) == "arn:aws:iam::123456789012:role/target" && params.ResourceArns[0] == "*"
+	})).Return(&iam.SimulatePrincipalPolicyOutput{}, nil)
+
+	_, err := SimulatePermissions(context.Background(), &DryRunState{}, []string{"ecs:StopTask"})
+
+	require.NoError(t, err)
+	iamClient.AssertExpectations(t)
+	stsClient.AssertNotCalled(t, "GetCallerIdentity", mock.Anything, mock.Anything)
+}
+
+func TestEc2DryRunMessage(t *testing.T) {
+	message, err := Ec2DryRunMessage("StopInstances", &smithy.GenericAPIError{Code: "DryRunOperation"})
+	require.NoError(t, err)
+	assert.Equal(t, action_kit_api.Info, *message.Level)
+
+	message, err = Ec2DryRunMessage("StopInstances", &smithy.GenericAPIError{Code: "UnauthorizedOperation"})
+	require.NoError(t, err)
+	assert.Equal(t, action_kit_api.Warn, *message.Level)
+
+	_, err = Ec2DryRunMessage("StopInstances", errors.New("network down"))
+	assert.Error(t, err)
+}
+
+type dryRunTestAction struct {
+	started bool
+}
+
+func (a *dryRunTestAction) NewEmptyState() string { return "" }
+func (a *dryRunTestAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{Id: "dry-run-test-action", Kind: action_kit_api.Attack}
+}
+func (a *dryRunTestAction) Prepare(_ context.Context, _ *string, _ action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	return nil, nil
+}
+func (a *dryRunTestAction) Start(_ context.Context, _ *string) (*action_kit_api.StartResult, error) {
+	a.started = true
+	return nil, nil
+}
+func (a *dryRunTestAction) DryRun(_ context.Context, _ *string) ([]action_kit_api.Message, error) {
+	return []action_kit_api.Message{{Message: "checked"}}, nil
+}
+
+func TestInstrumentedActionDryRun(t *testing.T) {
+	delegate := &dryRunTestAction{}
+	action := &instrumentedAction[string]{delegate: delegate, id: "dry-run-test-action"}
+
+	description := action.Describe()
+	assert.Equal(t, dryRunParameterName, description.Parameters[len(description.Parameters)-1].Name)
+
+	state := action.NewEmptyState()
+	prepareResult, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Config:      map[string]any{dryRunParameterName: true},
+		Target:      &action_kit_api.Target{Attributes: map[string][]string{"aws.account": {"123456789012"}, "aws.region": {"eu-central-1"}}},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, state.DryRun)
+	assert.Equal(t, "123456789012", state.DryRun.Account)
+	assert.Len(t, *prepareResult.Messages, 1)
+
+	startResult, err := action.Start(context.Background(), &state)
+	require.NoError(t, err)
+	assert.False(t, delegate.started)
+	assert.Equal(t, "checked", (*startResult.Messages)[0].Message)
+}
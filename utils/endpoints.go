@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"strings"
+)
+
+// serviceEndpointOverrides provides the base endpoints of `AwsEndpointOverrides` to the clients of all services. It is
+// picked up by the same resolution as the `AWS_ENDPOINT_URL_<SERVICE>` environment variables, so services without an
+// entry fall back to the global `AwsEndpointOverride`.
+type serviceEndpointOverrides map[string]string
+
+func newServiceEndpointOverrides(overrides map[string]string) serviceEndpointOverrides {
+	result := make(serviceEndpointOverrides, len(overrides))
+	for serviceId, endpoint := range overrides {
+		result[normalizeServiceId(serviceId)] = endpoint
+	}
+	return result
+}
+
+// GetServiceBaseEndpoint is called by every client with the SDK id of its service, e.g. `EC2` or `Elastic Load
+// Balancing v2`.
+func (o serviceEndpointOverrides) GetServiceBaseEndpoint(_ context.Context, sdkID string) (string, bool, error) {
+	endpoint, ok := o[normalizeServiceId(sdkID)]
+	return endpoint, ok, nil
+}
+
+// normalizeServiceId allows the ids to be written like in the SDK, in lower case or like in the environment variables,
+// e.g. `elastic_load_balancing_v2`.
+func normalizeServiceId(serviceId string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(serviceId))
+}
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceEndpointOverridesApplyToClients(t *testing.T) {
+	awsConfig := aws.Config{
+		Region:       "eu-central-1",
+		BaseEndpoint: aws.String("http://localstack:4566"),
+		ConfigSources: []any{newServiceEndpointOverrides(map[string]string{
+			"EC2":                       "http://ec2-mock:8080",
+			"elastic_load_balancing_v2": "http://elb-mock:8080",
+		})},
+	}
+
+	assert.Equal(t, "http://ec2-mock:8080", aws.ToString(ec2.NewFromConfig(awsConfig).Options().BaseEndpoint))
+	assert.Equal(t, "http://elb-mock:8080", aws.ToString(elasticloadbalancingv2.NewFromConfig(awsConfig).Options().BaseEndpoint))
+	assert.Equal(t, "http://localstack:4566", aws.ToString(sts.NewFromConfig(awsConfig).Options().BaseEndpoint), "falls back to the global override")
+	assert.Equal(t, "http://ec2-mock:8080", aws.ToString(ec2.NewFromConfig(awsConfig.Copy()).Options().BaseEndpoint), "copies keep the overrides")
+}
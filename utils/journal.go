@@ -0,0 +1,285 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+)
+
+// Every extension process owns the entries of the attacks it started and renews their heartbeat every minute. Entries
+// are only rolled back once their heartbeat is older than journalLeaseTimeout, i.e. the owner is gone. Other replicas or
+// installations sharing the journal never roll back the running attacks of each other.
+
+const (
+	journalRollbackInterval    = time.Minute
+	journalLeaseTimeout        = 5 * time.Minute
+	journalRolledBackRetention = 24 * time.Hour
+)
+
+// JournalEntry records a started attack together with the state needed to restore its target.
+type JournalEntry struct {
+	ExecutionId      string          `json:"executionId"`
+	ActionId         string          `json:"actionId"`
+	State            json.RawMessage `json:"state"`
+	StartedAt        time.Time       `json:"startedAt"`
+	OwnerId          string          `json:"ownerId,omitempty"`
+	HeartbeatAt      time.Time       `json:"heartbeatAt"`
+	RolledBackAt     *time.Time      `json:"rolledBackAt,omitempty"`
+	RollbackAttempts int             `json:"rollbackAttempts,omitempty"`
+}
+
+// ownerGone returns true if the owner of the entry has not renewed its heartbeat within the lease timeout.
+func (e JournalEntry) ownerGone(now time.Time) bool {
+	heartbeatAt := e.HeartbeatAt
+	if heartbeatAt.IsZero() {
+		heartbeatAt = e.StartedAt
+	}
+	return now.Sub(heartbeatAt) > journalLeaseTimeout
+}
+
+// JournalStore persists journal entries. Entries are keyed by their execution id.
+type JournalStore interface {
+	Put(ctx context.Context, entry JournalEntry) error
+	Get(ctx context.Context, executionId string) (*JournalEntry, error)
+	Delete(ctx context.Context, executionId string) error
+	List(ctx context.Context) ([]JournalEntry, error)
+}
+
+type journalRollback func(ctx context.Context, state json.RawMessage) error
+
+var (
+	journalStore     JournalStore
+	journalRollbacks = map[string]journalRollback{}
+	journalMutex     sync.Mutex
+
+	// journalOwnerId identifies this process, a restarted extension is a new owner.
+	journalOwnerId = uuid.NewString()
+	// journalOwnEntries holds the running attacks of this process, whose heartbeat is renewed. The mutex is held while
+	// writing them, so that a heartbeat never recreates the entry of a stopped attack.
+	journalOwnEntries      = map[string]JournalEntry{}
+	journalOwnEntriesMutex sync.Mutex
+)
+
+// InitializeAttackJournal creates the configured journal store. Must be called after InitializeAwsAccess, as the S3
+// and DynamoDB stores use the credentials of the extension itself.
+func InitializeAttackJournal() {
+	store, err := newJournalStore(*extConfig.Config())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to initialize the attack journal. Attacks are not journaled.")
+		return
+	}
+	journalStore = store
+}
+
+func newJournalStore(spec extConfig.Specification) (JournalStore, error) {
+	switch spec.AttackJournalBackend {
+	case "file":
+		return newFileJournalStore(filepath.Join(spec.AttackJournalFileDirectory, spec.AttackJournalScope))
+	case "s3":
+		return &s3JournalStore{client: s3.NewFromConfig(rootAwsConfig), bucket: spec.AttackJournalS3Bucket, prefix: spec.AttackJournalS3Prefix + spec.AttackJournalScope + "/"}, nil
+	case "dynamodb":
+		return &dynamodbJournalStore{client: dynamodb.NewFromConfig(rootAwsConfig), table: spec.AttackJournalDynamodbTable, scope: spec.AttackJournalScope}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func registerJournalRollback(actionId string, rollback journalRollback) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+	journalRollbacks[actionId] = rollback
+}
+
+func getJournalRollback(actionId string) (journalRollback, bool) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+	rollback, ok := journalRollbacks[actionId]
+	return rollback, ok
+}
+
+// journalAttackStarted records the attack before and after it mutates the target. Failures are logged, the attack
+// itself is not prevented by an unavailable journal.
+func journalAttackStarted(ctx context.Context, actionId string, executionId string, state any) {
+	if journalStore == nil || executionId == "" {
+		return
+	}
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to encode the state of execution %s for the attack journal.", executionId)
+		return
+	}
+	journalOwnEntriesMutex.Lock()
+	defer journalOwnEntriesMutex.Unlock()
+	now := time.Now()
+	entry, ok := journalOwnEntries[executionId]
+	if !ok {
+		entry = JournalEntry{ExecutionId: executionId, ActionId: actionId, StartedAt: now, OwnerId: journalOwnerId}
+	}
+	entry.State = encoded
+	entry.HeartbeatAt = now
+	journalOwnEntries[executionId] = entry
+	if err := journalStore.Put(ctx, entry); err != nil {
+		log.Error().Err(err).Msgf("Failed to write execution %s to the attack journal.", executionId)
+	}
+}
+
+// journalAttackRolledBack returns true if the target of the execution has already been restored on startup. The
+// caller must not restore it a second time.
+func journalAttackRolledBack(ctx context.Context, executionId string) bool {
+	if journalStore == nil || executionId == "" {
+		return false
+	}
+	entry, err := journalStore.Get(ctx, executionId)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to read execution %s from the attack journal.", executionId)
+		return false
+	}
+	return entry != nil && entry.RolledBackAt != nil
+}
+
+func journalAttackStopped(ctx context.Context, executionId string) {
+	if journalStore == nil || executionId == "" {
+		return
+	}
+	journalOwnEntriesMutex.Lock()
+	defer journalOwnEntriesMutex.Unlock()
+	delete(journalOwnEntries, executionId)
+	if err := journalStore.Delete(ctx, executionId); err != nil {
+		log.Error().Err(err).Msgf("Failed to remove execution %s from the attack journal.", executionId)
+	}
+}
+
+// StartAttackJournalRollback renews the heartbeat of the running attacks every minute and stops every journaled attack
+// whose owner is gone, e.g. because the extension was terminated during the attack. Failed rollbacks are retried every
+// minute up to AttackJournalMaxRollbackAttempts.
+func StartAttackJournalRollback(ctx context.Context) {
+	if journalStore == nil {
+		return
+	}
+	log.Info().Msgf("Journaling attacks as owner %s.", journalOwnerId)
+	go func() {
+		for {
+			heartbeatJournaledAttacks(ctx)
+			rollbackJournaledAttacks(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(journalRollbackInterval):
+			}
+		}
+	}()
+}
+
+func heartbeatJournaledAttacks(ctx context.Context) {
+	journalOwnEntriesMutex.Lock()
+	defer journalOwnEntriesMutex.Unlock()
+	for executionId, entry := range journalOwnEntries {
+		entry.HeartbeatAt = time.Now()
+		journalOwnEntries[executionId] = entry
+		if err := journalStore.Put(ctx, entry); err != nil {
+			log.Warn().Err(err).Msgf("Failed to renew the heartbeat of execution %s in the attack journal.", executionId)
+		}
+	}
+}
+
+func isOwnRunningAttack(executionId string) bool {
+	journalOwnEntriesMutex.Lock()
+	defer journalOwnEntriesMutex.Unlock()
+	_, ok := journalOwnEntries[executionId]
+	return ok
+}
+
+// rollbackJournaledAttacks returns the number of entries which still need to be rolled back.
+func rollbackJournaledAttacks(ctx context.Context) int {
+	entries, err := journalStore.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read the attack journal.")
+		return 1
+	}
+	pending := 0
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.RolledBackAt != nil {
+			if now.Sub(*entry.RolledBackAt) > journalRolledBackRetention {
+				journalAttackStopped(ctx, entry.ExecutionId)
+			}
+			continue
+		}
+		// entries taken over by this process for a rollback are retried regardless of their heartbeat
+		if entry.OwnerId == journalOwnerId {
+			if isOwnRunningAttack(entry.ExecutionId) {
+				continue
+			}
+		} else if !entry.ownerGone(now) {
+			continue
+		}
+		if !rollbackJournaledAttack(ctx, entry) {
+			pending++
+		}
+	}
+	return pending
+}
+
+// rollbackJournaledAttack returns false if the rollback failed and should be retried.
+func rollbackJournaledAttack(ctx context.Context, entry JournalEntry) bool {
+	rollback, ok := getJournalRollback(entry.ActionId)
+	if !ok {
+		log.Warn().Msgf("Execution %s of action %s is in the attack journal, but the action is not registered. Skipping rollback.", entry.ExecutionId, entry.ActionId)
+		return true
+	}
+
+	if entry.OwnerId != journalOwnerId {
+		// take over the entry, so that other replicas leave it alone while it is rolled back
+		previousOwnerId := entry.OwnerId
+		entry.OwnerId = journalOwnerId
+		entry.HeartbeatAt = time.Now()
+		if err := journalStore.Put(ctx, entry); err != nil {
+			log.Warn().Err(err).Msgf("Failed to take over execution %s in the attack journal. Retrying.", entry.ExecutionId)
+			return false
+		}
+		log.Info().Msgf("Rolling back execution %s of action %s started at %s by owner %s, which is gone.", entry.ExecutionId, entry.ActionId, entry.StartedAt.Format(time.RFC3339), previousOwnerId)
+	}
+	err := rollback(ctx, entry.State)
+	if err == nil {
+		entry.RolledBackAt = new(time.Now())
+		if err := journalStore.Put(ctx, entry); err != nil {
+			log.Error().Err(err).Msgf("Failed to mark execution %s as rolled back in the attack journal.", entry.ExecutionId)
+		}
+		return true
+	}
+
+	entry.RollbackAttempts++
+	if entry.RollbackAttempts >= extConfig.Config().AttackJournalMaxRollbackAttempts {
+		log.Error().Err(err).Msgf("Failed to roll back execution %s of action %s after %d attempts. Giving up, the target needs to be restored manually. State: %s", entry.ExecutionId, entry.ActionId, entry.RollbackAttempts, string(entry.State))
+		journalAttackStopped(ctx, entry.ExecutionId)
+		return true
+	}
+	log.Warn().Err(err).Msgf("Failed to roll back execution %s of action %s (attempt %d). Retrying.", entry.ExecutionId, entry.ActionId, entry.RollbackAttempts)
+	entry.HeartbeatAt = time.Now()
+	if err := journalStore.Put(ctx, entry); err != nil {
+		log.Error().Err(err).Msgf("Failed to update execution %s in the attack journal.", entry.ExecutionId)
+	}
+	return false
+}
+
+func journalRollbackFor[T any](stop func(ctx context.Context, state *T) error) journalRollback {
+	return func(ctx context.Context, encoded json.RawMessage) error {
+		var state T
+		if err := json.Unmarshal(encoded, &state); err != nil {
+			return fmt.Errorf("failed to decode state: %w", err)
+		}
+		return stop(ctx, &state)
+	}
+}
@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type fileJournalStore struct {
+	directory string
+}
+
+func newFileJournalStore(directory string) (*fileJournalStore, error) {
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory '%s': %w", directory, err)
+	}
+	return &fileJournalStore{directory: directory}, nil
+}
+
+func (s *fileJournalStore) path(executionId string) string {
+	return filepath.Join(s.directory, executionId+".json")
+}
+
+func (s *fileJournalStore) Put(_ context.Context, entry JournalEntry) error {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, so that a crash never leaves a truncated entry behind
+	tmp := s.path(entry.ExecutionId) + ".tmp"
+	if err := os.WriteFile(tmp, encoded, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(entry.ExecutionId))
+}
+
+func (s *fileJournalStore) Get(_ context.Context, executionId string) (*JournalEntry, error) {
+	content, err := os.ReadFile(s.path(executionId))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var entry JournalEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *fileJournalStore) Delete(_ context.Context, executionId string) error {
+	err := os.Remove(s.path(executionId))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *fileJournalStore) List(ctx context.Context) ([]JournalEntry, error) {
+	files, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]JournalEntry, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		entry, err := s.Get(ctx, strings.TrimSuffix(file.Name(), ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal entry '%s': %w", file.Name(), err)
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries, nil
+}
+
+type JournalS3Api interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+type s3JournalStore struct {
+	client JournalS3Api
+	bucket string
+	prefix string
+}
+
+func (s *s3JournalStore) key(executionId string) string {
+	return s.prefix + executionId + ".json"
+}
+
+func (s *s3JournalStore) Put(ctx context.Context, entry JournalEntry) error {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.key(entry.ExecutionId)),
+		Body:        bytes.NewReader(encoded),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+func (s *s3JournalStore) Get(ctx context.Context, executionId string) (*JournalEntry, error) {
+	return s.getByKey(ctx, s.key(executionId))
+}
+
+func (s *s3JournalStore) getByKey(ctx context.Context, key string) (*JournalEntry, error) {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	var noSuchKey *s3types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer func() { _ = output.Body.Close() }()
+	content, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, err
+	}
+	var entry JournalEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *s3JournalStore) Delete(ctx context.Context, executionId string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(s.key(executionId))})
+	return err
+}
+
+func (s *s3JournalStore) List(ctx context.Context) ([]JournalEntry, error) {
+	entries := make([]JournalEntry, 0)
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{Bucket: aws.String(s.bucket), Prefix: aws.String(s.prefix)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			entry, err := s.getByKey(ctx, aws.ToString(object.Key))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read journal entry '%s': %w", aws.ToString(object.Key), err)
+			}
+			if entry != nil {
+				entries = append(entries, *entry)
+			}
+		}
+	}
+	return entries, nil
+}
+
+type JournalDynamodbApi interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+// dynamodbJournalStore expects a table with the string partition key `scope` and the string sort key `executionId`.
+type dynamodbJournalStore struct {
+	client JournalDynamodbApi
+	table  string
+	scope  string
+}
+
+func (s *dynamodbJournalStore) key(executionId string) map[string]ddbtypes.AttributeValue {
+	return map[string]ddbtypes.AttributeValue{
+		"scope":       &ddbtypes.AttributeValueMemberS{Value: s.scope},
+		"executionId": &ddbtypes.AttributeValueMemberS{Value: executionId},
+	}
+}
+
+func (s *dynamodbJournalStore) Put(ctx context.Context, entry JournalEntry) error {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	item := s.key(entry.ExecutionId)
+	item["entry"] = &ddbtypes.AttributeValueMemberS{Value: string(encoded)}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(s.table), Item: item})
+	return err
+}
+
+func (s *dynamodbJournalStore) Get(ctx context.Context, executionId string) (*JournalEntry, error) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(s.table), Key: s.key(executionId), ConsistentRead: aws.Bool(true)})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+	return decodeDynamodbJournalEntry(output.Item)
+}
+
+func (s *dynamodbJournalStore) Delete(ctx context.Context, executionId string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(s.table), Key: s.key(executionId)})
+	return err
+}
+
+func (s *dynamodbJournalStore) List(ctx context.Context) ([]JournalEntry, error) {
+	entries := make([]JournalEntry, 0)
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.table),
+		KeyConditionExpression:    aws.String("#scope = :scope"),
+		ExpressionAttributeNames:  map[string]string{"#scope": "scope"},
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{":scope": &ddbtypes.AttributeValueMemberS{Value: s.scope}},
+		ConsistentRead:            aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			entry, err := decodeDynamodbJournalEntry(item)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, *entry)
+		}
+	}
+	return entries, nil
+}
+
+func decodeDynamodbJournalEntry(item map[string]ddbtypes.AttributeValue) (*JournalEntry, error) {
+	value, ok := item["entry"].(*ddbtypes.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf("journal item without entry attribute")
+	}
+	var entry JournalEntry
+	if err := json.Unmarshal([]byte(value.Value), &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type journalTestState struct {
+	Original string
+}
+
+type journalTestAction struct {
+	stopped []string
+	stopErr error
+}
+
+func (a *journalTestAction) NewEmptyState() journalTestState { return journalTestState{} }
+func (a *journalTestAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{Id: "journal-test-action"}
+}
+func (a *journalTestAction) Prepare(_ context.Context, state *journalTestState, _ action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.Original = "enabled"
+	return nil, nil
+}
+func (a *journalTestAction) Start(_ context.Context, _ *journalTestState) (*action_kit_api.StartResult, error) {
+	return nil, nil
+}
+func (a *journalTestAction) Stop(_ context.Context, state *journalTestState) (*action_kit_api.StopResult, error) {
+	if a.stopErr != nil {
+		return nil, a.stopErr
+	}
+	a.stopped = append(a.stopped, state.Original)
+	return nil, nil
+}
+
+func withFileJournal(t *testing.T) {
+	store, err := newFileJournalStore(t.TempDir())
+	require.NoError(t, err)
+	journalStore = store
+	t.Cleanup(func() {
+		journalStore = nil
+		journalOwnEntries = map[string]JournalEntry{}
+	})
+}
+
+// abandonJournalEntries hands all entries over to an owner which is gone, as if the extension had been terminated.
+func abandonJournalEntries(t *testing.T) {
+	entries, err := journalStore.List(context.Background())
+	require.NoError(t, err)
+	for _, entry := range entries {
+		entry.OwnerId = "terminated-extension"
+		entry.HeartbeatAt = time.Now().Add(-time.Hour)
+		require.NoError(t, journalStore.Put(context.Background(), entry))
+	}
+	journalOwnEntriesMutex.Lock()
+	defer journalOwnEntriesMutex.Unlock()
+	journalOwnEntries = map[string]JournalEntry{}
+}
+
+func TestFileJournalStore(t *testing.T) {
+	store, err := newFileJournalStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, JournalEntry{ExecutionId: "a", ActionId: "action", State: json.RawMessage(`{"x":1}`)}))
+	require.NoError(t, store.Put(ctx, JournalEntry{ExecutionId: "b", ActionId: "action", State: json.RawMessage(`{"x":2}`)}))
+
+	entry, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"x":1}`, string(entry.State))
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	require.NoError(t, store.Delete(ctx, "a"), "deleting a missing entry is no error")
+	entry, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	entries, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "b", entries[0].ExecutionId)
+}
+
+func newJournaledTestAction(delegate *journalTestAction) *instrumentedActionWithStop[journalTestState] {
+	registerJournalRollback("journal-test-action", journalRollbackFor(func(ctx context.Context, state *journalTestState) error {
+		_, err := delegate.Stop(ctx, state)
+		return err
+	}))
+	return &instrumentedActionWithStop[journalTestState]{
+		instrumentedAction: instrumentedAction[journalTestState]{delegate: delegate, id: "journal-test-action", journaled: true},
+		stop:               delegate,
+	}
+}
+
+func startJournaledTestAction(t *testing.T, action *instrumentedActionWithStop[journalTestState]) InstrumentedState[journalTestState] {
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{ExecutionId: uuid.New()})
+	require.NoError(t, err)
+	_, err = action.Start(context.Background(), &state)
+	require.NoError(t, err)
+	return state
+}
+
+func TestJournaledActionIsRemovedFromJournalOnStop(t *testing.T) {
+	withFileJournal(t)
+	delegate := &journalTestAction{}
+	action := newJournaledTestAction(delegate)
+
+	state := startJournaledTestAction(t, action)
+	entries, _ := journalStore.List(context.Background())
+	require.Len(t, entries, 1)
+	assert.Equal(t, "journal-test-action", entries[0].ActionId)
+
+	_, err := action.Stop(context.Background(), &state)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"enabled"}, delegate.stopped)
+	entries, _ = journalStore.List(context.Background())
+	assert.Empty(t, entries)
+}
+
+func TestJournaledActionIsRolledBackOnlyOnce(t *testing.T) {
+	withFileJournal(t)
+	delegate := &journalTestAction{}
+	action := newJournaledTestAction(delegate)
+	state := startJournaledTestAction(t, action)
+	abandonJournalEntries(t)
+
+	assert.Equal(t, 0, rollbackJournaledAttacks(context.Background()))
+	assert.Equal(t, []string{"enabled"}, delegate.stopped)
+	assert.Equal(t, 0, rollbackJournaledAttacks(context.Background()), "rolled back entries are not rolled back again")
+	assert.Len(t, delegate.stopped, 1)
+
+	result, err := action.Stop(context.Background(), &state)
+	require.NoError(t, err)
+	assert.Len(t, delegate.stopped, 1, "stop after the rollback does not restore the target again")
+	assert.Contains(t, (*result.Messages)[0].Message, "already been restored")
+	entries, _ := journalStore.List(context.Background())
+	assert.Empty(t, entries)
+}
+
+func TestJournaledActionRollbackGivesUpAfterMaxAttempts(t *testing.T) {
+	withFileJournal(t)
+	config.Update(func(spec *config.Specification) { spec.AttackJournalMaxRollbackAttempts = 2 })
+	delegate := &journalTestAction{stopErr: errors.New("access denied")}
+	startJournaledTestAction(t, newJournaledTestAction(delegate))
+	abandonJournalEntries(t)
+
+	assert.Equal(t, 1, rollbackJournaledAttacks(context.Background()))
+	entries, _ := journalStore.List(context.Background())
+	require.Len(t, entries, 1)
+	assert.Equal(t, 1, entries[0].RollbackAttempts)
+	assert.Equal(t, journalOwnerId, entries[0].OwnerId, "the entry is taken over for the retries")
+
+	assert.Equal(t, 0, rollbackJournaledAttacks(context.Background()))
+	entries, _ = journalStore.List(context.Background())
+	assert.Empty(t, entries)
+}
+
+func TestJournaledActionIsOnlyRolledBackIfOwnerIsGone(t *testing.T) {
+	withFileJournal(t)
+	delegate := &journalTestAction{}
+	startJournaledTestAction(t, newJournaledTestAction(delegate))
+	require.NoError(t, journalStore.Put(context.Background(), JournalEntry{
+		ExecutionId: "other-replica",
+		ActionId:    "journal-test-action",
+		State:       json.RawMessage(`{"Original":"other"}`),
+		StartedAt:   time.Now().Add(-time.Hour),
+		OwnerId:     "other-replica-owner",
+		HeartbeatAt: time.Now(),
+	}))
+
+	assert.Equal(t, 0, rollbackJournaledAttacks(context.Background()))
+	assert.Empty(t, delegate.stopped, "neither the running attack of this extension nor of a live owner is rolled back")
+
+	abandonJournalEntries(t)
+	assert.Equal(t, 0, rollbackJournaledAttacks(context.Background()))
+	assert.ElementsMatch(t, []string{"enabled", "other"}, delegate.stopped)
+}
+
+func TestHeartbeatRenewsRunningAttacks(t *testing.T) {
+	withFileJournal(t)
+	state := startJournaledTestAction(t, newJournaledTestAction(&journalTestAction{}))
+	entry, err := journalStore.Get(context.Background(), state.ExecutionId)
+	require.NoError(t, err)
+	entry.HeartbeatAt = time.Now().Add(-time.Hour)
+	require.NoError(t, journalStore.Put(context.Background(), *entry))
+
+	heartbeatJournaledAttacks(context.Background())
+
+	entry, err = journalStore.Get(context.Background(), state.ExecutionId)
+	require.NoError(t, err)
+	assert.Equal(t, journalOwnerId, entry.OwnerId)
+	assert.WithinDuration(t, time.Now(), entry.HeartbeatAt, time.Minute)
+}
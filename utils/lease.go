@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	extension_kit "github.com/steadybit/extension-kit"
+)
+
+// ActionWithResourceArns is implemented by attacks which restore recorded values on stop, but whose target does not carry
+// the ARN of the changed resources or which are not journaled. The returned ARNs are leased.
+type ActionWithResourceArns interface {
+	ResourceArns(ctx context.Context, target *action_kit_api.Target) ([]string, error)
+}
+
+// Lease marks a resource as being attacked by one execution. Leases are held from prepare until stop, or until start
+// for attacks without stop. Only attacks restoring recorded values take leases, attacks like CPU stress can run in
+// parallel on the same target.
+type Lease struct {
+	ResourceArn string    `json:"resourceArn"`
+	ActionId    string    `json:"actionId"`
+	ExecutionId string    `json:"executionId"`
+	TargetName  string    `json:"targetName"`
+	AcquiredAt  time.Time `json:"acquiredAt"`
+}
+
+var (
+	leases             = make(map[string]Lease)
+	leasesMutex        sync.Mutex
+	leaseRetryInterval = time.Second
+)
+
+// GetLeases returns the currently held leases, ordered by resource ARN.
+func GetLeases() []Lease {
+	leasesMutex.Lock()
+	defer leasesMutex.Unlock()
+	now := time.Now()
+	result := make([]Lease, 0, len(leases))
+	for _, lease := range leases {
+		if !leaseExpired(lease, now) {
+			result = append(result, lease)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ResourceArn < result[j].ResourceArn
+	})
+	return result
+}
+
+// leaseResourceArns returns the resources to lease. Journaled attacks restore the recorded values of their target, so
+// they lease its ARN.
+func leaseResourceArns(ctx context.Context, action any, target *action_kit_api.Target, journaled bool) ([]string, error) {
+	if target == nil {
+		return nil, nil
+	}
+	if withResourceArns, ok := action.(ActionWithResourceArns); ok {
+		return withResourceArns.ResourceArns(ctx, target)
+	}
+	if journaled {
+		return slices.Clone(target.Attributes["aws.arn"]), nil
+	}
+	return nil, nil
+}
+
+// acquireLeases leases the resources for the execution. If another execution holds one of them, the attempt is
+// repeated until `AttackLeaseWaitTimeout` has passed.
+func acquireLeases(ctx context.Context, resourceArns []string, holder Lease) error {
+	deadline := time.Now().Add(time.Duration(extConfig.Config().AttackLeaseWaitTimeout) * time.Second)
+	for {
+		conflict := tryAcquireLeases(resourceArns, holder)
+		if conflict == nil {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return leaseConflictError(conflict)
+		}
+		select {
+		case <-time.After(leaseRetryInterval):
+		case <-ctx.Done():
+			return leaseConflictError(conflict)
+		}
+	}
+}
+
+func tryAcquireLeases(resourceArns []string, holder Lease) *Lease {
+	leasesMutex.Lock()
+	defer leasesMutex.Unlock()
+	now := time.Now()
+	for _, resourceArn := range resourceArns {
+		existing, ok := leases[resourceArn]
+		if !ok || existing.ExecutionId == holder.ExecutionId {
+			continue
+		}
+		if !leaseExpired(existing, now) {
+			return &existing
+		}
+		log.Warn().Msgf("Lease of resource '%s' by execution %s expired, it has not been stopped since %s.", resourceArn, existing.ExecutionId, existing.AcquiredAt.Format(time.RFC3339))
+	}
+	for _, resourceArn := range resourceArns {
+		lease := holder
+		lease.ResourceArn = resourceArn
+		lease.AcquiredAt = now
+		leases[resourceArn] = lease
+	}
+	return nil
+}
+
+// leaseExpired is true for leases held longer than `AttackLeaseMaxAge`. They are left behind by executions which are
+// never stopped, e.g. because the agent crashed.
+func leaseExpired(lease Lease, now time.Time) bool {
+	maxAge := extConfig.Config().AttackLeaseMaxAge
+	return maxAge > 0 && now.Sub(lease.AcquiredAt) > time.Duration(maxAge)*time.Second
+}
+
+func releaseLeases(resourceArns []string, executionId string) {
+	leasesMutex.Lock()
+	defer leasesMutex.Unlock()
+	for _, resourceArn := range resourceArns {
+		if existing, ok := leases[resourceArn]; ok && existing.ExecutionId == executionId {
+			delete(leases, resourceArn)
+		}
+	}
+}
+
+func leaseConflictError(conflict *Lease) error {
+	return extension_kit.ToError(fmt.Sprintf("Resource '%s' is already attacked by '%s' (execution %s, target '%s') since %s. Concurrent attacks on the same resource are rejected, as they would restore wrong values.",
+		conflict.ResourceArn, conflict.ActionId, conflict.ExecutionId, conflict.TargetName, conflict.AcquiredAt.Format(time.RFC3339)), nil)
+}
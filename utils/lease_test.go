@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type leaseTestAction struct{}
+
+func (a *leaseTestAction) NewEmptyState() string { return "" }
+func (a *leaseTestAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{Id: "lease-test-action", Kind: action_kit_api.Attack}
+}
+func (a *leaseTestAction) Prepare(_ context.Context, _ *string, _ action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	return nil, nil
+}
+func (a *leaseTestAction) Start(_ context.Context, _ *string) (*action_kit_api.StartResult, error) {
+	return nil, nil
+}
+func (a *leaseTestAction) Stop(_ context.Context, _ *string) (*action_kit_api.StopResult, error) {
+	return nil, nil
+}
+
+func newLeaseTestAction() *instrumentedActionWithStop[string] {
+	delegate := &leaseTestAction{}
+	return &instrumentedActionWithStop[string]{
+		instrumentedAction: instrumentedAction[string]{delegate: delegate, id: "lease-test-action", journaled: true, hasStop: true},
+		stop:               delegate,
+	}
+}
+
+func prepareLeaseTestAction(action *instrumentedActionWithStop[string], arn string) (InstrumentedState[string], error) {
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Target:      &action_kit_api.Target{Name: "orders", Attributes: map[string][]string{"aws.arn": {arn}}},
+	})
+	return state, err
+}
+
+func TestConcurrentAttacksOnSameResourceAreRejected(t *testing.T) {
+	extConfig.Update(func(spec *extConfig.Specification) { spec.AttackLeaseWaitTimeout = 0 })
+	action := newLeaseTestAction()
+	arn := "arn:aws:dynamodb:eu-central-1:123456789012:table/orders"
+
+	first, err := prepareLeaseTestAction(action, arn)
+	require.NoError(t, err)
+	assert.Equal(t, []string{arn}, first.Leases)
+
+	_, err = prepareLeaseTestAction(action, arn)
+	var extensionError extension_kit.ExtensionError
+	require.ErrorAs(t, err, &extensionError)
+	assert.Contains(t, extensionError.Title, "is already attacked by 'lease-test-action'")
+
+	_, err = prepareLeaseTestAction(action, "arn:aws:dynamodb:eu-central-1:123456789012:table/other")
+	require.NoError(t, err, "other resources are not affected")
+
+	_, err = action.Stop(context.Background(), &first)
+	require.NoError(t, err)
+	_, err = prepareLeaseTestAction(action, arn)
+	assert.NoError(t, err, "the lease is released on stop")
+
+	leases = make(map[string]Lease)
+}
+
+func TestConflictingAttackWaitsForLease(t *testing.T) {
+	extConfig.Update(func(spec *extConfig.Specification) { spec.AttackLeaseWaitTimeout = 5 })
+	defer extConfig.Update(func(spec *extConfig.Specification) { spec.AttackLeaseWaitTimeout = 0 })
+	leaseRetryInterval = 10 * time.Millisecond
+	defer func() { leaseRetryInterval = time.Second }()
+	action := newLeaseTestAction()
+	arn := "arn:aws:sqs:eu-central-1:123456789012:orders"
+
+	first, err := prepareLeaseTestAction(action, arn)
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = action.Stop(context.Background(), &first)
+	}()
+
+	second, err := prepareLeaseTestAction(action, arn)
+	require.NoError(t, err)
+	assert.Equal(t, second.ExecutionId, GetLeases()[0].ExecutionId)
+
+	leases = make(map[string]Lease)
+}
+
+type leaseWithoutStopTestAction struct {
+	dryRunTestAction
+}
+
+func (a *leaseWithoutStopTestAction) ResourceArns(_ context.Context, target *action_kit_api.Target) ([]string, error) {
+	return target.Attributes["aws-ec2.arn"], nil
+}
+
+func TestLeaseOfAttackWithoutStopIsReleasedAfterStart(t *testing.T) {
+	extConfig.Update(func(spec *extConfig.Specification) { spec.AttackLeaseWaitTimeout = 0 })
+	action := &instrumentedAction[string]{delegate: &leaseWithoutStopTestAction{}, id: "dry-run-test-action"}
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Target:      &action_kit_api.Target{Attributes: map[string][]string{"aws-ec2.arn": {"arn:aws:ec2:eu-central-1:123456789012:instance/i-1"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, GetLeases(), 1)
+
+	_, err = action.Start(context.Background(), &state)
+	require.NoError(t, err)
+	assert.Empty(t, GetLeases())
+}
+
+func TestAttacksNotRestoringValuesTakeNoLease(t *testing.T) {
+	action := &instrumentedActionWithStop[string]{
+		instrumentedAction: instrumentedAction[string]{delegate: &leaseTestAction{}, id: "stress-test-action", hasStop: true},
+		stop:               &leaseTestAction{},
+	}
+	arn := "arn:aws:ec2:eu-central-1:123456789012:instance/i-1"
+
+	first, err := prepareLeaseTestAction(action, arn)
+	require.NoError(t, err)
+	assert.Empty(t, first.Leases)
+	_, err = prepareLeaseTestAction(action, arn)
+	assert.NoError(t, err, "parallel attacks are not rejected")
+	assert.Empty(t, GetLeases())
+}
+
+func TestExpiredLeaseIsTakenOver(t *testing.T) {
+	extConfig.Update(func(spec *extConfig.Specification) {
+		spec.AttackLeaseWaitTimeout = 0
+		spec.AttackLeaseMaxAge = 3600
+	})
+	defer extConfig.Update(func(spec *extConfig.Specification) { spec.AttackLeaseMaxAge = 0 })
+	action := newLeaseTestAction()
+	arn := "arn:aws:sqs:eu-central-1:123456789012:orders"
+
+	_, err := prepareLeaseTestAction(action, arn)
+	require.NoError(t, err)
+	leasesMutex.Lock()
+	lease := leases[arn]
+	lease.AcquiredAt = time.Now().Add(-2 * time.Hour)
+	leases[arn] = lease
+	leasesMutex.Unlock()
+	assert.Empty(t, GetLeases(), "expired leases are not listed")
+
+	second, err := prepareLeaseTestAction(action, arn)
+	require.NoError(t, err, "the lease of an execution that was never stopped expires")
+	assert.Equal(t, second.ExecutionId, leases[arn].ExecutionId)
+
+	leases = make(map[string]Lease)
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	middleware2 "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
+	"github.com/steadybit/extension-kit/exthttp"
+)
+
+const metricsNamespace = "steadybit_extension_aws"
+
+var (
+	discoveryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "discovery_duration_seconds",
+		Help:      "Duration of a discovery run for a single account and region.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"discovery", "account", "region"})
+	discoveryTargets = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "discovery_targets",
+		Help:      "Number of targets returned by the last discovery run for a single account and region.",
+	}, []string{"discovery", "account", "region"})
+	discoveryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "discovery_errors_total",
+		Help:      "Number of failed discovery runs for a single account and region.",
+	}, []string{"discovery", "account", "region"})
+	discoveryLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "discovery_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful discovery run for a single account and region.",
+	}, []string{"discovery", "account", "region"})
+
+	awsApiCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "aws_api_calls_total",
+		Help:      "Number of AWS API calls.",
+	}, []string{"service", "operation"})
+	awsApiErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "aws_api_errors_total",
+		Help:      "Number of failed AWS API calls by error code.",
+	}, []string{"service", "operation", "code"})
+	awsApiDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "aws_api_call_duration_seconds",
+		Help:      "Duration of AWS API calls, including retries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "operation"})
+
+	actionOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "action_operations_total",
+		Help:      "Number of action operations (prepare, start, status, stop) by outcome.",
+	}, []string{"action", "operation", "outcome"})
+
+	blackholeCleanups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "blackhole_cleanups_total",
+		Help:      "Number of blackhole executions rolled back by the blackhole reconciler, by reason and outcome.",
+	}, []string{"account", "region", "reason", "outcome"})
+)
+
+// RegisterMetricsHandler exposes the Prometheus metrics at /metrics.
+func RegisterMetricsHandler() {
+	handler := promhttp.Handler()
+	exthttp.RegisterHttpHandlerWithLogLevel("/metrics", func(w http.ResponseWriter, r *http.Request, _ []byte) {
+		handler.ServeHTTP(w, r)
+	}, zerolog.DebugLevel)
+}
+
+func recordDiscovery(discovery string, account *AwsAccess, duration time.Duration, targets int, err error) {
+	discoveryDuration.WithLabelValues(discovery, account.AccountNumber, account.Region).Observe(duration.Seconds())
+	if err != nil {
+		discoveryErrors.WithLabelValues(discovery, account.AccountNumber, account.Region).Inc()
+		return
+	}
+	discoveryTargets.WithLabelValues(discovery, account.AccountNumber, account.Region).Set(float64(targets))
+	discoveryLastSuccess.WithLabelValues(discovery, account.AccountNumber, account.Region).SetToCurrentTime()
+}
+
+func recordAwsApiCall(ctx context.Context, duration time.Duration, err error) {
+	service := middleware2.GetServiceID(ctx)
+	operation := middleware2.GetOperationName(ctx)
+	awsApiCalls.WithLabelValues(service, operation).Inc()
+	awsApiDuration.WithLabelValues(service, operation).Observe(duration.Seconds())
+	if err != nil {
+		awsApiErrors.WithLabelValues(service, operation, errorCode(err)).Inc()
+	}
+}
+
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "Canceled"
+	}
+	return "Unknown"
+}
+
+func recordActionOperation(action string, operation string, failed bool) {
+	outcome := "success"
+	if failed {
+		outcome = "failure"
+	}
+	actionOperations.WithLabelValues(action, operation, outcome).Inc()
+}
+
+// RecordBlackholeCleanup counts a blackhole execution rolled back by the blackhole reconciler.
+func RecordBlackholeCleanup(account string, region string, reason string, failed bool) {
+	outcome := "success"
+	if failed {
+		outcome = "failure"
+	}
+	blackholeCleanups.WithLabelValues(account, region, reason, outcome).Inc()
+}
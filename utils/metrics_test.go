@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachAccountRecordsDiscoveryMetrics(t *testing.T) {
+	config.Update(func(spec *config.Specification) { spec.WorkerThreads = 1 })
+	accounts = getTestAccountsWithoutRoleAssumption()
+
+	_, err := ForEveryConfiguredAwsAccess(getTestFunction(nil, nil), context.Background(), "metrics-test")
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(discoveryTargets.WithLabelValues("metrics-test", "12345678", "us-east-1")))
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(discoveryDuration), 1)
+
+	_, _ = ForEveryConfiguredAwsAccess(getTestFunction(new("12345678"), nil), context.Background(), "metrics-test")
+	assert.Equal(t, float64(1), testutil.ToFloat64(discoveryErrors.WithLabelValues("metrics-test", "12345678", "us-east-1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(discoveryTargets.WithLabelValues("metrics-test", "12345678", "us-east-1")), "failed runs keep the last target count")
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/rs/zerolog/log"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+)
+
+type OrganizationsApi interface {
+	organizations.ListAccountsAPIClient
+	organizations.ListAccountsForParentAPIClient
+	organizations.ListOrganizationalUnitsForParentAPIClient
+	ListTagsForResource(ctx context.Context, params *organizations.ListTagsForResourceInput, optFns ...func(*organizations.Options)) (*organizations.ListTagsForResourceOutput, error)
+}
+
+var (
+	organizationRolesMutex sync.Mutex
+	// roles which have been onboarded by the organization sync, keyed by role ARN
+	organizationRoles = map[string]extConfig.AssumeRole{}
+)
+
+// StartOrganizationAccountSync periodically re-reads the accounts of the AWS organization, so that new accounts are
+// discovered without a restart and removed accounts are no longer queried.
+func StartOrganizationAccountSync(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-time.After(time.Duration(max(extConfig.Config().OrganizationsRefreshInterval, 1)) * time.Second):
+				if extConfig.Config().OrganizationsEnabled {
+					syncOrganizationAccounts(ctx, organizations.NewFromConfig(rootAwsConfig))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func syncOrganizationAccounts(ctx context.Context, client OrganizationsApi) {
+	orgAccounts, err := listOrganizationAccounts(ctx, client)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list accounts of the AWS organization. Keeping the previously onboarded accounts.")
+		return
+	}
+
+	roleNameTemplate, err := extConfig.ParseRoleNameTemplate(extConfig.Config().OrganizationsRoleNameTemplate)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid organizationsRoleNameTemplate.")
+		return
+	}
+
+	explicitlyConfigured := getExplicitlyConfiguredAccounts()
+	desired := make(map[string]extConfig.AssumeRole)
+	for _, account := range orgAccounts {
+		accountId := aws.ToString(account.Id)
+		if slices.Contains(extConfig.Config().OrganizationsExcludedAccounts, accountId) || explicitlyConfigured[accountId] {
+			continue
+		}
+		if len(extConfig.Config().OrganizationsAccountTagFilters) > 0 {
+			tags, err := listAccountTags(ctx, client, accountId)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed to list tags of account '%s'. The account will be skipped.", accountId)
+				continue
+			}
+			if !MatchesTagFilter(tags, extConfig.Config().OrganizationsAccountTagFilters) {
+				continue
+			}
+		}
+		var roleName bytes.Buffer
+		if err := roleNameTemplate.Execute(&roleName, extConfig.RoleNameTemplateData{AccountId: accountId, AccountName: aws.ToString(account.Name)}); err != nil {
+			log.Warn().Err(err).Msgf("Failed to render role name for account '%s'. The account will be skipped.", accountId)
+			continue
+		}
+		roleArn := fmt.Sprintf("arn:%s:iam::%s:role/%s", rootPartition, accountId, roleName.String())
+		desired[roleArn] = extConfig.AssumeRole{
+			RoleArn:    roleArn,
+			Regions:    extConfig.Config().Regions,
+			TagFilters: extConfig.Config().TagFilters,
+		}
+	}
+
+	reconcileOrganizationRoles(ctx, desired)
+}
+
+func reconcileOrganizationRoles(ctx context.Context, desired map[string]extConfig.AssumeRole) {
+	organizationRolesMutex.Lock()
+	defer organizationRolesMutex.Unlock()
+	for roleArn, current := range organizationRoles {
+		if assumeRole, ok := desired[roleArn]; !ok || !reflect.DeepEqual(assumeRole, current) {
+			if ok {
+				log.Info().Msgf("Configuration of role '%s' changed. Re-adding it.", roleArn)
+			} else {
+				log.Info().Msgf("Account of role '%s' is no longer part of the AWS organization or filtered out. Removing it.", roleArn)
+			}
+			delete(organizationRoles, roleArn)
+			removeAssumedRole(roleArn)
+		}
+	}
+	for _, roleArn := range slices.Sorted(maps.Keys(desired)) {
+		if _, ok := organizationRoles[roleArn]; ok {
+			continue
+		}
+		log.Info().Msgf("Onboarding account of the AWS organization via role '%s'", roleArn)
+		organizationRoles[roleArn] = desired[roleArn]
+		assumeRoleAndAddAccess(ctx, desired[roleArn])
+	}
+}
+
+func listOrganizationAccounts(ctx context.Context, client OrganizationsApi) ([]orgtypes.Account, error) {
+	var result []orgtypes.Account
+	if len(extConfig.Config().OrganizationsOrganizationalUnits) == 0 {
+		paginator := organizations.NewListAccountsPaginator(client, &organizations.ListAccountsInput{})
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, output.Accounts...)
+		}
+	} else {
+		for _, ou := range extConfig.Config().OrganizationsOrganizationalUnits {
+			accounts, err := listAccountsForParent(ctx, client, ou)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, accounts...)
+		}
+	}
+
+	seen := make(map[string]bool)
+	active := make([]orgtypes.Account, 0, len(result))
+	for _, account := range result {
+		accountId := aws.ToString(account.Id)
+		if seen[accountId] || !isActive(account) {
+			continue
+		}
+		seen[accountId] = true
+		active = append(active, account)
+	}
+	return active, nil
+}
+
+// listAccountsForParent returns the accounts of the given organizational unit including all nested units.
+func listAccountsForParent(ctx context.Context, client OrganizationsApi, parentId string) ([]orgtypes.Account, error) {
+	var result []orgtypes.Account
+	accountPaginator := organizations.NewListAccountsForParentPaginator(client, &organizations.ListAccountsForParentInput{ParentId: aws.String(parentId)})
+	for accountPaginator.HasMorePages() {
+		output, err := accountPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, output.Accounts...)
+	}
+
+	ouPaginator := organizations.NewListOrganizationalUnitsForParentPaginator(client, &organizations.ListOrganizationalUnitsForParentInput{ParentId: aws.String(parentId)})
+	for ouPaginator.HasMorePages() {
+		output, err := ouPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ou := range output.OrganizationalUnits {
+			nested, err := listAccountsForParent(ctx, client, aws.ToString(ou.Id))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, nested...)
+		}
+	}
+	return result, nil
+}
+
+func listAccountTags(ctx context.Context, client OrganizationsApi, accountId string) ([]types.Tag, error) {
+	var tags []types.Tag
+	var nextToken *string
+	for {
+		output, err := client.ListTagsForResource(ctx, &organizations.ListTagsForResourceInput{ResourceId: aws.String(accountId), NextToken: nextToken})
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range output.Tags {
+			tags = append(tags, types.Tag{Key: tag.Key, Value: tag.Value})
+		}
+		if output.NextToken == nil {
+			return tags, nil
+		}
+		nextToken = output.NextToken
+	}
+}
+
+func isActive(account orgtypes.Account) bool {
+	if account.State != "" {
+		return account.State == orgtypes.AccountStateActive
+	}
+	return account.Status == orgtypes.AccountStatusActive
+}
+
+func getExplicitlyConfiguredAccounts() map[string]bool {
+	result := make(map[string]bool)
+	for _, assumeRole := range extConfig.Config().AssumeRolesAdvanced {
+		if parsed, err := arn.Parse(assumeRole.RoleArn); err == nil {
+			result[parsed.AccountID] = true
+		}
+	}
+	return result
+}
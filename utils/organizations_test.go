@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type organizationsApiMock struct {
+	mock.Mock
+}
+
+func (m *organizationsApiMock) ListAccounts(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*organizations.ListAccountsOutput), args.Error(1)
+}
+
+func (m *organizationsApiMock) ListAccountsForParent(ctx context.Context, params *organizations.ListAccountsForParentInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsForParentOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*organizations.ListAccountsForParentOutput), args.Error(1)
+}
+
+func (m *organizationsApiMock) ListOrganizationalUnitsForParent(ctx context.Context, params *organizations.ListOrganizationalUnitsForParentInput, optFns ...func(*organizations.Options)) (*organizations.ListOrganizationalUnitsForParentOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*organizations.ListOrganizationalUnitsForParentOutput), args.Error(1)
+}
+
+func (m *organizationsApiMock) ListTagsForResource(ctx context.Context, params *organizations.ListTagsForResourceInput, optFns ...func(*organizations.Options)) (*organizations.ListTagsForResourceOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*organizations.ListTagsForResourceOutput), args.Error(1)
+}
+
+func orgAccount(id string, state orgtypes.AccountState) orgtypes.Account {
+	return orgtypes.Account{Id: aws.String(id), Name: aws.String("account-" + id), State: state}
+}
+
+func setupOrganizationTest(t *testing.T) {
+	config.Update(func(spec *config.Specification) {
+		spec.OrganizationsEnabled = true
+		spec.OrganizationsRoleNameTemplate = "steadybit-{{.AccountId}}"
+		spec.OrganizationsOrganizationalUnits = nil
+		spec.OrganizationsAccountTagFilters = nil
+		spec.OrganizationsExcludedAccounts = nil
+		spec.AssumeRolesAdvanced = nil
+		spec.Regions = []string{"us-east-1"}
+	})
+	accounts = make(map[string]AwsAccess)
+	supervisor = newRoleSupervisor()
+	organizationRoles = map[string]config.AssumeRole{}
+	rootPartition = "aws"
+
+	originalIdentify := identifyAccount
+	t.Cleanup(func() {
+		identifyAccount = originalIdentify
+		config.Update(func(spec *config.Specification) {
+			spec.OrganizationsEnabled = false
+			spec.Regions = nil
+		})
+	})
+	identifyAccount = func(ctx context.Context, awsConfig aws.Config) (string, error) {
+		return "", errors.New("unexpected")
+	}
+}
+
+func TestSyncOrganizationAccountsAddsAndRemovesAccounts(t *testing.T) {
+	setupOrganizationTest(t)
+	config.Update(func(spec *config.Specification) {
+		spec.OrganizationsExcludedAccounts = []string{"333333333333"}
+		spec.AssumeRolesAdvanced = []config.AssumeRole{{RoleArn: "arn:aws:iam::444444444444:role/custom"}}
+	})
+
+	var identified []string
+	identifyAccount = func(ctx context.Context, awsConfig aws.Config) (string, error) {
+		account := "111111111111"
+		if len(identified) > 0 {
+			account = "222222222222"
+		}
+		identified = append(identified, account)
+		return account, nil
+	}
+
+	mockedApi := new(organizationsApiMock)
+	mockedApi.On("ListAccounts", mock.Anything, mock.Anything).Return(&organizations.ListAccountsOutput{
+		Accounts: []orgtypes.Account{
+			orgAccount("111111111111", orgtypes.AccountStateActive),
+			orgAccount("222222222222", orgtypes.AccountStateActive),
+			orgAccount("333333333333", orgtypes.AccountStateActive),
+			orgAccount("444444444444", orgtypes.AccountStateActive),
+			orgAccount("555555555555", orgtypes.AccountStateSuspended),
+		},
+	}, nil).Once()
+
+	syncOrganizationAccounts(context.Background(), mockedApi)
+
+	assert.Len(t, identified, 2)
+	assert.ElementsMatch(t, []string{
+		"arn:aws:iam::111111111111:role/steadybit-111111111111",
+		"arn:aws:iam::222222222222:role/steadybit-222222222222",
+	}, slices.Collect(maps.Keys(organizationRoles)))
+	_, err := GetAwsAccess("111111111111", "us-east-1", new("arn:aws:iam::111111111111:role/steadybit-111111111111"))
+	require.NoError(t, err)
+
+	// account 111111111111 left the organization
+	mockedApi.On("ListAccounts", mock.Anything, mock.Anything).Return(&organizations.ListAccountsOutput{
+		Accounts: []orgtypes.Account{orgAccount("222222222222", orgtypes.AccountStateActive)},
+	}, nil).Once()
+
+	syncOrganizationAccounts(context.Background(), mockedApi)
+
+	assert.Len(t, identified, 2, "already onboarded accounts are not assumed again")
+	assert.ElementsMatch(t, []string{"arn:aws:iam::222222222222:role/steadybit-222222222222"}, slices.Collect(maps.Keys(organizationRoles)))
+	_, err = GetAwsAccess("111111111111", "us-east-1", new("arn:aws:iam::111111111111:role/steadybit-111111111111"))
+	require.Error(t, err)
+	_, err = GetAwsAccess("222222222222", "us-east-1", new("arn:aws:iam::222222222222:role/steadybit-222222222222"))
+	require.NoError(t, err)
+}
+
+func TestSyncOrganizationAccountsKeepsAccountsOnListError(t *testing.T) {
+	setupOrganizationTest(t)
+	organizationRoles["arn:aws:iam::111111111111:role/steadybit-111111111111"] = config.AssumeRole{RoleArn: "arn:aws:iam::111111111111:role/steadybit-111111111111"}
+
+	mockedApi := new(organizationsApiMock)
+	mockedApi.On("ListAccounts", mock.Anything, mock.Anything).Return(nil, errors.New("access denied"))
+
+	syncOrganizationAccounts(context.Background(), mockedApi)
+
+	assert.Len(t, organizationRoles, 1)
+}
+
+func TestSyncOrganizationAccountsWithOrganizationalUnitsAndTagFilters(t *testing.T) {
+	setupOrganizationTest(t)
+	config.Update(func(spec *config.Specification) {
+		spec.OrganizationsOrganizationalUnits = []string{"ou-root"}
+		spec.OrganizationsAccountTagFilters = []config.TagFilter{{Key: "chaos", Values: []string{"enabled"}}}
+	})
+
+	identifyAccount = func(ctx context.Context, awsConfig aws.Config) (string, error) {
+		return "222222222222", nil
+	}
+
+	mockedApi := new(organizationsApiMock)
+	mockedApi.On("ListAccountsForParent", mock.Anything, mock.MatchedBy(func(params *organizations.ListAccountsForParentInput) bool {
+		return *params.ParentId == "ou-root"
+	})).Return(&organizations.ListAccountsForParentOutput{
+		Accounts: []orgtypes.Account{orgAccount("111111111111", orgtypes.AccountStateActive)},
+	}, nil)
+	mockedApi.On("ListOrganizationalUnitsForParent", mock.Anything, mock.MatchedBy(func(params *organizations.ListOrganizationalUnitsForParentInput) bool {
+		return *params.ParentId == "ou-root"
+	})).Return(&organizations.ListOrganizationalUnitsForParentOutput{
+		OrganizationalUnits: []orgtypes.OrganizationalUnit{{Id: aws.String("ou-nested")}},
+	}, nil)
+	mockedApi.On("ListAccountsForParent", mock.Anything, mock.MatchedBy(func(params *organizations.ListAccountsForParentInput) bool {
+		return *params.ParentId == "ou-nested"
+	})).Return(&organizations.ListAccountsForParentOutput{
+		Accounts: []orgtypes.Account{orgAccount("222222222222", orgtypes.AccountStateActive)},
+	}, nil)
+	mockedApi.On("ListOrganizationalUnitsForParent", mock.Anything, mock.MatchedBy(func(params *organizations.ListOrganizationalUnitsForParentInput) bool {
+		return *params.ParentId == "ou-nested"
+	})).Return(&organizations.ListOrganizationalUnitsForParentOutput{}, nil)
+	mockedApi.On("ListTagsForResource", mock.Anything, mock.MatchedBy(func(params *organizations.ListTagsForResourceInput) bool {
+		return *params.ResourceId == "111111111111"
+	})).Return(&organizations.ListTagsForResourceOutput{
+		Tags: []orgtypes.Tag{{Key: aws.String("chaos"), Value: aws.String("disabled")}},
+	}, nil)
+	mockedApi.On("ListTagsForResource", mock.Anything, mock.MatchedBy(func(params *organizations.ListTagsForResourceInput) bool {
+		return *params.ResourceId == "222222222222"
+	})).Return(&organizations.ListTagsForResourceOutput{
+		Tags: []orgtypes.Tag{{Key: aws.String("chaos"), Value: aws.String("enabled")}},
+	}, nil)
+
+	syncOrganizationAccounts(context.Background(), mockedApi)
+
+	assert.ElementsMatch(t, []string{"arn:aws:iam::222222222222:role/steadybit-222222222222"}, slices.Collect(maps.Keys(organizationRoles)))
+	mockedApi.AssertExpectations(t)
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// regionPartitions maps region prefixes to their partition.
+var regionPartitions = []struct {
+	prefix    string
+	partition string
+}{
+	{"us-isob-", "aws-iso-b"},
+	{"us-isof-", "aws-iso-f"},
+	{"eu-isoe-", "aws-iso-e"},
+	{"us-iso-", "aws-iso"},
+	{"us-gov-", "aws-us-gov"},
+	{"cn-", "aws-cn"},
+}
+
+// PartitionForRegion returns the partition of the region, e.g. `aws-cn` for `cn-north-1`. Unknown regions belong to
+// the commercial partition `aws`.
+func PartitionForRegion(region string) string {
+	for _, p := range regionPartitions {
+		if strings.HasPrefix(region, p.prefix) {
+			return p.partition
+		}
+	}
+	return "aws"
+}
+
+// BuildArn returns the ARN of a resource in the partition of the region. Pass an empty account for services whose ARNs
+// do not contain one, e.g. API Gateway.
+func BuildArn(service string, region string, account string, resource string) string {
+	return arn.ARN{
+		Partition: PartitionForRegion(region),
+		Service:   service,
+		Region:    region,
+		AccountID: account,
+		Resource:  resource,
+	}.String()
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionForRegion(t *testing.T) {
+	tests := map[string]string{
+		"eu-central-1":   "aws",
+		"us-gov-west-1":  "aws-us-gov",
+		"cn-north-1":     "aws-cn",
+		"cn-northwest-1": "aws-cn",
+		"us-iso-east-1":  "aws-iso",
+		"us-isob-east-1": "aws-iso-b",
+		"eu-isoe-west-1": "aws-iso-e",
+		"":               "aws",
+	}
+	for region, want := range tests {
+		assert.Equal(t, want, PartitionForRegion(region), region)
+	}
+}
+
+func TestBuildArn(t *testing.T) {
+	assert.Equal(t, "arn:aws-cn:ec2:cn-north-1:123456789012:instance/i-1", BuildArn("ec2", "cn-north-1", "123456789012", "instance/i-1"))
+	assert.Equal(t, "arn:aws:apigateway:eu-central-1::/apis/abc/stages/prod", BuildArn("apigateway", "eu-central-1", "", "/apis/abc/stages/prod"))
+}
@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/rs/zerolog/log"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+)
+
+const (
+	permissionReportMaxAge = 5 * time.Minute
+	permissionCheckTimeout = time.Minute
+	permissionPolicySid    = "SteadybitExtensionAwsMissingPermissions"
+)
+
+// PermissionReport is the result of the IAM permission self-check of all configured accounts.
+type PermissionReport struct {
+	CheckedAt time.Time            `json:"checkedAt"`
+	Accounts  []AccountPermissions `json:"accounts"`
+}
+
+// AccountPermissions lists the checked permissions of the principal used for one account. All regions of the account
+// share the principal, so it is checked once.
+type AccountPermissions struct {
+	Account         string            `json:"account"`
+	Regions         []string          `json:"regions"`
+	Role            *string           `json:"role,omitempty"`
+	Principal       string            `json:"principal,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	Checks          []PermissionCheck `json:"checks,omitempty"`
+	SuggestedPolicy *IamPolicy        `json:"suggestedPolicy,omitempty"`
+}
+
+type PermissionCheck struct {
+	Kind    string   `json:"kind"`
+	Id      string   `json:"id"`
+	Allowed []string `json:"allowed,omitempty"`
+	Denied  []string `json:"denied,omitempty"`
+}
+
+var (
+	permissionReport      *PermissionReport
+	permissionReportMutex sync.Mutex
+)
+
+// StartPermissionSelfCheck checks the permissions of all registered discoveries and actions in the background and logs
+// the missing ones together with a suggested policy.
+func StartPermissionSelfCheck(ctx context.Context) {
+	if !extConfig.Config().PermissionSelfCheckOnStartup {
+		return
+	}
+	go func() {
+		report := refreshPermissionReport(ctx)
+		logPermissionReport(report)
+	}()
+}
+
+// GetPermissionReport returns the result of the last permission self-check. The check is repeated if the last result
+// is older than five minutes.
+func GetPermissionReport() PermissionReport {
+	permissionReportMutex.Lock()
+	report := permissionReport
+	permissionReportMutex.Unlock()
+	if report != nil && time.Since(report.CheckedAt) < permissionReportMaxAge {
+		return *report
+	}
+	return refreshPermissionReport(context.Background())
+}
+
+func refreshPermissionReport(ctx context.Context) PermissionReport {
+	ctx, cancel := context.WithTimeout(ctx, permissionCheckTimeout)
+	defer cancel()
+	report := checkPermissions(ctx, GetRequiredPermissions())
+	permissionReportMutex.Lock()
+	permissionReport = &report
+	permissionReportMutex.Unlock()
+	return report
+}
+
+func checkPermissions(ctx context.Context, required []RequiredPermissions) PermissionReport {
+	report := PermissionReport{CheckedAt: time.Now(), Accounts: make([]AccountPermissions, 0)}
+	actions := make([]string, 0)
+	for _, r := range required {
+		actions = append(actions, r.Actions...)
+	}
+	slices.Sort(actions)
+	actions = slices.Compact(actions)
+
+	for _, account := range accountsByPrincipal() {
+		if len(actions) > 0 {
+			checkAccountPermissions(ctx, &account, required, actions)
+		}
+		report.Accounts = append(report.Accounts, account)
+	}
+	return report
+}
+
+// accountsByPrincipal groups the configured accesses by account and role, ordered by account.
+func accountsByPrincipal() []AccountPermissions {
+	accountsMutex.RLock()
+	grouped := make(map[string]*AccountPermissions)
+	for _, access := range accounts {
+		key := access.AccountNumber + "/" + aws.ToString(access.AssumeRole)
+		if _, ok := grouped[key]; !ok {
+			grouped[key] = &AccountPermissions{Account: access.AccountNumber, Role: access.AssumeRole}
+		}
+		grouped[key].Regions = append(grouped[key].Regions, access.Region)
+	}
+	accountsMutex.RUnlock()
+
+	result := make([]AccountPermissions, 0, len(grouped))
+	for _, account := range grouped {
+		sort.Strings(account.Regions)
+		result = append(result, *account)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Account != result[j].Account {
+			return result[i].Account < result[j].Account
+		}
+		return aws.ToString(result[i].Role) < aws.ToString(result[j].Role)
+	})
+	return result
+}
+
+func checkAccountPermissions(ctx context.Context, account *AccountPermissions, required []RequiredPermissions, actions []string) {
+	iamClient, stsClient, awsAccess, err := simulationClientProvider(account.Account, account.Regions[0], account.Role)
+	if err != nil {
+		account.Error = err.Error()
+		return
+	}
+	principal, err := principalArn(ctx, iamClient, stsClient, awsAccess)
+	if err != nil {
+		account.Error = "failed to determine the IAM principal: " + err.Error()
+		return
+	}
+	account.Principal = principal
+
+	results, err := simulatePrincipalPolicy(ctx, iamClient, principal, actions, "*")
+	if err != nil {
+		account.Error = err.Error()
+		return
+	}
+	allowed := make(map[string]bool, len(results))
+	for _, result := range results {
+		allowed[aws.ToString(result.EvalActionName)] = result.EvalDecision == iamtypes.PolicyEvaluationDecisionTypeAllowed
+	}
+
+	denied := make([]string, 0)
+	for _, r := range required {
+		check := PermissionCheck{Kind: r.Kind, Id: r.Id}
+		for _, action := range r.Actions {
+			if allowed[action] {
+				check.Allowed = append(check.Allowed, action)
+			} else {
+				check.Denied = append(check.Denied, action)
+				denied = append(denied, action)
+			}
+		}
+		account.Checks = append(account.Checks, check)
+	}
+	if len(denied) > 0 {
+		account.SuggestedPolicy = new(NewIamPolicy(permissionPolicySid, denied))
+	}
+}
+
+func logPermissionReport(report PermissionReport) {
+	for _, account := range report.Accounts {
+		if account.Error != "" {
+			log.Warn().Str("account", account.Account).Str("role", aws.ToString(account.Role)).Msgf("Failed to check the IAM permissions with the IAM policy simulator: %s", account.Error)
+			continue
+		}
+		if account.SuggestedPolicy == nil {
+			log.Info().Str("account", account.Account).Str("principal", account.Principal).Msg("All IAM permissions needed by the enabled discoveries and actions are granted.")
+			continue
+		}
+		affected := make([]string, 0)
+		for _, check := range account.Checks {
+			if len(check.Denied) > 0 {
+				affected = append(affected, check.Id)
+			}
+		}
+		policy, _ := json.Marshal(account.SuggestedPolicy)
+		log.Warn().Str("account", account.Account).Str("principal", account.Principal).
+			Msgf("IAM permissions are missing for %s. Affected targets are not discovered and attacks will fail. Suggested policy: %s", strings.Join(affected, ", "), string(policy))
+	}
+}
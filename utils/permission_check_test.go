@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPermissionsReportsDeniedActions(t *testing.T) {
+	originalAccounts := accounts
+	t.Cleanup(func() { accounts = originalAccounts })
+	role := aws.String("arn:aws:iam::123456789012:role/steadybit")
+	accounts = map[string]AwsAccess{
+		"123456789012-eu-central-1": {AccountNumber: "123456789012", Region: "eu-central-1", AssumeRole: role},
+		"123456789012-us-east-1":    {AccountNumber: "123456789012", Region: "us-east-1", AssumeRole: role},
+	}
+
+	iamClient := new(dryRunIamMock)
+	mockDryRunClients(t, iamClient, new(dryRunStsMock), &AwsAccess{AssumeRole: role})
+	iamClient.On("SimulatePrincipalPolicy", mock.Anything, mock.MatchedBy(func(params *iam.SimulatePrincipalPolicyInput) bool {
+		return assert.ObjectsAreEqual([]string{"ec2:DescribeInstances", "ec2:StopInstances"}, params.ActionNames)
+	})).Return(&iam.SimulatePrincipalPolicyOutput{
+		EvaluationResults: []iamtypes.EvaluationResult{
+			{EvalActionName: aws.String("ec2:DescribeInstances"), EvalDecision: iamtypes.PolicyEvaluationDecisionTypeAllowed},
+			{EvalActionName: aws.String("ec2:StopInstances"), EvalDecision: iamtypes.PolicyEvaluationDecisionTypeExplicitDeny},
+		},
+	}, nil).Once()
+
+	report := checkPermissions(context.Background(), []RequiredPermissions{
+		{Kind: PermissionKindAction, Id: "com.steadybit.extension_aws.ec2_instance.state", Actions: []string{"ec2:StopInstances", "ec2:DescribeInstances"}},
+		{Kind: PermissionKindDiscovery, Id: "com.steadybit.extension_aws.ec2-instance", Actions: []string{"ec2:DescribeInstances"}},
+	})
+
+	require.Len(t, report.Accounts, 1, "regions sharing a principal are checked once")
+	account := report.Accounts[0]
+	assert.Equal(t, []string{"eu-central-1", "us-east-1"}, account.Regions)
+	assert.Equal(t, *role, account.Principal)
+	require.Len(t, account.Checks, 2)
+	assert.Equal(t, []string{"ec2:StopInstances"}, account.Checks[0].Denied)
+	assert.Equal(t, []string{"ec2:DescribeInstances"}, account.Checks[0].Allowed)
+	assert.Empty(t, account.Checks[1].Denied)
+	require.NotNil(t, account.SuggestedPolicy)
+	assert.Equal(t, []string{"ec2:StopInstances"}, account.SuggestedPolicy.Statement[0].Action)
+	iamClient.AssertExpectations(t)
+}
+
+func TestRegisterRequiredPermissionsReplacesEntries(t *testing.T) {
+	original := requiredPermissions
+	t.Cleanup(func() { requiredPermissions = original })
+	requiredPermissions = nil
+
+	RegisterRequiredPermissions(PermissionKindDiscovery, "b", []string{"sqs:ListQueues"})
+	RegisterRequiredPermissions(PermissionKindAction, "a", []string{"sqs:PurgeQueue"})
+	RegisterRequiredPermissions(PermissionKindDiscovery, "b", []string{"sqs:ListQueues", "sqs:ListQueueTags"})
+	RegisterRequiredPermissions(PermissionKindAction, "c", nil)
+
+	assert.Equal(t, []RequiredPermissions{
+		{Kind: PermissionKindAction, Id: "a", Actions: []string{"sqs:PurgeQueue"}},
+		{Kind: PermissionKindDiscovery, Id: "b", Actions: []string{"sqs:ListQueues", "sqs:ListQueueTags"}},
+	}, GetRequiredPermissions())
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+)
+
+const (
+	PermissionKindDiscovery = "discovery"
+	PermissionKindAction    = "action"
+	PermissionKindExtension = "extension"
+)
+
+// RequiredPermissions lists the IAM actions a registered discovery or action calls in the target accounts.
+type RequiredPermissions struct {
+	Kind    string   `json:"kind"`
+	Id      string   `json:"id"`
+	Actions []string `json:"actions"`
+}
+
+// IamPolicy is an IAM policy document.
+type IamPolicy struct {
+	Version   string               `json:"Version"`
+	Statement []IamPolicyStatement `json:"Statement"`
+}
+
+type IamPolicyStatement struct {
+	Sid      string   `json:"Sid,omitempty"`
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource string   `json:"Resource"`
+}
+
+// DryRunPermissions lists the IAM actions called in every account by the dry run and the permission self-check.
+var DryRunPermissions = []string{"iam:SimulatePrincipalPolicy", "iam:GetRole"}
+
+// TagCachePermissions lists the IAM actions called in every account by the shared tag cache of the discoveries.
+var TagCachePermissions = []string{"tag:GetResources"}
+
+var (
+	requiredPermissions      []RequiredPermissions
+	requiredPermissionsMutex sync.Mutex
+)
+
+// RegisterRequiredPermissions records the IAM actions of a discovery or action. Actions registered with
+// RegisterInstrumentedAction are recorded automatically if they implement ActionWithRequiredPermissions.
+func RegisterRequiredPermissions(kind string, id string, actions []string) {
+	if len(actions) == 0 {
+		return
+	}
+	requiredPermissionsMutex.Lock()
+	defer requiredPermissionsMutex.Unlock()
+	requiredPermissions = slices.DeleteFunc(requiredPermissions, func(p RequiredPermissions) bool {
+		return p.Kind == kind && p.Id == id
+	})
+	requiredPermissions = append(requiredPermissions, RequiredPermissions{Kind: kind, Id: id, Actions: slices.Clone(actions)})
+}
+
+// GetRequiredPermissions returns the permissions of all registered discoveries and actions, ordered by kind and id.
+func GetRequiredPermissions() []RequiredPermissions {
+	requiredPermissionsMutex.Lock()
+	defer requiredPermissionsMutex.Unlock()
+	result := slices.Clone(requiredPermissions)
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
+
+// NewIamPolicy returns a policy allowing the given IAM actions on all resources. Duplicates are removed.
+func NewIamPolicy(sid string, actions []string) IamPolicy {
+	unique := slices.Clone(actions)
+	slices.Sort(unique)
+	unique = slices.Compact(unique)
+	return IamPolicy{
+		Version:   "2012-10-17",
+		Statement: []IamPolicyStatement{{Sid: sid, Effect: "Allow", Action: unique, Resource: "*"}},
+	}
+}
+
+// ExtensionPermissions returns the IAM actions the extension calls with its own credentials for the given
+// configuration, i.e. assuming the roles of the accounts, reading the accounts of the organization and writing the
+// attack journal.
+func ExtensionPermissions(spec extConfig.Specification) []string {
+	actions := make([]string, 0)
+	if len(spec.AssumeRoles) > 0 || len(spec.AssumeRolesAdvanced) > 0 || spec.OrganizationsEnabled {
+		actions = append(actions, "sts:AssumeRole")
+	}
+	if spec.OrganizationsEnabled {
+		if len(spec.OrganizationsOrganizationalUnits) > 0 {
+			actions = append(actions, "organizations:ListAccountsForParent", "organizations:ListOrganizationalUnitsForParent")
+		} else {
+			actions = append(actions, "organizations:ListAccounts")
+		}
+		if len(spec.OrganizationsAccountTagFilters) > 0 {
+			actions = append(actions, "organizations:ListTagsForResource")
+		}
+	}
+	switch spec.AttackJournalBackend {
+	case "s3":
+		actions = append(actions, "s3:PutObject", "s3:GetObject", "s3:DeleteObject", "s3:ListBucket")
+	case "dynamodb":
+		actions = append(actions, "dynamodb:PutItem", "dynamodb:GetItem", "dynamodb:DeleteItem", "dynamodb:Query")
+	}
+	return actions
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	extension_kit "github.com/steadybit/extension-kit"
+)
+
+// checkTargetProtection rejects targets carrying one of the protected tags or having an ARN matching one of the
+// protected ARN patterns. Tags are exposed by the discoveries as `<target type>.label.<lowercase tag key>` attributes,
+// ARNs as attributes ending in `.arn`.
+func checkTargetProtection(target *action_kit_api.Target) error {
+	if target == nil {
+		return nil
+	}
+	for _, protectedTag := range extConfig.Config().ProtectedTags {
+		key, value, hasValue := strings.Cut(protectedTag, "=")
+		suffix := ".label." + strings.ToLower(strings.TrimSpace(key))
+		for attribute, values := range target.Attributes {
+			if !strings.HasSuffix(attribute, suffix) || strings.Contains(attribute, "k8s-label") {
+				continue
+			}
+			if !hasValue || slices.Contains(values, strings.TrimSpace(value)) {
+				return protectedTargetError(target, fmt.Sprintf("it is tagged with the protection tag '%s'", protectedTag))
+			}
+		}
+	}
+	for _, pattern := range extConfig.Config().ProtectedArnPatterns {
+		regex := globToRegex(strings.TrimSpace(pattern))
+		for attribute, values := range target.Attributes {
+			if attribute != "aws.arn" && !strings.HasSuffix(attribute, ".arn") {
+				continue
+			}
+			for _, arn := range values {
+				if matchesPattern(regex, arn) {
+					return protectedTargetError(target, fmt.Sprintf("its ARN '%s' matches the protected ARN pattern '%s'", arn, pattern))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func protectedTargetError(target *action_kit_api.Target, reason string) error {
+	return extension_kit.ToError(fmt.Sprintf("Target '%s' is protected and must not be attacked, because %s.", target.Name, reason), nil)
+}
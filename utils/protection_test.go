@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTargetProtection(t *testing.T) {
+	original := *extConfig.Config()
+	defer extConfig.Set(original)
+	extConfig.Update(func(spec *extConfig.Specification) {
+		spec.ProtectedTags = []string{"steadybit.com/protected=true", "Critical"}
+		spec.ProtectedArnPatterns = []string{"arn:aws:dynamodb:*:123456789012:table/prod-*"}
+	})
+
+	tests := []struct {
+		name       string
+		attributes map[string][]string
+		protected  bool
+	}{
+		{
+			name:       "protection tag with matching value",
+			attributes: map[string][]string{"aws-ec2.label.steadybit.com/protected": {"true"}},
+			protected:  true,
+		},
+		{
+			name:       "protection tag with other value",
+			attributes: map[string][]string{"aws-ec2.label.steadybit.com/protected": {"false"}},
+			protected:  false,
+		},
+		{
+			name:       "protection tag without value matches any value",
+			attributes: map[string][]string{"aws.asg.label.critical": {"anything"}},
+			protected:  true,
+		},
+		{
+			name:       "kubernetes labels are ignored",
+			attributes: map[string][]string{"aws.eks.nodegroup.k8s-label.critical": {"yes"}},
+			protected:  false,
+		},
+		{
+			name:       "matching arn",
+			attributes: map[string][]string{"aws.arn": {"arn:aws:dynamodb:eu-central-1:123456789012:table/prod-orders"}},
+			protected:  true,
+		},
+		{
+			name:       "non matching arn",
+			attributes: map[string][]string{"aws.arn": {"arn:aws:dynamodb:eu-central-1:123456789012:table/test-orders"}},
+			protected:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTargetProtection(&action_kit_api.Target{Name: "target", Attributes: tt.attributes})
+			if tt.protected {
+				var extensionError extension_kit.ExtensionError
+				require.ErrorAs(t, err, &extensionError)
+				assert.Contains(t, extensionError.Title, "Target 'target' is protected")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInstrumentedActionRejectsProtectedTargets(t *testing.T) {
+	original := *extConfig.Config()
+	defer extConfig.Set(original)
+	extConfig.Update(func(spec *extConfig.Specification) { spec.ProtectedTags = []string{"steadybit.com/protected=true"} })
+
+	action := &instrumentedAction[string]{delegate: &instrumentationTestAction{}, id: "instrumentation-test-action"}
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+		Target: &action_kit_api.Target{Attributes: map[string][]string{"aws-ec2.label.steadybit.com/protected": {"true"}}},
+	})
+
+	assert.Error(t, err)
+	assert.Empty(t, state.State, "the action must not be prepared")
+}
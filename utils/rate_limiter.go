@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	middleware2 "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/rs/zerolog/log"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// rateLimitDecreaseFactor is applied to the rate after every throttling error
+	rateLimitDecreaseFactor = 0.5
+	// rateLimitIncreaseInterval is the minimum time between two increases after successful calls
+	rateLimitIncreaseInterval = 5 * time.Second
+)
+
+// adaptiveRateLimiter is a token bucket which halves its rate when AWS throttles and slowly recovers towards the
+// configured maximum afterward.
+type adaptiveRateLimiter struct {
+	m          sync.Mutex
+	limiter    *rate.Limiter
+	maxRate    rate.Limit
+	minRate    rate.Limit
+	lastChange time.Time
+}
+
+var rateLimiters sync.Map
+
+func newAdaptiveRateLimiter(maxRate float64, minRate float64, burst int) *adaptiveRateLimiter {
+	return &adaptiveRateLimiter{
+		limiter: rate.NewLimiter(rate.Limit(maxRate), max(burst, 1)),
+		maxRate: rate.Limit(maxRate),
+		minRate: rate.Limit(min(minRate, maxRate)),
+	}
+}
+
+func (l *adaptiveRateLimiter) wait(ctx context.Context) error {
+	return l.limiter.Wait(ctx)
+}
+
+func (l *adaptiveRateLimiter) onThrottle(now time.Time) rate.Limit {
+	l.m.Lock()
+	defer l.m.Unlock()
+	newRate := max(l.limiter.Limit()*rateLimitDecreaseFactor, l.minRate)
+	l.limiter.SetLimitAt(now, newRate)
+	l.lastChange = now
+	return newRate
+}
+
+func (l *adaptiveRateLimiter) onSuccess(now time.Time) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	current := l.limiter.Limit()
+	if current >= l.maxRate || now.Sub(l.lastChange) < rateLimitIncreaseInterval {
+		return
+	}
+	l.limiter.SetLimitAt(now, min(current+l.maxRate/10, l.maxRate))
+	l.lastChange = now
+}
+
+func getRateLimiter(account string, region string, service string) *adaptiveRateLimiter {
+	key := fmt.Sprintf("%s/%s/%s", account, region, service)
+	if limiter, ok := rateLimiters.Load(key); ok {
+		return limiter.(*adaptiveRateLimiter)
+	}
+	limiter, _ := rateLimiters.LoadOrStore(key, newAdaptiveRateLimiter(extConfig.Config().AwsApiRateLimit, extConfig.Config().AwsApiRateLimitMin, extConfig.Config().AwsApiRateLimitBurst))
+	return limiter.(*adaptiveRateLimiter)
+}
+
+var throttleCheck = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+// rateLimitMiddleware is added after the retry middleware, so that every attempt takes a token and every throttled
+// attempt slows down the calls for the same account, region and service.
+func rateLimitMiddleware(account string) middleware.FinalizeMiddleware {
+	return middleware.FinalizeMiddlewareFunc("rateLimitMiddleware",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (out middleware.FinalizeOutput, metadata middleware.Metadata, err error) {
+			region := middleware2.GetRegion(ctx)
+			service := middleware2.GetServiceID(ctx)
+			limiter := getRateLimiter(account, region, service)
+			if err := limiter.wait(ctx); err != nil {
+				return out, metadata, err
+			}
+			out, metadata, err = next.HandleFinalize(ctx, in)
+			if err != nil && throttleCheck.IsErrorThrottle(err) == aws.TrueTernary {
+				newRate := limiter.onThrottle(time.Now())
+				log.Debug().Msgf("AWS throttled %s - %s - %s in account %s. Reducing rate to %.2f calls/s.", region, service, middleware2.GetOperationName(ctx), account, float64(newRate))
+			} else if err == nil {
+				limiter.onSuccess(time.Now())
+			}
+			return out, metadata, err
+		})
+}
+
+func addRateLimitMiddleware(account string) func(stack *middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		rateLimiter := rateLimitMiddleware(account)
+		if err := stack.Finalize.Insert(rateLimiter, (&retry.Attempt{}).ID(), middleware.After); err != nil {
+			return stack.Finalize.Add(rateLimiter, middleware.After)
+		}
+		return nil
+	}
+}
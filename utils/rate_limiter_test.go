@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestAdaptiveRateLimiterBacksOffAndRecovers(t *testing.T) {
+	limiter := newAdaptiveRateLimiter(20, 2, 40)
+	now := time.Now()
+
+	assert.Equal(t, rate.Limit(10), limiter.onThrottle(now))
+	assert.Equal(t, rate.Limit(5), limiter.onThrottle(now))
+	assert.Equal(t, rate.Limit(2.5), limiter.onThrottle(now))
+	assert.Equal(t, rate.Limit(2), limiter.onThrottle(now), "rate never drops below the minimum")
+
+	limiter.onSuccess(now.Add(time.Second))
+	assert.Equal(t, rate.Limit(2), limiter.limiter.Limit(), "no increase shortly after throttling")
+
+	limiter.onSuccess(now.Add(rateLimitIncreaseInterval))
+	assert.Equal(t, rate.Limit(4), limiter.limiter.Limit())
+
+	for i := 2; i < 20; i++ {
+		limiter.onSuccess(now.Add(time.Duration(i) * rateLimitIncreaseInterval))
+	}
+	assert.Equal(t, rate.Limit(20), limiter.limiter.Limit(), "rate never exceeds the maximum")
+}
+
+func TestGetRateLimiterIsSharedPerAccountRegionAndService(t *testing.T) {
+	a := getRateLimiter("123456789012", "eu-central-1", "EC2")
+	assert.Same(t, a, getRateLimiter("123456789012", "eu-central-1", "EC2"))
+	assert.NotSame(t, a, getRateLimiter("123456789012", "eu-central-1", "ECS"))
+	assert.NotSame(t, a, getRateLimiter("123456789012", "eu-west-1", "EC2"))
+	assert.NotSame(t, a, getRateLimiter("210987654321", "eu-central-1", "EC2"))
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/rs/zerolog/log"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+)
+
+type RoleAssumptionState string
+
+const (
+	RoleAssumptionStateAssumed  RoleAssumptionState = "assumed"
+	RoleAssumptionStateRetrying RoleAssumptionState = "retrying"
+)
+
+// RoleStatus describes the outcome of the role assumption for a single entry of `AssumeRolesAdvanced`.
+type RoleStatus struct {
+	RoleArn     string              `json:"roleArn"`
+	State       RoleAssumptionState `json:"state"`
+	Account     string              `json:"account,omitempty"`
+	Regions     []string            `json:"regions"`
+	Attempts    int                 `json:"attempts"`
+	LastError   string              `json:"lastError,omitempty"`
+	LastAttempt time.Time           `json:"lastAttempt"`
+	NextAttempt *time.Time          `json:"nextAttempt,omitempty"`
+}
+
+type pendingRole struct {
+	assumeRole extConfig.AssumeRole
+	awsConfig  aws.Config
+	backoff    time.Duration
+}
+
+type roleSupervisor struct {
+	m        sync.Mutex
+	statuses map[string]*RoleStatus
+	pending  map[string]*pendingRole
+	ticker   *time.Ticker
+}
+
+var supervisor = newRoleSupervisor()
+
+func newRoleSupervisor() *roleSupervisor {
+	return &roleSupervisor{
+		statuses: make(map[string]*RoleStatus),
+		pending:  make(map[string]*pendingRole),
+	}
+}
+
+// StartRoleSupervisor keeps retrying roles that could not be assumed during InitializeAwsAccess. Roles are retried
+// with an exponential backoff and hot-added to the configured accounts as soon as the assumption succeeds.
+func StartRoleSupervisor(ctx context.Context) {
+	supervisor.start(ctx)
+}
+
+// GetRoleStatuses returns the assumption state of every configured role, ordered by role ARN.
+func GetRoleStatuses() []RoleStatus {
+	return supervisor.getStatuses()
+}
+
+func (s *roleSupervisor) start(ctx context.Context) {
+	s.ticker = time.NewTicker(time.Second)
+	go func() {
+		defer s.ticker.Stop()
+		for {
+			select {
+			case <-s.ticker.C:
+				s.retryDue(ctx, time.Now())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *roleSupervisor) markAssumed(assumeRole extConfig.AssumeRole, account string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.markAssumedLocked(assumeRole, account)
+}
+
+func (s *roleSupervisor) markAssumedLocked(assumeRole extConfig.AssumeRole, account string) {
+	status := s.statusFor(assumeRole)
+	status.State = RoleAssumptionStateAssumed
+	status.Account = account
+	status.Attempts++
+	status.LastError = ""
+	status.LastAttempt = time.Now()
+	status.NextAttempt = nil
+	delete(s.pending, assumeRole.RoleArn)
+}
+
+func (s *roleSupervisor) markFailed(assumeRole extConfig.AssumeRole, awsConfig aws.Config, err error) time.Time {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.markFailedLocked(assumeRole, awsConfig, err)
+}
+
+func (s *roleSupervisor) markFailedLocked(assumeRole extConfig.AssumeRole, awsConfig aws.Config, err error) time.Time {
+	pending, ok := s.pending[assumeRole.RoleArn]
+	if !ok {
+		pending = &pendingRole{assumeRole: assumeRole, awsConfig: awsConfig, backoff: initialRetryBackoff()}
+		s.pending[assumeRole.RoleArn] = pending
+	} else {
+		pending.backoff = min(pending.backoff*2, maxRetryBackoff())
+	}
+	now := time.Now()
+	nextAttempt := now.Add(pending.backoff)
+	status := s.statusFor(assumeRole)
+	status.State = RoleAssumptionStateRetrying
+	status.Attempts++
+	status.LastError = err.Error()
+	status.LastAttempt = now
+	status.NextAttempt = &nextAttempt
+	return nextAttempt
+}
+
+func (s *roleSupervisor) forget(roleArn string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.statuses, roleArn)
+	delete(s.pending, roleArn)
+}
+
+// markRetryFailed records a failed retry. It returns false if the role has been removed by a configuration reload in
+// the meantime, the role is not put back into pending then.
+func (s *roleSupervisor) markRetryFailed(pending *pendingRole, err error) (time.Time, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if !s.isPendingLocked(pending.assumeRole.RoleArn) {
+		return time.Time{}, false
+	}
+	return s.markFailedLocked(pending.assumeRole, pending.awsConfig, err), true
+}
+
+// markRetryAssumed records a successful retry and adds the accesses of the role while holding the lock, so that a role
+// removed by a configuration reload in the meantime is not added again. It returns false if the role has been removed.
+func (s *roleSupervisor) markRetryAssumed(pending *pendingRole, account string) ([]AwsAccess, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if !s.isPendingLocked(pending.assumeRole.RoleArn) {
+		return nil, false
+	}
+	s.markAssumedLocked(pending.assumeRole, account)
+	return prepareRegionConfigs(pending.awsConfig, &pending.assumeRole.RoleArn, account, pending.assumeRole.Regions, pending.assumeRole.TagFilters), true
+}
+
+func (s *roleSupervisor) isPendingLocked(roleArn string) bool {
+	_, ok := s.pending[roleArn]
+	return ok
+}
+
+func (s *roleSupervisor) statusFor(assumeRole extConfig.AssumeRole) *RoleStatus {
+	status, ok := s.statuses[assumeRole.RoleArn]
+	if !ok {
+		status = &RoleStatus{RoleArn: assumeRole.RoleArn, Regions: assumeRole.Regions}
+		s.statuses[assumeRole.RoleArn] = status
+	}
+	return status
+}
+
+func (s *roleSupervisor) dueRoles(now time.Time) []*pendingRole {
+	s.m.Lock()
+	defer s.m.Unlock()
+	var due []*pendingRole
+	for roleArn, pending := range s.pending {
+		if status, ok := s.statuses[roleArn]; ok && status.NextAttempt != nil && !status.NextAttempt.After(now) {
+			due = append(due, pending)
+		}
+	}
+	return due
+}
+
+func (s *roleSupervisor) retryDue(ctx context.Context, now time.Time) {
+	for _, pending := range s.dueRoles(now) {
+		assumedAccount, err := identifyAccount(ctx, pending.awsConfig)
+		if err != nil {
+			if nextAttempt, ok := s.markRetryFailed(pending, err); ok {
+				log.Warn().Err(err).Msgf("Still failing to assume role '%s'. Next attempt at %s.", pending.assumeRole.RoleArn, nextAttempt.Format(time.RFC3339))
+			}
+			continue
+		}
+		added, ok := s.markRetryAssumed(pending, assumedAccount)
+		if !ok {
+			// role was removed from the configuration in the meantime
+			continue
+		}
+		log.Info().Msgf("Successfully assumed role '%s' in account '%s' after retrying", pending.assumeRole.RoleArn, assumedAccount)
+		notifyAccessListeners(added)
+	}
+}
+
+func (s *roleSupervisor) getStatuses() []RoleStatus {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]RoleStatus, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].RoleArn < result[j].RoleArn
+	})
+	return result
+}
+
+func initialRetryBackoff() time.Duration {
+	return max(time.Duration(extConfig.Config().AssumeRoleRetryInitialInterval)*time.Second, time.Second)
+}
+
+func maxRetryBackoff() time.Duration {
+	return max(time.Duration(extConfig.Config().AssumeRoleRetryMaxInterval)*time.Second, initialRetryBackoff())
+}
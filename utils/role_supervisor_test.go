@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleSupervisorHotAddsRoleAfterRetry(t *testing.T) {
+	config.Update(func(spec *config.Specification) {
+		spec.AssumeRoleRetryInitialInterval = 10
+		spec.AssumeRoleRetryMaxInterval = 300
+	})
+	accounts = getTestAccountsWithoutRoleAssumption()
+	supervisor = newRoleSupervisor()
+	originalIdentify := identifyAccount
+	defer func() { identifyAccount = originalIdentify }()
+
+	var added []string
+	originalListeners := accessListeners
+	defer func() { accessListeners = originalListeners }()
+	AddAwsAccessListener(func(account *AwsAccess) {
+		added = append(added, account.AccountNumber+"@"+account.Region)
+	})
+
+	assumeRole := config.AssumeRole{RoleArn: "arn:aws:iam::44444444:role/test", Regions: []string{"us-east-1", "eu-west-1"}}
+	nextAttempt := supervisor.markFailed(assumeRole, aws.Config{}, errors.New("sts unavailable"))
+
+	identifyAccount = func(ctx context.Context, awsConfig aws.Config) (string, error) {
+		return "", errors.New("still unavailable")
+	}
+	supervisor.retryDue(context.Background(), nextAttempt)
+
+	statuses := GetRoleStatuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, RoleAssumptionStateRetrying, statuses[0].State)
+	assert.Equal(t, 2, statuses[0].Attempts)
+	assert.Equal(t, "still unavailable", statuses[0].LastError)
+	assert.Equal(t, 20*time.Second, supervisor.pending[assumeRole.RoleArn].backoff)
+	_, err := GetAwsAccess("44444444", "us-east-1", &assumeRole.RoleArn)
+	require.Error(t, err)
+
+	identifyAccount = func(ctx context.Context, awsConfig aws.Config) (string, error) {
+		return "44444444", nil
+	}
+	supervisor.retryDue(context.Background(), *statuses[0].NextAttempt)
+
+	statuses = GetRoleStatuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, RoleAssumptionStateAssumed, statuses[0].State)
+	assert.Equal(t, "44444444", statuses[0].Account)
+	assert.Nil(t, statuses[0].NextAttempt)
+	assert.Empty(t, supervisor.pending)
+	assert.ElementsMatch(t, []string{"44444444@us-east-1", "44444444@eu-west-1"}, added)
+
+	access, err := GetAwsAccess("44444444", "eu-west-1", &assumeRole.RoleArn)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", access.AwsConfig.Region)
+}
+
+func TestRoleSupervisorSkipsRolesThatAreNotDue(t *testing.T) {
+	config.Update(func(spec *config.Specification) {
+		spec.AssumeRoleRetryInitialInterval = 10
+		spec.AssumeRoleRetryMaxInterval = 15
+	})
+	accounts = getTestAccountsWithoutRoleAssumption()
+	supervisor = newRoleSupervisor()
+	originalIdentify := identifyAccount
+	defer func() { identifyAccount = originalIdentify }()
+
+	calls := 0
+	identifyAccount = func(ctx context.Context, awsConfig aws.Config) (string, error) {
+		calls++
+		return "", errors.New("sts unavailable")
+	}
+
+	assumeRole := config.AssumeRole{RoleArn: "arn:aws:iam::44444444:role/test", Regions: []string{"us-east-1"}}
+	nextAttempt := supervisor.markFailed(assumeRole, aws.Config{}, errors.New("sts unavailable"))
+
+	supervisor.retryDue(context.Background(), nextAttempt.Add(-time.Second))
+	assert.Equal(t, 0, calls)
+
+	supervisor.retryDue(context.Background(), nextAttempt)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 15*time.Second, supervisor.pending[assumeRole.RoleArn].backoff, "backoff is capped by the max interval")
+}
+
+func TestRoleSupervisorDropsRetryOfRemovedRole(t *testing.T) {
+	config.Update(func(spec *config.Specification) {
+		spec.AssumeRoleRetryInitialInterval = 10
+		spec.AssumeRoleRetryMaxInterval = 300
+	})
+	accounts = getTestAccountsWithoutRoleAssumption()
+	supervisor = newRoleSupervisor()
+	originalIdentify := identifyAccount
+	defer func() { identifyAccount = originalIdentify }()
+	assumeRole := config.AssumeRole{RoleArn: "arn:aws:iam::44444444:role/test", Regions: []string{"us-east-1"}}
+
+	// the configuration is reloaded while the retry is running
+	for _, identifyErr := range []error{errors.New("still unavailable"), nil} {
+		nextAttempt := supervisor.markFailed(assumeRole, aws.Config{}, errors.New("sts unavailable"))
+		identifyAccount = func(ctx context.Context, awsConfig aws.Config) (string, error) {
+			removeAssumedRole(assumeRole.RoleArn)
+			return "44444444", identifyErr
+		}
+
+		supervisor.retryDue(context.Background(), nextAttempt)
+
+		assert.Empty(t, supervisor.pending)
+		assert.Empty(t, GetRoleStatuses())
+		_, err := GetAwsAccess("44444444", "us-east-1", &assumeRole.RoleArn)
+		require.Error(t, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/aws/smithy-go/middleware"
 	"github.com/rs/zerolog/log"
 	"strings"
+	"time"
 )
 
 type logForwarder struct {
@@ -32,5 +33,8 @@ var customLoggerMiddleware = middleware.InitializeMiddlewareFunc("customLoggerMi
 		} else {
 			log.Info().Msgf("AWS-Call: %s - %s - %s", middleware2.GetRegion(ctx), middleware2.GetServiceID(ctx), operationName)
 		}
-		return next.HandleInitialize(ctx, in)
+		start := time.Now()
+		out, metadata, err = next.HandleInitialize(ctx, in)
+		recordAwsApiCall(ctx, time.Since(start), err)
+		return out, metadata, err
 	})
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/smithy-go"
+	"github.com/rs/zerolog/log"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+)
+
+// ResourceTags holds the tags of the resources of a resource type. If the tagging API could not be used, the tags are
+// read per resource instead, see Get.
+type ResourceTags struct {
+	byArn       map[string]map[string]string
+	perResource bool
+}
+
+// Of returns the tags of the resource. Resources which were never tagged are unknown to the tagging API and have no tags.
+func (t ResourceTags) Of(arn string) map[string]string {
+	if tags, ok := t.byArn[arn]; ok {
+		return tags
+	}
+	return map[string]string{}
+}
+
+// Get returns the tags of the resource. If the tagging API could not be used, the tags are read by fetch, i.e. the tag
+// call of the resource's service. If this fails as well, the error is only returned for protected tags configured by the
+// user, as targets without tags could not be recognized as protected. Otherwise, the target is discovered without tags.
+func (t ResourceTags) Get(arn string, fetch func() (map[string]string, error)) (map[string]string, error) {
+	if !t.perResource {
+		return t.Of(arn), nil
+	}
+	tags, err := fetch()
+	if err != nil {
+		if extConfig.Config().HasExplicitProtectedTags() {
+			return nil, err
+		}
+		log.Debug().Err(err).Msgf("Failed to fetch the tags of %s", arn)
+		return map[string]string{}, nil
+	}
+	return tags, nil
+}
+
+type tagCacheEntry struct {
+	tags      map[string]map[string]string
+	fetchedAt time.Time
+}
+
+var (
+	tagCache              = make(map[string]tagCacheEntry)
+	lastFetchedTags       = make(map[string]map[string]map[string]string)
+	tagCacheMutex         sync.Mutex
+	tagsUnavailableLogged sync.Map
+)
+
+// GetResourceTags returns the tags of all resources of a resource type in the account and region, e.g. `sqs` or
+// `dynamodb:table`. A few GetResources calls replace the per-resource tag calls of the discoveries, and the result is
+// shared by all discoveries of the resource type for `TagCacheTtl`.
+//
+// If `tag:GetResources` is not permitted, e.g. by the IAM policy of an older installation, the discoveries fall back to
+// the tag calls of the services (see ResourceTags.Get). On other errors, the last fetched tags are used, and the tag
+// calls of the services if there are none.
+func GetResourceTags(ctx context.Context, client resourcegroupstaggingapi.GetResourcesAPIClient, account *AwsAccess, resourceType string) (ResourceTags, error) {
+	key := resourceType + "|" + getMapKey(account.AccountNumber, account.Region, account.AssumeRole)
+	ttl := time.Duration(extConfig.Config().TagCacheTtl) * time.Second
+	if ttl > 0 {
+		tagCacheMutex.Lock()
+		entry, ok := tagCache[key]
+		tagCacheMutex.Unlock()
+		if ok && time.Since(entry.fetchedAt) < ttl {
+			return ResourceTags{byArn: entry.tags}, nil
+		}
+	}
+
+	tags, err := fetchResourceTags(ctx, client, resourceType)
+	if err != nil {
+		if !isAccessDenied(err) {
+			tagCacheMutex.Lock()
+			lastTags, ok := lastFetchedTags[key]
+			tagCacheMutex.Unlock()
+			if ok {
+				log.Warn().Err(err).Msgf("Failed to fetch the tags of %s resources. Using the last fetched tags.", resourceType)
+				return ResourceTags{byArn: lastTags}, nil
+			}
+		}
+		if _, logged := tagsUnavailableLogged.LoadOrStore(resourceType, true); !logged {
+			log.Warn().Err(err).Msgf("Failed to fetch the tags of %s resources with the Resource Groups Tagging API. Reading the tags of every resource instead, please permit tag:GetResources.", resourceType)
+		}
+		return ResourceTags{perResource: true}, nil
+	}
+
+	tagCacheMutex.Lock()
+	defer tagCacheMutex.Unlock()
+	lastFetchedTags[key] = tags
+	if ttl > 0 {
+		for existingKey, existing := range tagCache {
+			if time.Since(existing.fetchedAt) >= ttl {
+				delete(tagCache, existingKey)
+			}
+		}
+		tagCache[key] = tagCacheEntry{tags: tags, fetchedAt: time.Now()}
+	}
+	return ResourceTags{byArn: tags}, nil
+}
+
+// isAccessDenied reports whether the call was rejected by IAM.
+func isAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && strings.Contains(apiErr.ErrorCode(), "AccessDenied") {
+		return true
+	}
+	var re *awshttp.ResponseError
+	return errors.As(err, &re) && re.HTTPStatusCode() == 403
+}
+
+func fetchResourceTags(ctx context.Context, client resourcegroupstaggingapi.GetResourcesAPIClient, resourceType string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string)
+	paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(client, &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: []string{resourceType},
+		ResourcesPerPage:    aws.Int32(100),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, mapping := range output.ResourceTagMappingList {
+			tags := make(map[string]string, len(mapping.Tags))
+			for _, tag := range mapping.Tags {
+				if tag.Key != nil {
+					tags[*tag.Key] = aws.ToString(tag.Value)
+				}
+			}
+			result[aws.ToString(mapping.ResourceARN)] = tags
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/smithy-go"
+	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tagClientFake struct {
+	calls int
+	err   error
+}
+
+func (f *tagClientFake) GetResources(_ context.Context, params *resourcegroupstaggingapi.GetResourcesInput, _ ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if params.PaginationToken == nil {
+		return &resourcegroupstaggingapi.GetResourcesOutput{
+			ResourceTagMappingList: []types.ResourceTagMapping{
+				{ResourceARN: aws.String("arn:aws:sqs:eu-central-1:42:orders"), Tags: []types.Tag{{Key: aws.String("team"), Value: aws.String("checkout")}}},
+			},
+			PaginationToken: aws.String("page-2"),
+		}, nil
+	}
+	return &resourcegroupstaggingapi.GetResourcesOutput{
+		ResourceTagMappingList: []types.ResourceTagMapping{
+			{ResourceARN: aws.String("arn:aws:sqs:eu-central-1:42:payments"), Tags: []types.Tag{{Key: aws.String("team"), Value: aws.String("payment")}}},
+		},
+		PaginationToken: aws.String(""),
+	}, nil
+}
+
+func TestGetResourceTagsIsSharedWithinTtl(t *testing.T) {
+	config.Update(func(spec *config.Specification) { spec.TagCacheTtl = 60 })
+	defer func() {
+		config.Update(func(spec *config.Specification) { spec.TagCacheTtl = 0 })
+		tagCache = make(map[string]tagCacheEntry)
+	}()
+	client := &tagClientFake{}
+	account := &AwsAccess{AccountNumber: "42", Region: "eu-central-1"}
+
+	tags, err := GetResourceTags(context.Background(), client, account, "sqs")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "checkout"}, tags.Of("arn:aws:sqs:eu-central-1:42:orders"))
+	assert.Equal(t, map[string]string{"team": "payment"}, tags.Of("arn:aws:sqs:eu-central-1:42:payments"))
+	assert.Empty(t, tags.Of("arn:aws:sqs:eu-central-1:42:untagged"))
+	assert.Equal(t, 2, client.calls)
+
+	_, err = GetResourceTags(context.Background(), client, account, "sqs")
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.calls, "served from the cache")
+
+	_, err = GetResourceTags(context.Background(), client, &AwsAccess{AccountNumber: "42", Region: "us-east-1"}, "sqs")
+	require.NoError(t, err)
+	assert.Equal(t, 4, client.calls, "other regions are cached separately")
+}
+
+func TestGetResourceTagsWithoutCache(t *testing.T) {
+	config.Update(func(spec *config.Specification) { spec.TagCacheTtl = 0 })
+	client := &tagClientFake{}
+	account := &AwsAccess{AccountNumber: "42", Region: "eu-central-1"}
+
+	_, err := GetResourceTags(context.Background(), client, account, "sqs")
+	require.NoError(t, err)
+	_, err = GetResourceTags(context.Background(), client, account, "sqs")
+	require.NoError(t, err)
+	assert.Equal(t, 4, client.calls)
+}
+
+func TestGetResourceTagsFallsBackToPerResourceCallsIfDenied(t *testing.T) {
+	lastFetchedTags = make(map[string]map[string]map[string]string)
+	account := &AwsAccess{AccountNumber: "42", Region: "eu-central-1"}
+	_, err := GetResourceTags(context.Background(), &tagClientFake{}, account, "sqs")
+	require.NoError(t, err)
+
+	tags, err := GetResourceTags(context.Background(), &tagClientFake{err: &smithy.GenericAPIError{Code: "AccessDeniedException"}}, account, "sqs")
+	require.NoError(t, err)
+	queueTags, err := tags.Get("arn:aws:sqs:eu-central-1:42:orders", func() (map[string]string, error) {
+		return map[string]string{"team": "fulfillment"}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "fulfillment"}, queueTags, "the tags are read per resource, not taken from the last fetch")
+}
+
+func TestGetResourceTagsUsesLastFetchedTagsOnOtherErrors(t *testing.T) {
+	lastFetchedTags = make(map[string]map[string]map[string]string)
+	account := &AwsAccess{AccountNumber: "43", Region: "eu-central-1"}
+	perResourceCalls := 0
+	fetch := func() (map[string]string, error) {
+		perResourceCalls++
+		return map[string]string{"team": "fulfillment"}, nil
+	}
+
+	tags, err := GetResourceTags(context.Background(), &tagClientFake{err: errors.New("ThrottlingException")}, account, "sqs")
+	require.NoError(t, err)
+	queueTags, err := tags.Get("arn:aws:sqs:eu-central-1:42:orders", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "fulfillment"}, queueTags)
+	assert.Equal(t, 1, perResourceCalls, "without previous tags, the tags are read per resource")
+
+	_, err = GetResourceTags(context.Background(), &tagClientFake{}, account, "sqs")
+	require.NoError(t, err)
+	tags, err = GetResourceTags(context.Background(), &tagClientFake{err: errors.New("ThrottlingException")}, account, "sqs")
+	require.NoError(t, err)
+	queueTags, err = tags.Get("arn:aws:sqs:eu-central-1:42:orders", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "checkout"}, queueTags, "the last fetched tags are used")
+	assert.Equal(t, 1, perResourceCalls)
+}
+
+func TestResourceTagsPerResourceFailureIsOnlyReturnedForExplicitProtectedTags(t *testing.T) {
+	defer config.Update(func(spec *config.Specification) { spec.ProtectedTags = nil })
+	tags := ResourceTags{perResource: true}
+	failing := func() (map[string]string, error) {
+		return nil, errors.New("AccessDenied")
+	}
+
+	config.Update(func(spec *config.Specification) { spec.ProtectedTags = []string{config.DefaultProtectedTag} })
+	queueTags, err := tags.Get("arn:aws:sqs:eu-central-1:42:orders", failing)
+	require.NoError(t, err)
+	assert.Empty(t, queueTags)
+
+	config.Update(func(spec *config.Specification) { spec.ProtectedTags = []string{"team=platform"} })
+	_, err = tags.Get("arn:aws:sqs:eu-central-1:42:orders", failing)
+	assert.ErrorContains(t, err, "AccessDenied", "targets without tags could not be recognized as protected")
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-aws/v2/config"
+)
+
+var compiledPatterns sync.Map
+
+// MatchesTags returns true if the tags match all filters. Filters are verified during configuration parsing, invalid
+// regular expressions never match.
+func MatchesTags(tags map[string]string, filters []config.TagFilter) bool {
+	for _, filter := range filters {
+		if !matchesSingleTagFilter(tags, filter) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesSingleTagFilter(tags map[string]string, filter config.TagFilter) bool {
+	if filter.IsGroup() {
+		if len(filter.AllOf) > 0 && !MatchesTags(tags, filter.AllOf) {
+			return false
+		}
+		if len(filter.AnyOf) > 0 && !slices.ContainsFunc(filter.AnyOf, func(nested config.TagFilter) bool {
+			return matchesSingleTagFilter(tags, nested)
+		}) {
+			return false
+		}
+		return true
+	}
+
+	value, present := tags[filter.Key]
+	switch filter.Operator {
+	case config.TagFilterOperatorNotEquals:
+		return !present || !slices.Contains(filter.Values, value)
+	case config.TagFilterOperatorExists:
+		return present
+	case config.TagFilterOperatorAbsent:
+		return !present
+	case config.TagFilterOperatorGlob:
+		return present && slices.ContainsFunc(filter.Values, func(pattern string) bool {
+			return matchesPattern(globToRegex(pattern), value)
+		})
+	case config.TagFilterOperatorRegex:
+		return present && slices.ContainsFunc(filter.Values, func(pattern string) bool {
+			return matchesPattern("^(?:"+pattern+")$", value)
+		})
+	default:
+		return present && slices.Contains(filter.Values, value)
+	}
+}
+
+func matchesPattern(pattern string, value string) bool {
+	compiled, ok := compiledPatterns.Load(pattern)
+	if !ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Invalid tag filter pattern '%s'", pattern)
+			return false
+		}
+		compiled, _ = compiledPatterns.LoadOrStore(pattern, re)
+	}
+	return compiled.(*regexp.Regexp).MatchString(value)
+}
+
+// globToRegex converts a glob pattern supporting `*` and `?` into an anchored regular expression.
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/steadybit/extension-aws/v2/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesTags(t *testing.T) {
+	tags := map[string]string{
+		"env":     "prod",
+		"team":    "shop-checkout",
+		"version": "v1.23",
+	}
+
+	tests := []struct {
+		name    string
+		filters []config.TagFilter
+		want    bool
+	}{
+		{
+			name:    "explicit equals",
+			filters: []config.TagFilter{{Key: "env", Operator: config.TagFilterOperatorEquals, Values: []string{"prod"}}},
+			want:    true,
+		},
+		{
+			name:    "not equals with matching value",
+			filters: []config.TagFilter{{Key: "env", Operator: config.TagFilterOperatorNotEquals, Values: []string{"prod"}}},
+			want:    false,
+		},
+		{
+			name:    "not equals with other value",
+			filters: []config.TagFilter{{Key: "env", Operator: config.TagFilterOperatorNotEquals, Values: []string{"dev", "staging"}}},
+			want:    true,
+		},
+		{
+			name:    "not equals with missing key",
+			filters: []config.TagFilter{{Key: "owner", Operator: config.TagFilterOperatorNotEquals, Values: []string{"prod"}}},
+			want:    true,
+		},
+		{
+			name:    "exists",
+			filters: []config.TagFilter{{Key: "team", Operator: config.TagFilterOperatorExists}},
+			want:    true,
+		},
+		{
+			name:    "exists with missing key",
+			filters: []config.TagFilter{{Key: "owner", Operator: config.TagFilterOperatorExists}},
+			want:    false,
+		},
+		{
+			name:    "absent",
+			filters: []config.TagFilter{{Key: "owner", Operator: config.TagFilterOperatorAbsent}},
+			want:    true,
+		},
+		{
+			name:    "absent with present key",
+			filters: []config.TagFilter{{Key: "env", Operator: config.TagFilterOperatorAbsent}},
+			want:    false,
+		},
+		{
+			name:    "glob",
+			filters: []config.TagFilter{{Key: "team", Operator: config.TagFilterOperatorGlob, Values: []string{"payment-*", "shop-*"}}},
+			want:    true,
+		},
+		{
+			name:    "glob quotes regex characters",
+			filters: []config.TagFilter{{Key: "version", Operator: config.TagFilterOperatorGlob, Values: []string{"v1?2*"}}},
+			want:    true,
+		},
+		{
+			name:    "glob must match the whole value",
+			filters: []config.TagFilter{{Key: "team", Operator: config.TagFilterOperatorGlob, Values: []string{"shop"}}},
+			want:    false,
+		},
+		{
+			name:    "regex",
+			filters: []config.TagFilter{{Key: "version", Operator: config.TagFilterOperatorRegex, Values: []string{`v1\.\d+`}}},
+			want:    true,
+		},
+		{
+			name:    "regex must match the whole value",
+			filters: []config.TagFilter{{Key: "version", Operator: config.TagFilterOperatorRegex, Values: []string{`v1`}}},
+			want:    false,
+		},
+		{
+			name:    "invalid regex never matches",
+			filters: []config.TagFilter{{Key: "version", Operator: config.TagFilterOperatorRegex, Values: []string{`(`}}},
+			want:    false,
+		},
+		{
+			name: "anyOf across keys",
+			filters: []config.TagFilter{{AnyOf: []config.TagFilter{
+				{Key: "owner", Values: []string{"me"}},
+				{Key: "team", Operator: config.TagFilterOperatorGlob, Values: []string{"shop-*"}},
+			}}},
+			want: true,
+		},
+		{
+			name: "anyOf without match",
+			filters: []config.TagFilter{{AnyOf: []config.TagFilter{
+				{Key: "owner", Values: []string{"me"}},
+				{Key: "env", Values: []string{"dev"}},
+			}}},
+			want: false,
+		},
+		{
+			name: "anyOf of allOf groups combined with a plain filter",
+			filters: []config.TagFilter{
+				{Key: "env", Values: []string{"prod"}},
+				{AnyOf: []config.TagFilter{
+					{AllOf: []config.TagFilter{
+						{Key: "team", Values: []string{"payment"}},
+						{Key: "version", Operator: config.TagFilterOperatorExists},
+					}},
+					{AllOf: []config.TagFilter{
+						{Key: "team", Values: []string{"shop-checkout"}},
+						{Key: "version", Operator: config.TagFilterOperatorExists},
+					}},
+				}},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchesTags(tags, tt.filters))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	middleware2 "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/rs/zerolog/log"
+	extConfig "github.com/steadybit/extension-aws/v2/config"
+	"github.com/steadybit/extension-kit/extbuild"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/steadybit/extension-aws"
+
+// InitializeTracing installs an OTLP/HTTP trace exporter if tracing is enabled. The returned function flushes and
+// stops the exporter. Without tracing, the global no-op tracer provider stays in place.
+func InitializeTracing(ctx context.Context) func(context.Context) error {
+	if !extConfig.Config().TracingEnabled {
+		return func(context.Context) error { return nil }
+	}
+
+	var options []otlptracehttp.Option
+	if extConfig.Config().TracingEndpoint != "" {
+		options = append(options, otlptracehttp.WithEndpointURL(extConfig.Config().TracingEndpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create the OTLP trace exporter. Tracing is disabled.")
+		return func(context.Context) error { return nil }
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(extConfig.Config().TracingSampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName("extension-aws"),
+			semconv.ServiceVersion(extbuild.GetSemverVersionStringOrUnknown()),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	log.Info().Str("endpoint", extConfig.Config().TracingEndpoint).Msg("Tracing enabled.")
+	return provider.Shutdown
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+func recordSpanError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// tracingMiddleware creates a span for every AWS API call. Spans are children of the action span if the call is made
+// from within an action.
+var tracingMiddleware = middleware.InitializeMiddlewareFunc("tracingMiddleware",
+	func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (out middleware.InitializeOutput, metadata middleware.Metadata, err error) {
+		service := middleware2.GetServiceID(ctx)
+		operation := middleware2.GetOperationName(ctx)
+		ctx, span := tracer().Start(ctx, fmt.Sprintf("%s.%s", service, operation),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("rpc.system", "aws-api"),
+				attribute.String("rpc.service", service),
+				attribute.String("rpc.method", operation),
+				attribute.String("cloud.region", middleware2.GetRegion(ctx)),
+			))
+		defer span.End()
+
+		out, metadata, err = next.HandleInitialize(ctx, in)
+		if requestId, ok := middleware2.GetRequestIDMetadata(metadata); ok {
+			span.SetAttributes(attribute.String("aws.request_id", requestId))
+		}
+		recordSpanError(span, err)
+		return out, metadata, err
+	})